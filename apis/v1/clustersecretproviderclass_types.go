@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SecretProviderClassKind is the kind of the namespaced SecretProviderClass
+	SecretProviderClassKind = "SecretProviderClass"
+	// ClusterSecretProviderClassKind is the kind of the cluster scoped ClusterSecretProviderClass
+	ClusterSecretProviderClassKind = "ClusterSecretProviderClass"
+)
+
+// ClusterSecretProviderClassSpec defines the desired state of ClusterSecretProviderClass
+type ClusterSecretProviderClassSpec struct {
+	SecretProviderClassSpec `json:",inline"`
+	// NamespaceSelector selects the namespaces of the pods that are allowed to use
+	// the ClusterSecretProviderClass. An empty selector allows all namespaces and
+	// a nil selector allows none.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:scope=Cluster,shortName=cspc
+// +kubebuilder:printcolumn:name="Provider",type="string",JSONPath=".spec.provider"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+
+// ClusterSecretProviderClass is the Schema for the clustersecretproviderclasses API.
+// It can be used by pods in all the namespaces selected by the namespace selector.
+type ClusterSecretProviderClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterSecretProviderClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterSecretProviderClassList contains a list of ClusterSecretProviderClass
+type ClusterSecretProviderClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterSecretProviderClass `json:"items"`
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Hub marks SecretProviderClass as the conversion hub. All other versions
+// of the API are converted to and from v1.
+func (*SecretProviderClass) Hub() {}
+
+// Hub marks SecretProviderClassPodStatus as the conversion hub. All other
+// versions of the API are converted to and from v1.
+func (*SecretProviderClassPodStatus) Hub() {}
+
+// SetupWebhookWithManager registers the conversion webhook for
+// SecretProviderClass with the manager webhook server.
+func (r *SecretProviderClass) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
+
+// SetupWebhookWithManager registers the conversion webhook for
+// SecretProviderClassPodStatus with the manager webhook server.
+func (r *SecretProviderClassPodStatus) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
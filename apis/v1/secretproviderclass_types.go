@@ -0,0 +1,360 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Provider enum for all the provider names
+type Provider string
+
+const (
+	// Azure provider for Azure Key Vault
+	Azure Provider = "Azure"
+	// Vault provider for Hashicorp Vault
+	Vault Provider = "Vault"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// SecretObjectData defines the desired state of synced K8s secret object data
+type SecretObjectData struct {
+	// name of the object to sync
+	ObjectName string `json:"objectName,omitempty"`
+	// data field to populate
+	Key string `json:"key,omitempty"`
+	// Go template used to render the data field value. The mounted objects are
+	// available to the template keyed by object name. When set, objectName is
+	// optional.
+	Template string `json:"template,omitempty"`
+	// JSONPath expression selecting a single field of a JSON or YAML object to
+	// populate the data field with, e.g. {.username}
+	JSONPath string `json:"jsonPath,omitempty"`
+	// ExpandKeys populates a data field for every top-level key of a JSON or
+	// YAML object. When set, key must be empty.
+	ExpandKeys bool `json:"expandKeys,omitempty"`
+	// transformations applied in order to the object content after jsonPath and
+	// before expandKeys
+	Transforms []Transform `json:"transforms,omitempty"`
+}
+
+// TransformType is the type of a secret object data transformation
+// +kubebuilder:validation:Enum=Base64Decode;HexDecode;GzipDecode;PKCS12
+type TransformType string
+
+const (
+	// TransformBase64Decode decodes base64 (standard encoding) content
+	TransformBase64Decode TransformType = "Base64Decode"
+	// TransformHexDecode decodes hex content
+	TransformHexDecode TransformType = "HexDecode"
+	// TransformGzipDecode decompresses gzip content
+	TransformGzipDecode TransformType = "GzipDecode"
+	// TransformPKCS12 unpacks a PKCS#12 (PFX) bundle into PEM
+	TransformPKCS12 TransformType = "PKCS12"
+)
+
+// PKCS12Part is the part of a PKCS#12 bundle to output
+// +kubebuilder:validation:Enum=Cert;Key;CA
+type PKCS12Part string
+
+const (
+	// PKCS12PartCert outputs the leaf certificate
+	PKCS12PartCert PKCS12Part = "Cert"
+	// PKCS12PartKey outputs the private key
+	PKCS12PartKey PKCS12Part = "Key"
+	// PKCS12PartCA outputs the CA chain
+	PKCS12PartCA PKCS12Part = "CA"
+)
+
+// Transform defines a transformation of the secret object data content
+type Transform struct {
+	// type of the transformation
+	Type TransformType `json:"type"`
+	// options for the PKCS12 transformation
+	PKCS12 *PKCS12Transform `json:"pkcs12,omitempty"`
+}
+
+// PKCS12Transform defines the options to unpack a PKCS#12 bundle
+type PKCS12Transform struct {
+	// part of the bundle to output. If not set, the private key, the leaf
+	// certificate and the CA chain are all output in PEM format.
+	Part PKCS12Part `json:"part,omitempty"`
+	// name of the mounted object that contains the bundle password. If not set,
+	// the bundle is decoded with an empty password.
+	PasswordObjectName string `json:"passwordObjectName,omitempty"`
+}
+
+// SecretObject defines the desired state of synced K8s secret objects
+type SecretObject struct {
+	// name of the K8s secret object
+	SecretName string `json:"secretName,omitempty"`
+	// type of K8s secret object
+	Type string `json:"type,omitempty"`
+	// labels of K8s secret object
+	Labels map[string]string `json:"labels,omitempty"`
+	// annotations of K8s secret object
+	Annotations map[string]string   `json:"annotations,omitempty"`
+	Data        []*SecretObjectData `json:"data,omitempty"`
+	// marks the K8s secret object immutable. When the content changes, the secret
+	// is replaced instead of patched.
+	Immutable bool `json:"immutable,omitempty"`
+	// policy when the K8s secret object already exists and isn't managed by the
+	// driver or is owned by another secret provider class. Defaults to Fail.
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// policy for the K8s secret object when it's no longer used by any pod or is
+	// removed from secretObjects. Defaults to Delete.
+	RetainPolicy RetainPolicy `json:"retainPolicy,omitempty"`
+	// builds the .dockerconfigjson data field of a kubernetes.io/dockerconfigjson
+	// secret from mounted objects
+	DockerConfig *DockerConfigSource `json:"dockerConfig,omitempty"`
+	// options for kubernetes.io/tls secrets
+	TLS *TLSOptions `json:"tls,omitempty"`
+}
+
+// ConflictPolicy is the policy when a synced secret or configmap conflicts with an existing
+// secret or configmap
+// +kubebuilder:validation:Enum=Fail;Adopt;Overwrite
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyFail fails the sync and leaves the existing secret unchanged
+	ConflictPolicyFail ConflictPolicy = "Fail"
+	// ConflictPolicyAdopt takes ownership of an existing secret that isn't owned by
+	// another secret provider class and updates it to the desired state
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+	// ConflictPolicyOverwrite replaces the existing secret, even if it's owned by
+	// another secret provider class
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+)
+
+// RetainPolicy is the policy for a synced secret that's no longer in use
+// +kubebuilder:validation:Enum=Retain;Delete
+type RetainPolicy string
+
+const (
+	// RetainPolicyRetain keeps the secret when the last pod using it is deleted
+	// or when it's removed from secretObjects
+	RetainPolicyRetain RetainPolicy = "Retain"
+	// RetainPolicyDelete deletes the secret when the last pod using it is deleted
+	// or when it's removed from secretObjects
+	RetainPolicyDelete RetainPolicy = "Delete"
+)
+
+// TLSKeyFormat is the format of the tls.key private key
+// +kubebuilder:validation:Enum=PKCS1;PKCS8
+type TLSKeyFormat string
+
+const (
+	// TLSKeyFormatPKCS1 outputs RSA keys in PKCS#1 and EC keys in SEC 1 form
+	TLSKeyFormatPKCS1 TLSKeyFormat = "PKCS1"
+	// TLSKeyFormatPKCS8 outputs keys in PKCS#8 form
+	TLSKeyFormatPKCS8 TLSKeyFormat = "PKCS8"
+)
+
+// TLSOptions defines how the kubernetes.io/tls secret data is built
+type TLSOptions struct {
+	// adds the issuer certificates of the leaf certificate to the ca.crt data field
+	IncludeCA bool `json:"includeCA,omitempty"`
+	// name of the object that contains the passphrase of an encrypted PKCS#8
+	// private key
+	PassphraseObjectName string `json:"passphraseObjectName,omitempty"`
+	// format of the tls.key private key. Defaults to PKCS1.
+	KeyFormat TLSKeyFormat `json:"keyFormat,omitempty"`
+}
+
+// DockerConfigSource defines the mounted objects used to build a .dockerconfigjson
+type DockerConfigSource struct {
+	// name of the object that contains the registry server
+	RegistryObjectName string `json:"registryObjectName"`
+	// name of the object that contains the registry username
+	UsernameObjectName string `json:"usernameObjectName"`
+	// name of the object that contains the registry password
+	PasswordObjectName string `json:"passwordObjectName"`
+}
+
+// ConfigMapObject defines the desired state of synced K8s configmap objects
+type ConfigMapObject struct {
+	// name of the K8s configmap object
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// labels of K8s configmap object
+	Labels map[string]string   `json:"labels,omitempty"`
+	Data   []*SecretObjectData `json:"data,omitempty"`
+	// policy when the K8s configmap object already exists and isn't managed by the
+	// driver or is owned by another secret provider class. Defaults to Fail.
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+// SecretProviderClassSpec defines the desired state of SecretProviderClass
+type SecretProviderClassSpec struct {
+	// Configuration for provider name
+	Provider Provider `json:"provider,omitempty"`
+	// Configuration for specific provider
+	Parameters    map[string]string `json:"parameters,omitempty"`
+	SecretObjects []*SecretObject   `json:"secretObjects,omitempty"`
+	// objects that don't contain sensitive data, e.g. CA bundles, that are
+	// synced as K8s configmaps
+	ConfigMapObjects []*ConfigMapObject `json:"configMapObjects,omitempty"`
+	// policy for the rotation of the mounted contents and synced objects of the
+	// pods using the secret provider class
+	RotationPolicy *RotationPolicy `json:"rotationPolicy,omitempty"`
+}
+
+// RotationPolicy defines when the mounted contents and synced objects are rotated.
+// The pods can override the policy with the secrets-store.csi.k8s.io/rotation-*
+// annotations or the rotation* volume attributes.
+type RotationPolicy struct {
+	// enables rotation. Defaults to true if rotation is enabled for the driver.
+	Enabled *bool `json:"enabled,omitempty"`
+	// interval between rotations, e.g. 1h. Defaults to the rotation poll interval
+	// of the driver. Must be at least the rotation min interval of the driver.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// windows the rotation is allowed in. Rotation is allowed at any time if empty.
+	Windows []RotationWindow `json:"windows,omitempty"`
+	// action taken on the workload of a pod after a rotation updates its mounted
+	// contents, so apps that only read them at startup pick up the rotated contents.
+	// +optional
+	PostRotationAction *PostRotationAction `json:"postRotationAction,omitempty"`
+}
+
+// PostRotationActionType is the action taken on the workload of a pod after rotation
+// +kubebuilder:validation:Enum=None;Rollout;Evict
+type PostRotationActionType string
+
+const (
+	// PostRotationActionNone takes no action after rotation
+	PostRotationActionNone PostRotationActionType = "None"
+	// PostRotationActionRollout rolls out the deployment, statefulset, daemonset or
+	// replicaset of the pod by updating an annotation in its pod template
+	PostRotationActionRollout PostRotationActionType = "Rollout"
+	// PostRotationActionEvict evicts the pod, honoring its pod disruption budgets
+	PostRotationActionEvict PostRotationActionType = "Evict"
+)
+
+// PostRotationAction defines the action taken on the workload of a pod after a
+// rotation updates its mounted contents
+type PostRotationAction struct {
+	// type of the action
+	Type PostRotationActionType `json:"type"`
+	// min interval between actions on the same workload, e.g. 10m. Defaults to 5m.
+	// +optional
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+}
+
+// RotationWindow is a time window that starts on a cron schedule
+type RotationWindow struct {
+	// cron schedule of the start of the window in UTC with the 5 standard fields,
+	// e.g. "0 2 * * 1-5" for 2am on weekdays
+	Schedule string `json:"schedule"`
+	// duration of the window, e.g. 2h
+	Duration metav1.Duration `json:"duration"`
+}
+
+const (
+	// ConditionTypeValid indicates whether the SecretProviderClass passed validation
+	ConditionTypeValid = "Valid"
+	// ConditionTypeInUse indicates whether the SecretProviderClass is mounted by any pods
+	ConditionTypeInUse = "InUse"
+	// ConditionTypeProviderAvailable indicates whether the provider socket is present on
+	// all the nodes with pods that mount the SecretProviderClass
+	ConditionTypeProviderAvailable = "ProviderAvailable"
+	// ConditionTypeMountsHealthy indicates whether all the pods that mount the
+	// SecretProviderClass have been mounted successfully
+	ConditionTypeMountsHealthy = "MountsHealthy"
+	// ConditionTypeRotationsHealthy indicates whether the last rotation succeeded for all
+	// the pods that mount the SecretProviderClass
+	ConditionTypeRotationsHealthy = "RotationsHealthy"
+
+	// ValidationSucceededReason is the reason for the Valid condition when the validation succeeds
+	ValidationSucceededReason = "ValidationSucceeded"
+	// PodsMountedReason is the reason for the InUse condition when pods mount the SecretProviderClass
+	PodsMountedReason = "PodsMounted"
+	// NotInUseReason is the reason for the usage conditions when no pods mount the SecretProviderClass
+	NotInUseReason = "NotInUse"
+	// ProviderFoundReason is the reason for the ProviderAvailable condition when the provider is found on all nodes
+	ProviderFoundReason = "ProviderFound"
+	// MountsSucceededReason is the reason for the MountsHealthy condition when all mounts succeeded
+	MountsSucceededReason = "MountsSucceeded"
+	// RotationsSucceededReason is the reason for the RotationsHealthy condition when all rotations succeeded
+	RotationsSucceededReason = "RotationsSucceeded"
+)
+
+// ByPodStatus defines the state of SecretProviderClass as seen by
+// an individual controller
+type ByPodStatus struct {
+	// id of the pod that wrote the status
+	ID string `json:"id,omitempty"`
+	// namespace of the pod that wrote the status
+	Namespace string `json:"namespace,omitempty"`
+	// name of the node the pod that wrote the status runs on
+	NodeName string `json:"nodeName,omitempty"`
+	// number of pods on the node that mount the SecretProviderClass
+	PodCount int32 `json:"podCount,omitempty"`
+	// whether the provider socket is present on the node
+	ProviderAvailable bool `json:"providerAvailable,omitempty"`
+	// number of pods on the node with a failed mount
+	MountErrors int32 `json:"mountErrors,omitempty"`
+	// number of pods on the node with a failed rotation
+	RotationErrors int32 `json:"rotationErrors,omitempty"`
+	// last time the status was written by the pod
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// SecretProviderClassStatus defines the observed state of SecretProviderClass
+type SecretProviderClassStatus struct {
+	ByPod []*ByPodStatus `json:"byPod,omitempty"`
+	// PodCount is the number of pods that mount the SecretProviderClass
+	// +optional
+	PodCount int32 `json:"podCount,omitempty"`
+	// NodeCount is the number of nodes with pods that mount the SecretProviderClass
+	// +optional
+	NodeCount int32 `json:"nodeCount,omitempty"`
+	// Conditions represent the latest available observations of the object's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Provider",type="string",JSONPath=".spec.provider"
+// +kubebuilder:printcolumn:name="Pods",type="integer",JSONPath=".status.podCount"
+// +kubebuilder:printcolumn:name="Nodes",type="integer",JSONPath=".status.nodeCount"
+// +kubebuilder:printcolumn:name="Valid",type="string",JSONPath=".status.conditions[?(@.type==\"Valid\")].status"
+// +kubebuilder:printcolumn:name="Provider Available",type="string",priority=1,JSONPath=".status.conditions[?(@.type==\"ProviderAvailable\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +genclient
+
+// SecretProviderClass is the Schema for the secretproviderclasses API
+type SecretProviderClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SecretProviderClassSpec   `json:"spec,omitempty"`
+	Status SecretProviderClassStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SecretProviderClassList contains a list of SecretProviderClass
+type SecretProviderClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SecretProviderClass `json:"items"`
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+const (
+	// InternalNodeLabel used for setting the node name spc pod status belongs to
+	InternalNodeLabel = "internal.secrets-store.csi.k8s.io/node-name"
+)
+
+const (
+	// ConditionTypeMounted indicates whether the secrets store objects are mounted in the pod
+	ConditionTypeMounted = "Mounted"
+	// ConditionTypeSecretsSynced indicates whether the Kubernetes secrets defined in the
+	// secret provider class secretObjects are synced with the mounted contents
+	ConditionTypeSecretsSynced = "SecretsSynced"
+	// ConditionTypeRotationSucceeded indicates whether the last rotation of the mounted
+	// contents and Kubernetes secrets succeeded
+	ConditionTypeRotationSucceeded = "RotationSucceeded"
+	// ConditionTypeSecretConflict is set when a Kubernetes secret defined in the secret provider
+	// class secretObjects isn't managed by the driver or is owned by another secret provider class
+	ConditionTypeSecretConflict = "SecretConflict"
+	// ConditionTypePostRotationActionSucceeded indicates whether the post rotation action in the
+	// rotation policy was taken on the workload of the pod after the last rotation
+	ConditionTypePostRotationActionSucceeded = "PostRotationActionSucceeded"
+
+	// MountSucceededReason is the reason for the Mounted condition when the mount succeeds
+	MountSucceededReason = "MountSucceeded"
+	// SyncSucceededReason is the reason for the SecretsSynced condition when all secrets are synced
+	SyncSucceededReason = "SyncSucceeded"
+	// RotationSucceededReason is the reason for the RotationSucceeded condition when the rotation succeeds
+	RotationSucceededReason = "RotationSucceeded"
+	// RolloutTriggeredReason is the reason for the PostRotationActionSucceeded condition when
+	// the workload of the pod is rolled out
+	RolloutTriggeredReason = "RolloutTriggered"
+	// PodEvictedReason is the reason for the PostRotationActionSucceeded condition when the pod is evicted
+	PodEvictedReason = "PodEvicted"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// SecretProviderClassPodStatusStatus defines the observed state of SecretProviderClassPodStatus
+type SecretProviderClassPodStatusStatus struct {
+	PodName                 string `json:"podName,omitempty"`
+	SecretProviderClassName string `json:"secretProviderClassName,omitempty"`
+	// SecretProviderClassKind is the kind of the secret provider class referenced by the pod,
+	// either SecretProviderClass or ClusterSecretProviderClass. Defaults to SecretProviderClass.
+	// +optional
+	SecretProviderClassKind string                      `json:"secretProviderClassKind,omitempty"`
+	Mounted                 bool                        `json:"mounted,omitempty"`
+	TargetPath              string                      `json:"targetPath,omitempty"`
+	Objects                 []SecretProviderClassObject `json:"objects,omitempty"`
+	// LastRotationTime is the last time the mounted contents were updated by rotation
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// RotationHistory is the bounded history of the rotations of the mounted contents,
+	// most recent first
+	// +optional
+	RotationHistory []RotationHistoryEntry `json:"rotationHistory,omitempty"`
+	// Conditions represent the latest available observations of the object's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SecretProviderClassObject defines the object fetched from external secrets store
+type SecretProviderClassObject struct {
+	ID      string `json:"id,omitempty"`
+	Version string `json:"version,omitempty"`
+	// ExpiresAt is the time the object version expires, if the provider returned one
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// FetchedAt is the time the object version with its current expiry was fetched. The
+	// lifetime of the object version is the time between FetchedAt and ExpiresAt.
+	// +optional
+	FetchedAt *metav1.Time `json:"fetchedAt,omitempty"`
+}
+
+// RotationResult is the outcome of a rotation
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type RotationResult string
+
+const (
+	// RotationResultSucceeded is the result of a rotation that succeeded
+	RotationResultSucceeded RotationResult = "Succeeded"
+	// RotationResultFailed is the result of a rotation that failed
+	RotationResultFailed RotationResult = "Failed"
+)
+
+// RotationHistoryEntry records a rotation of the mounted contents of the pod
+type RotationHistoryEntry struct {
+	// Time is when the rotation completed
+	Time metav1.Time `json:"time"`
+	// Result is the outcome of the rotation
+	Result RotationResult `json:"result"`
+	// Reason is the reason code of the outcome of the rotation
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Objects are the objects whose version changed in the rotation
+	// +optional
+	Objects []RotationHistoryObject `json:"objects,omitempty"`
+}
+
+// RotationHistoryObject defines the change of version of an object fetched from external secrets store
+type RotationHistoryObject struct {
+	ID string `json:"id"`
+	// OldVersion is the version before the rotation. Empty if the object was added.
+	// +optional
+	OldVersion string `json:"oldVersion,omitempty"`
+	// NewVersion is the version after the rotation. Empty if the object was removed.
+	// +optional
+	NewVersion string `json:"newVersion,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName=spcps
+// +kubebuilder:printcolumn:name="Pod",type="string",JSONPath=".status.podName"
+// +kubebuilder:printcolumn:name="SecretProviderClass",type="string",JSONPath=".status.secretProviderClassName"
+// +kubebuilder:printcolumn:name="Mounted",type="string",JSONPath=".status.conditions[?(@.type==\"Mounted\")].status"
+// +kubebuilder:printcolumn:name="Synced",type="string",JSONPath=".status.conditions[?(@.type==\"SecretsSynced\")].status"
+// +kubebuilder:printcolumn:name="Rotated",type="string",JSONPath=".status.conditions[?(@.type==\"RotationSucceeded\")].status"
+// +kubebuilder:printcolumn:name="Rotation Reason",type="string",priority=1,JSONPath=".status.conditions[?(@.type==\"RotationSucceeded\")].reason"
+// +kubebuilder:printcolumn:name="Contents Updated",type="date",JSONPath=".status.lastRotationTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +genclient
+
+// SecretProviderClassPodStatus is the Schema for the secretproviderclassespodstatus API
+type SecretProviderClassPodStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status SecretProviderClassPodStatusStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SecretProviderClassPodStatusList contains a list of SecretProviderClassPodStatus
+type SecretProviderClassPodStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SecretProviderClassPodStatus `json:"items"`
+}
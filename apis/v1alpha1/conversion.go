@@ -0,0 +1,288 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+// ConversionDataAnnotation holds the v1 spec and status of an object read as v1alpha1 that can't
+// be represented in v1alpha1, so the v1 only fields are kept when the object is written back as
+// v1alpha1. It's only set if the conversion would lose data.
+const ConversionDataAnnotation = "secrets-store.csi.x-k8s.io/conversion-data"
+
+// secretProviderClassConversionData is the v1 data of a secret provider class stored in the
+// conversion data annotation
+type secretProviderClassConversionData struct {
+	Spec   secretsstorev1.SecretProviderClassSpec   `json:"spec,omitempty"`
+	Status secretsstorev1.SecretProviderClassStatus `json:"status,omitempty"`
+}
+
+// secretProviderClassPodStatusConversionData is the v1 data of a secret provider class pod status
+// stored in the conversion data annotation
+type secretProviderClassPodStatusConversionData struct {
+	Status secretsstorev1.SecretProviderClassPodStatusStatus `json:"status,omitempty"`
+}
+
+// ConvertTo converts this SecretProviderClass to the Hub version (v1).
+func (src *SecretProviderClass) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*secretsstorev1.SecretProviderClass)
+	if !ok {
+		return fmt.Errorf("failed to cast %T to %s", dstRaw, "v1 secretproviderclass")
+	}
+
+	// the v1 only fields are restored and the fields that exist in v1alpha1 are set from src,
+	// as they may have been updated using v1alpha1
+	data := &secretProviderClassConversionData{}
+	if err := restoreConversionData(src.ObjectMeta, &dst.ObjectMeta, data); err != nil {
+		return err
+	}
+	dst.Spec = data.Spec
+	dst.Status = data.Status
+	dst.Spec.Provider = secretsstorev1.Provider(src.Spec.Provider)
+	dst.Spec.Parameters = src.Spec.Parameters
+
+	restoredSecretObjects := make(map[string]*secretsstorev1.SecretObject, len(data.Spec.SecretObjects))
+	for _, so := range data.Spec.SecretObjects {
+		if so != nil {
+			restoredSecretObjects[so.SecretName] = so
+		}
+	}
+	dst.Spec.SecretObjects = nil
+	for _, so := range src.Spec.SecretObjects {
+		if so == nil {
+			continue
+		}
+		obj, ok := restoredSecretObjects[so.SecretName]
+		if !ok {
+			obj = &secretsstorev1.SecretObject{}
+		}
+		delete(restoredSecretObjects, so.SecretName)
+		obj.SecretName = so.SecretName
+		obj.Type = so.Type
+		obj.Labels = so.Labels
+
+		var restoredData []*secretsstorev1.SecretObjectData
+		for _, d := range obj.Data {
+			if d != nil {
+				restoredData = append(restoredData, d)
+			}
+		}
+		obj.Data = nil
+		for _, d := range so.Data {
+			if d == nil {
+				continue
+			}
+			// the data of a secret object has no key in v1alpha1, so it's matched by position
+			objData := &secretsstorev1.SecretObjectData{}
+			if i := len(obj.Data); i < len(restoredData) && restoredData[i].ObjectName == d.ObjectName && restoredData[i].Key == d.Key {
+				objData = restoredData[i]
+			}
+			objData.ObjectName = d.ObjectName
+			objData.Key = d.Key
+			obj.Data = append(obj.Data, objData)
+		}
+		dst.Spec.SecretObjects = append(dst.Spec.SecretObjects, obj)
+	}
+
+	restoredByPod := make(map[string]*secretsstorev1.ByPodStatus, len(data.Status.ByPod))
+	for _, bp := range data.Status.ByPod {
+		if bp != nil {
+			restoredByPod[bp.Namespace+"/"+bp.ID] = bp
+		}
+	}
+	dst.Status.ByPod = nil
+	for _, bp := range src.Status.ByPod {
+		if bp == nil {
+			continue
+		}
+		byPod, ok := restoredByPod[bp.Namespace+"/"+bp.ID]
+		if !ok {
+			byPod = &secretsstorev1.ByPodStatus{}
+		}
+		delete(restoredByPod, bp.Namespace+"/"+bp.ID)
+		byPod.ID = bp.ID
+		byPod.Namespace = bp.Namespace
+		dst.Status.ByPod = append(dst.Status.ByPod, byPod)
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this SecretProviderClass.
+func (dst *SecretProviderClass) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*secretsstorev1.SecretProviderClass)
+	if !ok {
+		return fmt.Errorf("failed to cast %T to %s", srcRaw, "v1 secretproviderclass")
+	}
+
+	dst.ObjectMeta = withoutConversionData(src.ObjectMeta)
+	dst.Spec.Provider = Provider(src.Spec.Provider)
+	dst.Spec.Parameters = src.Spec.Parameters
+	dst.Spec.SecretObjects = nil
+	for _, so := range src.Spec.SecretObjects {
+		if so == nil {
+			continue
+		}
+		obj := &SecretObject{
+			SecretName: so.SecretName,
+			Type:       so.Type,
+			Labels:     so.Labels,
+		}
+		for _, d := range so.Data {
+			if d == nil {
+				continue
+			}
+			obj.Data = append(obj.Data, &SecretObjectData{
+				ObjectName: d.ObjectName,
+				Key:        d.Key,
+			})
+		}
+		dst.Spec.SecretObjects = append(dst.Spec.SecretObjects, obj)
+	}
+	dst.Status.ByPod = nil
+	for _, bp := range src.Status.ByPod {
+		if bp == nil {
+			continue
+		}
+		dst.Status.ByPod = append(dst.Status.ByPod, &ByPodStatus{
+			ID:        bp.ID,
+			Namespace: bp.Namespace,
+		})
+	}
+
+	// keep the v1 only fields if converting back wouldn't restore the same object
+	converted := &secretsstorev1.SecretProviderClass{}
+	if err := dst.ConvertTo(converted); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(converted.Spec, src.Spec) && equality.Semantic.DeepEqual(converted.Status, src.Status) {
+		return nil
+	}
+	return setConversionData(&dst.ObjectMeta, &secretProviderClassConversionData{Spec: src.Spec, Status: src.Status})
+}
+
+// ConvertTo converts this SecretProviderClassPodStatus to the Hub version (v1).
+func (src *SecretProviderClassPodStatus) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*secretsstorev1.SecretProviderClassPodStatus)
+	if !ok {
+		return fmt.Errorf("failed to cast %T to %s", dstRaw, "v1 secretproviderclasspodstatus")
+	}
+
+	data := &secretProviderClassPodStatusConversionData{}
+	if err := restoreConversionData(src.ObjectMeta, &dst.ObjectMeta, data); err != nil {
+		return err
+	}
+	dst.Status = data.Status
+	dst.Status.PodName = src.Status.PodName
+	dst.Status.SecretProviderClassName = src.Status.SecretProviderClassName
+	dst.Status.Mounted = src.Status.Mounted
+	dst.Status.TargetPath = src.Status.TargetPath
+
+	restoredObjects := make(map[string]secretsstorev1.SecretProviderClassObject, len(data.Status.Objects))
+	for _, o := range data.Status.Objects {
+		restoredObjects[o.ID] = o
+	}
+	dst.Status.Objects = nil
+	for _, o := range src.Status.Objects {
+		obj := secretsstorev1.SecretProviderClassObject{
+			ID:      o.ID,
+			Version: o.Version,
+		}
+		// the expiry is only kept for the same object version
+		if restored, ok := restoredObjects[o.ID]; ok && restored.Version == o.Version {
+			obj.ExpiresAt = restored.ExpiresAt
+			obj.FetchedAt = restored.FetchedAt
+		}
+		dst.Status.Objects = append(dst.Status.Objects, obj)
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this SecretProviderClassPodStatus.
+func (dst *SecretProviderClassPodStatus) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*secretsstorev1.SecretProviderClassPodStatus)
+	if !ok {
+		return fmt.Errorf("failed to cast %T to %s", srcRaw, "v1 secretproviderclasspodstatus")
+	}
+
+	dst.ObjectMeta = withoutConversionData(src.ObjectMeta)
+	dst.Status.PodName = src.Status.PodName
+	dst.Status.SecretProviderClassName = src.Status.SecretProviderClassName
+	dst.Status.Mounted = src.Status.Mounted
+	dst.Status.TargetPath = src.Status.TargetPath
+	dst.Status.Objects = nil
+	for _, o := range src.Status.Objects {
+		dst.Status.Objects = append(dst.Status.Objects, SecretProviderClassObject{
+			ID:      o.ID,
+			Version: o.Version,
+		})
+	}
+
+	// keep the v1 only fields if converting back wouldn't restore the same object
+	converted := &secretsstorev1.SecretProviderClassPodStatus{}
+	if err := dst.ConvertTo(converted); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(converted.Status, src.Status) {
+		return nil
+	}
+	return setConversionData(&dst.ObjectMeta, &secretProviderClassPodStatusConversionData{Status: src.Status})
+}
+
+// withoutConversionData returns a copy of the object meta without the conversion data annotation.
+func withoutConversionData(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	meta = *meta.DeepCopy()
+	delete(meta.Annotations, ConversionDataAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	return meta
+}
+
+// setConversionData stores the data in the conversion data annotation of the object meta.
+func setConversionData(meta *metav1.ObjectMeta, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion data, err: %+v", err)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[ConversionDataAnnotation] = string(b)
+	return nil
+}
+
+// restoreConversionData sets dst to the src object meta without the conversion data annotation
+// and unmarshals the annotation, if any, into data.
+func restoreConversionData(src metav1.ObjectMeta, dst *metav1.ObjectMeta, data interface{}) error {
+	*dst = withoutConversionData(src)
+	value, ok := src.Annotations[ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), data); err != nil {
+		return fmt.Errorf("failed to unmarshal conversion data annotation %s, err: %+v", ConversionDataAnnotation, err)
+	}
+	return nil
+}
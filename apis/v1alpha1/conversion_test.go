@@ -0,0 +1,241 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestSecretProviderClassConversion(t *testing.T) {
+	spc := &SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+		Spec: SecretProviderClassSpec{
+			Provider:   "provider1",
+			Parameters: map[string]string{"parameter1": "value1"},
+			SecretObjects: []*SecretObject{
+				{
+					SecretName: "secret1",
+					Type:       "Opaque",
+					Labels:     map[string]string{"environment": "test"},
+					Data:       []*SecretObjectData{{ObjectName: "object1", Key: "key1"}},
+				},
+			},
+		},
+		Status: SecretProviderClassStatus{
+			ByPod: []*ByPodStatus{{ID: "pod1", Namespace: "default"}},
+		},
+	}
+
+	hub := &secretsstorev1.SecretProviderClass{}
+	if err := spc.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got, want := string(hub.Spec.Provider), "provider1"; got != want {
+		t.Errorf("ConvertTo() provider = %q, want %q", got, want)
+	}
+	if got, want := hub.Spec.SecretObjects[0].Data[0].Key, "key1"; got != want {
+		t.Errorf("ConvertTo() secret object data key = %q, want %q", got, want)
+	}
+
+	got := &SecretProviderClass{}
+	if err := got.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if diff := cmp.Diff(spc, got); diff != "" {
+		t.Errorf("round trip conversion mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSecretProviderClassPodStatusConversion(t *testing.T) {
+	spcps := &SecretProviderClassPodStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1-default-spc1",
+			Namespace: "default",
+			Labels:    map[string]string{InternalNodeLabel: "node1"},
+		},
+		Status: SecretProviderClassPodStatusStatus{
+			PodName:                 "pod1",
+			SecretProviderClassName: "spc1",
+			Mounted:                 true,
+			TargetPath:              "/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/vol/mount",
+			Objects:                 []SecretProviderClassObject{{ID: "secret/object1", Version: "v1"}},
+		},
+	}
+
+	hub := &secretsstorev1.SecretProviderClassPodStatus{}
+	if err := spcps.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got, want := hub.Status.Objects[0].Version, "v1"; got != want {
+		t.Errorf("ConvertTo() object version = %q, want %q", got, want)
+	}
+
+	got := &SecretProviderClassPodStatus{}
+	if err := got.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if diff := cmp.Diff(spcps, got); diff != "" {
+		t.Errorf("round trip conversion mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSecretProviderClassHubRoundTrip(t *testing.T) {
+	enabled := true
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	spc := &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default", Annotations: map[string]string{"foo": "bar"}},
+		Spec: secretsstorev1.SecretProviderClassSpec{
+			Provider:   "provider1",
+			Parameters: map[string]string{"parameter1": "value1"},
+			SecretObjects: []*secretsstorev1.SecretObject{
+				{
+					SecretName:  "secret1",
+					Type:        "kubernetes.io/tls",
+					Labels:      map[string]string{"environment": "test"},
+					Annotations: map[string]string{"team": "a"},
+					Data: []*secretsstorev1.SecretObjectData{
+						{ObjectName: "object1", Key: "tls.crt", JSONPath: "{.cert}", Transforms: []secretsstorev1.Transform{
+							{Type: secretsstorev1.TransformPKCS12, PKCS12: &secretsstorev1.PKCS12Transform{Part: secretsstorev1.PKCS12PartCert, PasswordObjectName: "password"}},
+						}},
+						{Key: "tls.key", Template: "{{ .object2 }}"},
+						{ObjectName: "object3", ExpandKeys: true},
+					},
+					Immutable:      true,
+					ConflictPolicy: secretsstorev1.ConflictPolicyAdopt,
+					RetainPolicy:   secretsstorev1.RetainPolicyRetain,
+					TLS:            &secretsstorev1.TLSOptions{IncludeCA: true, PassphraseObjectName: "passphrase", KeyFormat: secretsstorev1.TLSKeyFormatPKCS8},
+				},
+				{
+					SecretName:   "secret2",
+					Type:         "kubernetes.io/dockerconfigjson",
+					DockerConfig: &secretsstorev1.DockerConfigSource{RegistryObjectName: "registry", UsernameObjectName: "username", PasswordObjectName: "password"},
+				},
+			},
+			ConfigMapObjects: []*secretsstorev1.ConfigMapObject{
+				{ConfigMapName: "configmap1", Labels: map[string]string{"environment": "test"}, Data: []*secretsstorev1.SecretObjectData{{ObjectName: "object1", Key: "key1"}}, ConflictPolicy: secretsstorev1.ConflictPolicyAdopt},
+			},
+			RotationPolicy: &secretsstorev1.RotationPolicy{
+				Enabled:            &enabled,
+				Interval:           &metav1.Duration{Duration: time.Hour},
+				Windows:            []secretsstorev1.RotationWindow{{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}}},
+				PostRotationAction: &secretsstorev1.PostRotationAction{Type: secretsstorev1.PostRotationActionRollout, MinInterval: &metav1.Duration{Duration: time.Minute}},
+			},
+		},
+		Status: secretsstorev1.SecretProviderClassStatus{
+			ByPod: []*secretsstorev1.ByPodStatus{
+				{ID: "driver1", Namespace: "kube-system", NodeName: "node1", PodCount: 2, ProviderAvailable: true, MountErrors: 1, RotationErrors: 1, LastUpdateTime: &now},
+			},
+			PodCount:   2,
+			NodeCount:  1,
+			Conditions: []metav1.Condition{{Type: secretsstorev1.ConditionTypeValid, Status: metav1.ConditionTrue, Reason: secretsstorev1.ValidationSucceededReason, LastTransitionTime: now}},
+		},
+	}
+
+	spoke := &SecretProviderClass{}
+	if err := spoke.ConvertFrom(spc.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if _, ok := spoke.Annotations[ConversionDataAnnotation]; !ok {
+		t.Fatalf("ConvertFrom() expected the %s annotation to be set", ConversionDataAnnotation)
+	}
+	got := &secretsstorev1.SecretProviderClass{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if diff := cmp.Diff(spc, got); diff != "" {
+		t.Errorf("round trip conversion mismatch (-want +got):\n%s", diff)
+	}
+
+	// the fields updated using v1alpha1 are kept with the v1 only fields
+	spoke.Spec.Parameters = map[string]string{"parameter1": "value2"}
+	spoke.Spec.SecretObjects = spoke.Spec.SecretObjects[1:]
+	got = &secretsstorev1.SecretProviderClass{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	want := spc.DeepCopy()
+	want.Spec.Parameters = map[string]string{"parameter1": "value2"}
+	want.Spec.SecretObjects = want.Spec.SecretObjects[1:]
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("conversion of the updated object mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSecretProviderClassPodStatusHubRoundTrip(t *testing.T) {
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	spcps := &secretsstorev1.SecretProviderClassPodStatus{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1-default-spc1", Namespace: "default"},
+		Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+			PodName:                 "pod1",
+			SecretProviderClassName: "spc1",
+			SecretProviderClassKind: secretsstorev1.SecretProviderClassKind,
+			Mounted:                 true,
+			TargetPath:              "/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/vol/mount",
+			Objects: []secretsstorev1.SecretProviderClassObject{
+				{ID: "secret/object1", Version: "v1", ExpiresAt: &now, FetchedAt: &now},
+				{ID: "secret/object2", Version: "v1"},
+			},
+			LastRotationTime: &now,
+			RotationHistory: []secretsstorev1.RotationHistoryEntry{
+				{Time: now, Result: secretsstorev1.RotationResultSucceeded, Reason: secretsstorev1.RotationSucceededReason, Objects: []secretsstorev1.RotationHistoryObject{{ID: "secret/object1", OldVersion: "v0", NewVersion: "v1"}}},
+			},
+			Conditions: []metav1.Condition{{Type: secretsstorev1.ConditionTypeMounted, Status: metav1.ConditionTrue, Reason: secretsstorev1.MountSucceededReason, LastTransitionTime: now}},
+		},
+	}
+
+	spoke := &SecretProviderClassPodStatus{}
+	if err := spoke.ConvertFrom(spcps.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	got := &secretsstorev1.SecretProviderClassPodStatus{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if diff := cmp.Diff(spcps, got); diff != "" {
+		t.Errorf("round trip conversion mismatch (-want +got):\n%s", diff)
+	}
+
+	// the expiry isn't kept for an object version updated using v1alpha1
+	spoke.Status.Objects[0].Version = "v2"
+	got = &secretsstorev1.SecretProviderClassPodStatus{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got.Status.Objects[0].ExpiresAt != nil || got.Status.Objects[0].FetchedAt != nil {
+		t.Errorf("ConvertTo() expected the expiry of the updated object to be dropped, got: %+v", got.Status.Objects[0])
+	}
+}
+
+func TestConversionDataAnnotationLossless(t *testing.T) {
+	spc := &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+		Spec:       secretsstorev1.SecretProviderClassSpec{Provider: "provider1"},
+	}
+	spoke := &SecretProviderClass{}
+	if err := spoke.ConvertFrom(spc); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if spoke.Annotations != nil {
+		t.Errorf("ConvertFrom() expected no annotations for a lossless conversion, got: %v", spoke.Annotations)
+	}
+}
@@ -22,12 +22,14 @@ import (
 	"fmt"
 	"net/http"
 	_ "net/http/pprof" // #nosec
+	"os"
 	"time"
 
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/cache"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/metrics"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/rotation"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/version"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/webhook"
 
 	"google.golang.org/grpc"
 	"k8s.io/apimachinery/pkg/fields"
@@ -38,27 +40,34 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
 	"sigs.k8s.io/secrets-store-csi-driver/controllers"
 	secretsstore "sigs.k8s.io/secrets-store-csi-driver/pkg/secrets-store"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
 	// +kubebuilder:scaffold:imports
 )
 
 var (
 	endpoint           = flag.String("endpoint", "unix://tmp/csi.sock", "CSI endpoint")
-	driverName         = flag.String("drivername", "secrets-store.csi.k8s.io", "name of the driver")
+	driverName         = flag.String("drivername", k8sutil.DriverName, "name of the driver")
 	nodeID             = flag.String("nodeid", "", "node id")
 	debug              = flag.Bool("debug", false, "sets log to debug level [DEPRECATED]. Use -v=<log level> to configure log level.")
 	logFormatJSON      = flag.Bool("log-format-json", false, "set log formatter to json")
 	providerVolumePath = flag.String("provider-volume", "/etc/kubernetes/secrets-store-csi-providers", "Volume path for provider")
 	// this will be removed in a future release
-	metricsAddr          = flag.String("metrics-addr", ":8095", "The address the metric endpoint binds to")
-	_                    = flag.String("grpc-supported-providers", "", "[DEPRECATED] set list of providers that support grpc for driver-provider [alpha]")
-	enableSecretRotation = flag.Bool("enable-secret-rotation", false, "Enable secret rotation feature [alpha]")
-	rotationPollInterval = flag.Duration("rotation-poll-interval", 2*time.Minute, "Secret rotation poll interval duration")
-	enableProfile        = flag.Bool("enable-pprof", false, "enable pprof profiling")
-	profilePort          = flag.Int("pprof-port", 6065, "port for pprof profiling")
-	maxCallRecvMsgSize   = flag.Int("max-call-recv-msg-size", 1024*1024*4, "maximum size in bytes of gRPC response from plugins")
+	metricsAddr            = flag.String("metrics-addr", ":8095", "The address the metric endpoint binds to")
+	_                      = flag.String("grpc-supported-providers", "", "[DEPRECATED] set list of providers that support grpc for driver-provider [alpha]")
+	enableSecretRotation   = flag.Bool("enable-secret-rotation", false, "Enable secret rotation feature [alpha]")
+	rotationPollInterval   = flag.Duration("rotation-poll-interval", 2*time.Minute, "Secret rotation poll interval duration")
+	rotationMinInterval    = flag.Duration("rotation-min-interval", time.Minute, "Shortest rotation interval a rotation policy, pod annotation or volume attribute can set. Shorter intervals are raised to it")
+	rotationWorkers        = flag.Int("rotation-workers", 1, "Number of workers that rotate secrets concurrently")
+	rotationJitterFactor   = flag.Float64("rotation-jitter-factor", 0.1, "Max fraction of the rotation interval added to each rotation to spread them over time, between 0 and 1")
+	rotationMaxPerProvider = flag.Int("rotation-max-concurrent-per-provider", 0, "Max number of concurrent secret rotations per provider. 0 means no limit other than --rotation-workers")
+	rotationExpiryFraction = flag.Float64("rotation-expiry-refresh-fraction", 0.75, "Fraction of the lifetime of an object with an expiry after which it's refreshed regardless of the rotation poll interval, greater than 0 and at most 1")
+	enableProfile          = flag.Bool("enable-pprof", false, "enable pprof profiling")
+	profilePort            = flag.Int("pprof-port", 6065, "port for pprof profiling")
+	maxCallRecvMsgSize     = flag.Int("max-call-recv-msg-size", 1024*1024*4, "maximum size in bytes of gRPC response from plugins")
 
 	// enable filtered watch for NodePublishSecretRef secrets. The filtering is done on the csi driver label: secrets-store.csi.k8s.io/used=true
 	// For Kubernetes secrets used to provide credentials for use with the CSI driver, set the label by running: kubectl label secret secrets-store-creds secrets-store.csi.k8s.io/used=true
@@ -69,19 +78,33 @@ var (
 	providerHealthCheck         = flag.Bool("provider-health-check", false, "Enable health check for configured providers")
 	providerHealthCheckInterval = flag.Duration("provider-health-check-interval", 2*time.Minute, "Provider healthcheck interval duration")
 
+	// Serve the conversion webhook for the secrets-store.csi.x-k8s.io CRDs. The CRDs need to be
+	// configured with the webhook conversion strategy pointing to the driver service for this to be used.
+	enableConversionWebhook = flag.Bool("enable-conversion-webhook", false, "Enable conversion webhook for SecretProviderClass and SecretProviderClassPodStatus")
+	// Serve the validating webhook for SecretProviderClass. The ValidatingWebhookConfiguration
+	// needs to point to the driver service for invalid objects to be rejected at apply time.
+	enableValidatingWebhook = flag.Bool("enable-validating-webhook", false, "Enable validating webhook for SecretProviderClass")
+	webhookPort             = flag.Int("webhook-port", 9443, "The port the webhook server binds to")
+	webhookCertDir          = flag.String("webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "Directory containing tls.crt and tls.key used by the webhook server")
+
 	scheme = runtime.NewScheme()
 )
 
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = v1alpha1.AddToScheme(scheme)
+	_ = secretsstorev1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
-	klog.InitFlags(nil)
 	defer klog.Flush()
+	if len(os.Args) > 1 && os.Args[1] == webhookCommand {
+		runWebhook(os.Args[2:])
+		return
+	}
 
+	klog.InitFlags(nil)
 	flag.Parse()
 
 	if *logFormatJSON {
@@ -118,16 +141,21 @@ func main() {
 	labelSelectorByResource := map[schema.GroupResource]string{
 		// this enables filtered watch of secretproviderclasspodstatuses based on the internal node label
 		// internal.secrets-store.csi.k8s.io/node-name=<node name> added by csi driver
-		{Group: v1alpha1.GroupVersion.Group, Resource: "secretproviderclasspodstatuses"}: fmt.Sprintf("%s=%s", v1alpha1.InternalNodeLabel, *nodeID),
+		{Group: secretsstorev1.GroupVersion.Group, Resource: "secretproviderclasspodstatuses"}: fmt.Sprintf("%s=%s", secretsstorev1.InternalNodeLabel, *nodeID),
 		// this enables filtered watch of secrets based on the label (secrets-store.csi.k8s.io/managed=true)
 		// added to the secrets created by the CSI driver
 		{Group: "", Resource: "secrets"}: fmt.Sprintf("%s=true", controllers.SecretManagedLabel),
+		// this enables filtered watch of configmaps based on the label (secrets-store.csi.k8s.io/managed=true)
+		// added to the configmaps created by the CSI driver
+		{Group: "", Resource: "configmaps"}: fmt.Sprintf("%s=true", controllers.SecretManagedLabel),
 	}
 
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: *metricsAddr,
 		LeaderElection:     false,
+		Port:               *webhookPort,
+		CertDir:            *webhookCertDir,
 		NewCache: cache.Builder(cache.Options{
 			FieldSelectorByResource: fieldSelectorByResource,
 			LabelSelectorByResource: labelSelectorByResource,
@@ -137,6 +165,10 @@ func main() {
 		klog.Fatalf("failed to start manager, error: %+v", err)
 	}
 
+	// create provider clients
+	providerClients := secretsstore.NewPluginClientBuilder(*providerVolumePath, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(*maxCallRecvMsgSize)))
+	defer providerClients.Cleanup()
+
 	reconciler, err := controllers.New(mgr, *nodeID)
 	if err != nil {
 		klog.Fatalf("failed to create secret provider class pod status reconciler, error: %+v", err)
@@ -144,14 +176,35 @@ func main() {
 	if err = reconciler.SetupWithManager(mgr); err != nil {
 		klog.Fatalf("failed to create controller, error: %+v", err)
 	}
+	// the driver pod name and namespace are set using the downward API and identify
+	// the status written by this driver pod in the secret provider class status
+	podName := os.Getenv("POD_NAME")
+	if podName == "" {
+		if podName, err = os.Hostname(); err != nil {
+			klog.Fatalf("failed to get hostname, error: %+v", err)
+		}
+	}
+	spcStatusReconciler := controllers.NewSecretProviderClassStatusReconciler(mgr, *nodeID, podName, os.Getenv("POD_NAMESPACE"), providerClients, *rotationMinInterval)
+	if err = spcStatusReconciler.SetupWithManager(mgr); err != nil {
+		klog.Fatalf("failed to create secret provider class status controller, error: %+v", err)
+	}
+	if *enableConversionWebhook {
+		klog.InfoS("conversion webhook enabled", "port", *webhookPort)
+		if err = (&secretsstorev1.SecretProviderClass{}).SetupWebhookWithManager(mgr); err != nil {
+			klog.Fatalf("failed to create conversion webhook for secret provider class, error: %+v", err)
+		}
+		if err = (&secretsstorev1.SecretProviderClassPodStatus{}).SetupWebhookWithManager(mgr); err != nil {
+			klog.Fatalf("failed to create conversion webhook for secret provider class pod status, error: %+v", err)
+		}
+	}
+	if *enableValidatingWebhook {
+		klog.InfoS("validating webhook enabled", "port", *webhookPort)
+		(&webhook.SecretProviderClassValidator{MinRotationInterval: *rotationMinInterval}).SetupWithManager(mgr)
+	}
 	// +kubebuilder:scaffold:builder
 
 	ctx := withShutdownSignal(context.Background())
 
-	// create provider clients
-	providerClients := secretsstore.NewPluginClientBuilder(*providerVolumePath, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(*maxCallRecvMsgSize)))
-	defer providerClients.Cleanup()
-
 	// enable provider health check
 	if *providerHealthCheck {
 		klog.InfoS("provider health check enabled", "interval", *providerHealthCheckInterval)
@@ -170,7 +223,7 @@ func main() {
 	}()
 
 	if *enableSecretRotation {
-		rec, err := rotation.NewReconciler(scheme, *providerVolumePath, *nodeID, *rotationPollInterval, providerClients, *filteredWatchSecret)
+		rec, err := rotation.NewReconciler(scheme, *providerVolumePath, *nodeID, *rotationPollInterval, *rotationMinInterval, providerClients, *filteredWatchSecret, *rotationWorkers, *rotationMaxPerProvider, *rotationJitterFactor, *rotationExpiryFraction)
 		if err != nil {
 			klog.Fatalf("failed to initialize rotation reconciler, error: %+v", err)
 		}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/version"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/webhook"
+)
+
+// webhookCommand is the subcommand that runs the mutating webhook that injects the
+// secrets-store volume into pods: secrets-store-csi-driver webhook [flags]
+const webhookCommand = "webhook"
+
+// runWebhook runs the mutating webhook server until the process receives a termination signal.
+// The webhook is deployed separately from the driver daemonset, as it serves the admission
+// requests for pods on all the nodes.
+func runWebhook(args []string) {
+	fs := flag.NewFlagSet(webhookCommand, flag.ExitOnError)
+	port := fs.Int("port", 9443, "The port the webhook server binds to")
+	certDir := fs.String("cert-dir", "/tmp/k8s-webhook-server/serving-certs", "Directory containing tls.crt and tls.key used by the webhook server")
+	metricsAddr := fs.String("metrics-addr", ":8095", "The address the metric endpoint binds to")
+	klog.InitFlags(fs)
+	// flag.ExitOnError exits on parse errors
+	_ = fs.Parse(args)
+
+	cfg := ctrl.GetConfigOrDie()
+	cfg.UserAgent = version.GetUserAgent(webhookCommand)
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: *metricsAddr,
+		LeaderElection:     false,
+		Port:               *port,
+		CertDir:            *certDir,
+	})
+	if err != nil {
+		klog.Fatalf("failed to start manager, error: %+v", err)
+	}
+	(&webhook.PodInjector{}).SetupWithManager(mgr)
+
+	klog.InfoS("starting pod mutating webhook", "port", *port, "path", webhook.MutatePodPath)
+	if err = mgr.Start(withShutdownSignal(context.Background())); err != nil {
+		klog.Fatalf("failed to run manager, error: %+v", err)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/spcpsutil"
+)
+
+const (
+	// ObjectVersionsAnnotation is set on the synced secrets to the JSON encoded map of the
+	// mounted object IDs to their versions
+	ObjectVersionsAnnotation = "secrets-store.csi.k8s.io/object-versions"
+	// LastSyncTimeAnnotation is set on the synced secrets to the RFC 3339 time the secret
+	// data was last written
+	LastSyncTimeAnnotation = "secrets-store.csi.k8s.io/last-sync-time"
+	// SecretOwnerAnnotation is set on the synced secrets and configmaps to the <kind>/<name> of
+	// the secret provider class they are synced from and owned by. Together with the managed label,
+	// it's used to detect objects that aren't managed by the driver or are owned by another secret
+	// provider class.
+	SecretOwnerAnnotation = "secrets-store.csi.k8s.io/owner"
+	// RetainPolicyAnnotation is set on the synced secrets to the retain policy of the secret
+	// object, so the policy is known after the secret object is removed from the secret
+	// provider class
+	RetainPolicyAnnotation = "secrets-store.csi.k8s.io/retain-policy"
+)
+
+// SecretAnnotations returns the annotations for the secret object synced from the objects mounted
+// for the spc pod status. The user specified annotations are merged with the provenance annotations
+// and the provenance annotations take precedence.
+func SecretAnnotations(secretObj *secretsstorev1.SecretObject, spcPodStatus *secretsstorev1.SecretProviderClassPodStatus, syncTime time.Time) (map[string]string, error) {
+	versions, err := objectVersions(spcPodStatus)
+	if err != nil {
+		return nil, err
+	}
+	owner := SecretOwner(spcPodStatus)
+
+	annotationsMap := make(map[string]string, len(secretObj.Annotations)+4)
+	for k, v := range secretObj.Annotations {
+		annotationsMap[k] = v
+	}
+	annotationsMap[SecretOwnerAnnotation] = owner
+	annotationsMap[ObjectVersionsAnnotation] = versions
+	annotationsMap[LastSyncTimeAnnotation] = syncTime.UTC().Format(time.RFC3339)
+	annotationsMap[RetainPolicyAnnotation] = string(SecretRetainPolicy(secretObj))
+	return annotationsMap, nil
+}
+
+// ConfigMapAnnotations returns the provenance annotations for the configmap object synced from the
+// objects mounted for the spc pod status
+func ConfigMapAnnotations(spcPodStatus *secretsstorev1.SecretProviderClassPodStatus, syncTime time.Time) (map[string]string, error) {
+	versions, err := objectVersions(spcPodStatus)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		SecretOwnerAnnotation:    SecretOwner(spcPodStatus),
+		ObjectVersionsAnnotation: versions,
+		LastSyncTimeAnnotation:   syncTime.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// objectVersions returns the JSON encoded map of the object IDs mounted for the spc pod status
+// to their versions
+func objectVersions(spcPodStatus *secretsstorev1.SecretProviderClassPodStatus) (string, error) {
+	// json encoding sorts the map keys, so the annotation value is deterministic
+	versions, err := json.Marshal(spcpsutil.ObjectVersions(spcPodStatus.Status.Objects))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal object versions, err: %+v", err)
+	}
+	return string(versions), nil
+}
+
+// SyncedFromNewerVersions returns true if the data of the synced secret or configmap was last
+// written from object versions other than the ones mounted for the spc pod status, and the mounted
+// objects were fetched before that write, e.g. when the driver on another node rotated the objects
+// first. The data of such an object must not be replaced with the older mounted contents. The
+// mounted versions are considered newer if the object doesn't record the versions or the sync time,
+// or if the time the mounted objects were fetched isn't known.
+func SyncedFromNewerVersions(obj metav1.Object, spcPodStatus *secretsstorev1.SecretProviderClassPodStatus) bool {
+	annotations := obj.GetAnnotations()
+	recorded, ok := annotations[ObjectVersionsAnnotation]
+	if !ok {
+		return false
+	}
+	if mounted, err := objectVersions(spcPodStatus); err != nil || mounted == recorded {
+		return false
+	}
+	lastSyncTime, err := time.Parse(time.RFC3339, annotations[LastSyncTimeAnnotation])
+	if err != nil {
+		return false
+	}
+	fetchedAt := mountedObjectsFetchedAt(spcPodStatus)
+	if fetchedAt.IsZero() {
+		return false
+	}
+	// the sync time is truncated to seconds, so a fetch in the same second is considered as new
+	return fetchedAt.Before(lastSyncTime)
+}
+
+// mountedObjectsFetchedAt returns the time the objects mounted for the spc pod status were last
+// fetched. The fetch time is only recorded for objects with an expiry, so the time the contents
+// were last mounted or rotated is used as well. The zero time is returned if none is recorded.
+func mountedObjectsFetchedAt(spcPodStatus *secretsstorev1.SecretProviderClassPodStatus) time.Time {
+	var fetchedAt time.Time
+	latest := func(t *metav1.Time) {
+		if t != nil && t.After(fetchedAt) {
+			fetchedAt = t.Time
+		}
+	}
+	for _, obj := range spcPodStatus.Status.Objects {
+		latest(obj.FetchedAt)
+	}
+	latest(spcPodStatus.Status.LastRotationTime)
+	if mounted := meta.FindStatusCondition(spcPodStatus.Status.Conditions, secretsstorev1.ConditionTypeMounted); mounted != nil && mounted.Status == metav1.ConditionTrue {
+		latest(&mounted.LastTransitionTime)
+	}
+	return fetchedAt
+}
+
+// SecretAnnotationsChanged returns true if the desired annotations other than the last sync
+// time aren't set to the same value in the current annotations
+func SecretAnnotationsChanged(current, desired map[string]string) bool {
+	for k, v := range desired {
+		if k == LastSyncTimeAnnotation {
+			continue
+		}
+		if cv, ok := current[k]; !ok || cv != v {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretRetainPolicy returns the retain policy of the secret object, which defaults to Delete
+func SecretRetainPolicy(secretObj *secretsstorev1.SecretObject) secretsstorev1.RetainPolicy {
+	if secretObj.RetainPolicy == secretsstorev1.RetainPolicyRetain {
+		return secretsstorev1.RetainPolicyRetain
+	}
+	return secretsstorev1.RetainPolicyDelete
+}
+
+// SecretOwner returns the <kind>/<name> of the secret provider class referenced by the spc pod status
+func SecretOwner(spcPodStatus *secretsstorev1.SecretProviderClassPodStatus) string {
+	kind := spcPodStatus.Status.SecretProviderClassKind
+	if len(kind) == 0 {
+		kind = secretsstorev1.SecretProviderClassKind
+	}
+	return kind + "/" + spcPodStatus.Status.SecretProviderClassName
+}
+
+// SecretConflict returns a message describing the conflict if the secret isn't managed by the driver
+// or is owned by a secret provider class other than owner. An empty message is returned if there is
+// no conflict. Managed secrets without the owner annotation were synced before ownership was tracked
+// and don't conflict.
+func SecretConflict(secret *corev1.Secret, owner string) string {
+	return objectConflict("secret", secret, owner)
+}
+
+// ConfigMapConflict returns a message describing the conflict if the configmap isn't managed by the
+// driver or is owned by a secret provider class other than owner, in the same way as SecretConflict
+func ConfigMapConflict(configMap *corev1.ConfigMap, owner string) string {
+	return objectConflict("configmap", configMap, owner)
+}
+
+// objectConflict returns a message describing the conflict if the synced object of the kind isn't
+// managed by the driver or is owned by a secret provider class other than owner
+func objectConflict(kind string, obj metav1.Object, owner string) string {
+	if obj.GetLabels()[SecretManagedLabel] != "true" {
+		return fmt.Sprintf("%s %s/%s already exists and isn't managed by the driver", kind, obj.GetNamespace(), obj.GetName())
+	}
+	if current, ok := obj.GetAnnotations()[SecretOwnerAnnotation]; ok && current != owner {
+		return fmt.Sprintf("%s %s/%s is owned by %s", kind, obj.GetNamespace(), obj.GetName(), current)
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestSecretAnnotations(t *testing.T) {
+	g := NewWithT(t)
+
+	spcPodStatus := newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1")
+	spcPodStatus.Status.Objects = []secretsstorev1.SecretProviderClassObject{
+		{ID: "secret/obj2", Version: "v2"},
+		{ID: "secret/obj1", Version: "v1"},
+	}
+	syncTime := time.Date(2021, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	annotations, err := SecretAnnotations(&secretsstorev1.SecretObject{
+		Annotations: map[string]string{
+			"reloader": "true",
+			// provenance annotations take precedence over the user specified annotations
+			LastSyncTimeAnnotation: "user",
+		},
+	}, spcPodStatus, syncTime)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(annotations).To(Equal(map[string]string{
+		"reloader":               "true",
+		SecretOwnerAnnotation:    "SecretProviderClass/spc1",
+		ObjectVersionsAnnotation: `{"secret/obj1":"v1","secret/obj2":"v2"}`,
+		LastSyncTimeAnnotation:   "2021-04-01T10:00:00Z",
+		RetainPolicyAnnotation:   "Delete",
+	}))
+
+	spcPodStatus.Status.SecretProviderClassKind = secretsstorev1.ClusterSecretProviderClassKind
+	annotations, err = SecretAnnotations(&secretsstorev1.SecretObject{RetainPolicy: secretsstorev1.RetainPolicyRetain}, spcPodStatus, syncTime)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(annotations[SecretOwnerAnnotation]).To(Equal("ClusterSecretProviderClass/spc1"))
+	g.Expect(annotations[RetainPolicyAnnotation]).To(Equal("Retain"))
+}
+
+func TestSecretConflict(t *testing.T) {
+	g := NewWithT(t)
+
+	owner := "SecretProviderClass/spc1"
+
+	secret := newSecret("secret1", "default", nil)
+	g.Expect(SecretConflict(secret, owner)).To(Equal("secret default/secret1 already exists and isn't managed by the driver"))
+
+	// managed secret synced before ownership was tracked
+	secret = newSecret("secret1", "default", map[string]string{SecretManagedLabel: "true"})
+	g.Expect(SecretConflict(secret, owner)).To(BeEmpty())
+
+	secret.Annotations = map[string]string{SecretOwnerAnnotation: owner}
+	g.Expect(SecretConflict(secret, owner)).To(BeEmpty())
+
+	secret.Annotations = map[string]string{SecretOwnerAnnotation: "ClusterSecretProviderClass/cspc1"}
+	g.Expect(SecretConflict(secret, owner)).To(Equal("secret default/secret1 is owned by ClusterSecretProviderClass/cspc1"))
+}
+
+func TestSyncedFromNewerVersions(t *testing.T) {
+	g := NewWithT(t)
+
+	fetchedAt := metav1.NewTime(time.Date(2021, 4, 1, 10, 0, 0, 0, time.UTC))
+	spcPodStatus := newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1")
+	spcPodStatus.Status.Objects = []secretsstorev1.SecretProviderClassObject{
+		{ID: "secret/obj1", Version: "v1", FetchedAt: &fetchedAt},
+	}
+
+	// unmanaged secret
+	secret := newSecret("secret1", "default", nil)
+	g.Expect(SyncedFromNewerVersions(secret, spcPodStatus)).To(BeFalse())
+
+	// synced from the mounted versions, e.g. the secret was edited by hand
+	secret.Annotations = map[string]string{
+		ObjectVersionsAnnotation: `{"secret/obj1":"v1"}`,
+		LastSyncTimeAnnotation:   "2021-04-01T11:00:00Z",
+	}
+	g.Expect(SyncedFromNewerVersions(secret, spcPodStatus)).To(BeFalse())
+
+	// synced by another node after the objects were mounted
+	secret.Annotations[ObjectVersionsAnnotation] = `{"secret/obj1":"v2"}`
+	g.Expect(SyncedFromNewerVersions(secret, spcPodStatus)).To(BeTrue())
+
+	// synced by another node before the objects were mounted
+	secret.Annotations[LastSyncTimeAnnotation] = "2021-04-01T09:00:00Z"
+	g.Expect(SyncedFromNewerVersions(secret, spcPodStatus)).To(BeFalse())
+
+	// the fetch time of the mounted objects isn't known, so the mounted data is used
+	spcPodStatus.Status.Objects[0].FetchedAt = nil
+	secret.Annotations[LastSyncTimeAnnotation] = "2021-04-01T11:00:00Z"
+	g.Expect(SyncedFromNewerVersions(secret, spcPodStatus)).To(BeFalse())
+}
+
+func TestSyncedFromNewerVersionsWithoutExpiry(t *testing.T) {
+	g := NewWithT(t)
+
+	mountedAt := metav1.NewTime(time.Date(2021, 4, 1, 10, 0, 0, 0, time.UTC))
+	spcPodStatus := newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1")
+	// the fetch time isn't reported for objects without an expiry
+	spcPodStatus.Status.Objects = []secretsstorev1.SecretProviderClassObject{
+		{ID: "secret/obj1", Version: "v1"},
+	}
+	spcPodStatus.Status.Conditions = []metav1.Condition{
+		{
+			Type:               secretsstorev1.ConditionTypeMounted,
+			Status:             metav1.ConditionTrue,
+			Reason:             secretsstorev1.MountSucceededReason,
+			LastTransitionTime: mountedAt,
+		},
+	}
+	secret := newSecret("secret1", "default", nil)
+	secret.Annotations = map[string]string{
+		ObjectVersionsAnnotation: `{"secret/obj1":"v2"}`,
+		LastSyncTimeAnnotation:   "2021-04-01T11:00:00Z",
+	}
+
+	// synced by another node after the objects were mounted
+	g.Expect(SyncedFromNewerVersions(secret, spcPodStatus)).To(BeTrue())
+
+	// synced by another node before the objects were rotated
+	rotatedAt := metav1.NewTime(time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC))
+	spcPodStatus.Status.LastRotationTime = &rotatedAt
+	g.Expect(SyncedFromNewerVersions(secret, spcPodStatus)).To(BeFalse())
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
+	secretsstore "sigs.k8s.io/secrets-store-csi-driver/pkg/secrets-store"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/spcpsutil"
+)
+
+const (
+	// spcStatusResyncPeriod is the interval at which the spc status is reconciled
+	// to pick up changes in the provider socket presence. The status is only written
+	// if it changed.
+	spcStatusResyncPeriod = 5 * time.Minute
+	// byPodHeartbeatPeriod is the interval at which the byPod status entry is rewritten
+	// even if it hasn't changed, so it's not considered stale by other driver pods
+	byPodHeartbeatPeriod = 30 * time.Minute
+	// byPodStalePeriod is the duration after which a byPod status entry that hasn't
+	// been updated is considered stale. This happens when the driver pod that wrote
+	// the entry no longer exists.
+	byPodStalePeriod = 2 * time.Hour
+)
+
+// providerChecker checks if the provider socket is present on the node
+type providerChecker interface {
+	ProviderExists(provider string) bool
+}
+
+// SecretProviderClassStatusReconciler reconciles the status of SecretProviderClass objects.
+// Every driver pod maintains its own byPod entry in the status with the usage of the
+// SecretProviderClass on its node, and the aggregated counts and conditions are computed
+// from all the byPod entries.
+type SecretProviderClassStatusReconciler struct {
+	client.Client
+	reader          client.Reader
+	nodeID          string
+	podName         string
+	podNamespace    string
+	providerClients providerChecker
+	// minRotationInterval is the shortest rotation interval the rotation policy can set
+	minRotationInterval time.Duration
+	now                 func() time.Time
+}
+
+// NewSecretProviderClassStatusReconciler creates a new SecretProviderClassStatusReconciler.
+// podName and podNamespace identify the driver pod in the byPod status entries.
+func NewSecretProviderClassStatusReconciler(mgr manager.Manager, nodeID, podName, podNamespace string, providerClients *secretsstore.PluginClientBuilder, minRotationInterval time.Duration) *SecretProviderClassStatusReconciler {
+	return &SecretProviderClassStatusReconciler{
+		Client:              mgr.GetClient(),
+		reader:              mgr.GetCache(),
+		nodeID:              nodeID,
+		podName:             podName,
+		podNamespace:        podNamespace,
+		providerClients:     providerClients,
+		minRotationInterval: minRotationInterval,
+		now:                 time.Now,
+	}
+}
+
+// +kubebuilder:rbac:groups=secrets-store.csi.x-k8s.io,resources=secretproviderclasses/status,verbs=get;update;patch
+
+func (r *SecretProviderClassStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	klog.V(5).InfoS("reconcile started", "spc", req.NamespacedName.String())
+
+	spc := &secretsstorev1.SecretProviderClass{}
+	if err := r.reader.Get(ctx, req.NamespacedName, spc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "failed to get spc", "spc", req.NamespacedName.String())
+		return ctrl.Result{}, err
+	}
+
+	// the cache only contains the spc pod statuses that belong to this node
+	spcPodStatusList := &secretsstorev1.SecretProviderClassPodStatusList{}
+	if err := r.reader.List(ctx, spcPodStatusList, client.InNamespace(spc.Namespace), client.MatchingLabels{secretsstorev1.InternalNodeLabel: r.nodeID}); err != nil {
+		klog.ErrorS(err, "failed to list spc pod statuses", "spc", klog.KObj(spc))
+		return ctrl.Result{}, err
+	}
+
+	status := spc.Status.DeepCopy()
+	ops := r.byPodPatch(spc, status, r.nodeUsage(spc, spcPodStatusList.Items))
+	setAggregatedStatus(spc, status, r.minRotationInterval)
+	ops = append(ops, aggregatedStatusPatch(&spc.Status, status)...)
+
+	if len(ops) == 0 {
+		klog.V(5).InfoS("spc status is up to date", "spc", klog.KObj(spc))
+		return ctrl.Result{RequeueAfter: spcStatusResyncPeriod}, nil
+	}
+	if equality.Semantic.DeepEqual(spc.Status, secretsstorev1.SecretProviderClassStatus{}) {
+		// the status may not exist yet, so it's added as a whole if no other driver pod added it
+		ops = []jsonPatchOp{testResourceVersion(spc), {Op: "add", Path: "/status", Value: status}}
+	}
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		klog.ErrorS(err, "failed to marshal spc status patch", "spc", klog.KObj(spc))
+		return ctrl.Result{}, err
+	}
+	// the patch only changes the byPod entries of this driver pod and the stale ones, so the
+	// driver pods don't conflict with each other. If an entry it changes was changed by another
+	// driver pod in the meantime, the test operations fail the patch and the request is requeued.
+	if err := r.Status().Patch(ctx, spc, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsConflict(err) {
+			klog.V(5).InfoS("spc status changed while patching, requeuing", "spc", klog.KObj(spc), "err", err)
+		} else {
+			klog.ErrorS(err, "failed to patch spc status", "spc", klog.KObj(spc))
+		}
+		return ctrl.Result{}, err
+	}
+
+	klog.V(5).InfoS("reconcile complete", "spc", klog.KObj(spc))
+	return ctrl.Result{RequeueAfter: spcStatusResyncPeriod}, nil
+}
+
+// nodeUsage returns the usage of the spc on this node computed from the spc pod statuses
+func (r *SecretProviderClassStatusReconciler) nodeUsage(spc *secretsstorev1.SecretProviderClass, spcPodStatuses []secretsstorev1.SecretProviderClassPodStatus) *secretsstorev1.ByPodStatus {
+	usage := &secretsstorev1.ByPodStatus{
+		ID:        r.podName,
+		Namespace: r.podNamespace,
+		NodeName:  r.nodeID,
+	}
+	for i := range spcPodStatuses {
+		spcps := &spcPodStatuses[i]
+		if !referencesSecretProviderClass(spcps) || spcps.Status.SecretProviderClassName != spc.Name || !spcps.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		usage.PodCount++
+		if meta.IsStatusConditionFalse(spcps.Status.Conditions, secretsstorev1.ConditionTypeMounted) {
+			usage.MountErrors++
+		}
+		if meta.IsStatusConditionFalse(spcps.Status.Conditions, secretsstorev1.ConditionTypeRotationSucceeded) {
+			usage.RotationErrors++
+		}
+	}
+	if usage.PodCount > 0 {
+		usage.ProviderAvailable = r.providerClients.ProviderExists(string(spc.Spec.Provider))
+	}
+	return usage
+}
+
+// jsonPatchOp is an operation of a JSON patch (RFC 6902)
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// testResourceVersion returns the operation that fails the patch if the spc has been
+// updated since it was read
+func testResourceVersion(spc *secretsstorev1.SecretProviderClass) jsonPatchOp {
+	return jsonPatchOp{Op: "test", Path: "/metadata/resourceVersion", Value: spc.ResourceVersion}
+}
+
+// byPodPatch sets the byPod status entry of this driver pod and removes the stale entries
+// in the status, and returns the JSON patch operations that make the same changes to the
+// stored status. The entry of this driver pod is removed if no pods on the node mount the
+// spc, and is only rewritten if its usage changed or byPodHeartbeatPeriod elapsed.
+func (r *SecretProviderClassStatusReconciler) byPodPatch(spc *secretsstorev1.SecretProviderClass, status *secretsstorev1.SecretProviderClassStatus, usage *secretsstorev1.ByPodStatus) []jsonPatchOp {
+	now := metav1.NewTime(r.now())
+	byPods := append([]*secretsstorev1.ByPodStatus(nil), status.ByPod...)
+	found := false
+	var ops []jsonPatchOp
+	// every entry is tested before it's changed, so an entry rewritten by another driver pod
+	// isn't overwritten. The entries are visited in descending index order, so removing an
+	// entry doesn't shift the index of the entries changed after it.
+	for i := len(status.ByPod) - 1; i >= 0; i-- {
+		byPod := status.ByPod[i]
+		path := fmt.Sprintf("/status/byPod/%d", i)
+		switch {
+		case byPod != nil && byPod.ID == usage.ID && byPod.Namespace == usage.Namespace:
+			found = true
+			if usage.PodCount == 0 {
+				ops = append(ops, jsonPatchOp{Op: "test", Path: path, Value: byPod}, jsonPatchOp{Op: "remove", Path: path})
+				byPods = append(byPods[:i], byPods[i+1:]...)
+				continue
+			}
+			if !byPodChanged(byPod, usage, now.Time) {
+				continue
+			}
+			usage.LastUpdateTime = &now
+			ops = append(ops, jsonPatchOp{Op: "test", Path: path, Value: byPod}, jsonPatchOp{Op: "replace", Path: path, Value: usage})
+			byPods[i] = usage
+		case byPod == nil || byPod.LastUpdateTime == nil || now.Sub(byPod.LastUpdateTime.Time) > byPodStalePeriod:
+			ops = append(ops, jsonPatchOp{Op: "test", Path: path, Value: byPod}, jsonPatchOp{Op: "remove", Path: path})
+			byPods = append(byPods[:i], byPods[i+1:]...)
+		}
+	}
+
+	if !found && usage.PodCount > 0 {
+		usage.LastUpdateTime = &now
+		if len(status.ByPod) > 0 {
+			ops = append(ops, jsonPatchOp{Op: "add", Path: "/status/byPod/-", Value: usage})
+		} else {
+			// the byPod list may not exist yet, so it's added if no other driver pod added it
+			ops = append(ops, testResourceVersion(spc), jsonPatchOp{Op: "add", Path: "/status/byPod", Value: []*secretsstorev1.ByPodStatus{usage}})
+		}
+		byPods = append(byPods, usage)
+	}
+
+	status.ByPod = byPods
+	if len(status.ByPod) == 0 {
+		status.ByPod = nil
+	}
+	return ops
+}
+
+// byPodChanged returns true if the usage differs from the byPod entry, or if the entry
+// hasn't been rewritten for byPodHeartbeatPeriod
+func byPodChanged(byPod, usage *secretsstorev1.ByPodStatus, now time.Time) bool {
+	if byPod.LastUpdateTime == nil || now.Sub(byPod.LastUpdateTime.Time) >= byPodHeartbeatPeriod {
+		return true
+	}
+	existing := *byPod
+	existing.LastUpdateTime = usage.LastUpdateTime
+	return !equality.Semantic.DeepEqual(&existing, usage)
+}
+
+// aggregatedStatusPatch returns the JSON patch operations that set the counts and
+// conditions that changed in the status
+func aggregatedStatusPatch(current, status *secretsstorev1.SecretProviderClassStatus) []jsonPatchOp {
+	var ops []jsonPatchOp
+	if current.PodCount != status.PodCount {
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/status/podCount", Value: status.PodCount})
+	}
+	if current.NodeCount != status.NodeCount {
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/status/nodeCount", Value: status.NodeCount})
+	}
+	if !equality.Semantic.DeepEqual(current.Conditions, status.Conditions) {
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/status/conditions", Value: status.Conditions})
+	}
+	return ops
+}
+
+// setAggregatedStatus sets the counts and conditions in the status based on the byPod entries
+func setAggregatedStatus(spc *secretsstorev1.SecretProviderClass, status *secretsstorev1.SecretProviderClassStatus, minRotationInterval time.Duration) {
+	var podCount, mountErrors, rotationErrors int32
+	nodes := make(map[string]struct{})
+	providerNotFoundNodes := make(map[string]struct{})
+	for _, byPod := range status.ByPod {
+		podCount += byPod.PodCount
+		mountErrors += byPod.MountErrors
+		rotationErrors += byPod.RotationErrors
+		nodes[byPod.NodeName] = struct{}{}
+		if !byPod.ProviderAvailable {
+			providerNotFoundNodes[byPod.NodeName] = struct{}{}
+		}
+	}
+	status.PodCount = podCount
+	status.NodeCount = int32(len(nodes))
+
+	if err := secretsstore.ValidateSecretProviderClass(spc, minRotationInterval); err != nil {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeValid, metav1.ConditionFalse, internalerrors.InvalidSecretProviderClass, err.Error())
+	} else {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeValid, metav1.ConditionTrue, secretsstorev1.ValidationSucceededReason, "")
+	}
+
+	if podCount == 0 {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeInUse, metav1.ConditionFalse, secretsstorev1.NotInUseReason, "")
+		for _, conditionType := range []string{secretsstorev1.ConditionTypeProviderAvailable, secretsstorev1.ConditionTypeMountsHealthy, secretsstorev1.ConditionTypeRotationsHealthy} {
+			setSPCCondition(spc, status, conditionType, metav1.ConditionUnknown, secretsstorev1.NotInUseReason, "")
+		}
+		return
+	}
+
+	setSPCCondition(spc, status, secretsstorev1.ConditionTypeInUse, metav1.ConditionTrue, secretsstorev1.PodsMountedReason,
+		fmt.Sprintf("used by %d pods on %d nodes", podCount, len(nodes)))
+
+	if len(providerNotFoundNodes) > 0 {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeProviderAvailable, metav1.ConditionFalse, internalerrors.ProviderNotFound,
+			fmt.Sprintf("provider %s not found on nodes: %s", spc.Spec.Provider, strings.Join(sortedKeys(providerNotFoundNodes), ", ")))
+	} else {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeProviderAvailable, metav1.ConditionTrue, secretsstorev1.ProviderFoundReason, "")
+	}
+
+	if mountErrors > 0 {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeMountsHealthy, metav1.ConditionFalse, internalerrors.MountsFailed,
+			fmt.Sprintf("%d of %d pods failed to mount", mountErrors, podCount))
+	} else {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeMountsHealthy, metav1.ConditionTrue, secretsstorev1.MountsSucceededReason, "")
+	}
+
+	if rotationErrors > 0 {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeRotationsHealthy, metav1.ConditionFalse, internalerrors.RotationsFailed,
+			fmt.Sprintf("%d of %d pods failed to rotate", rotationErrors, podCount))
+	} else {
+		setSPCCondition(spc, status, secretsstorev1.ConditionTypeRotationsHealthy, metav1.ConditionTrue, secretsstorev1.RotationsSucceededReason, "")
+	}
+}
+
+// setSPCCondition sets the condition with the redacted message in the spc status.
+// The last transition time is only updated when the condition status changes.
+func setSPCCondition(spc *secretsstorev1.SecretProviderClass, status *secretsstorev1.SecretProviderClassStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	message = spcpsutil.RedactMessage(message)
+	if c := meta.FindStatusCondition(status.Conditions, conditionType); c != nil &&
+		c.Status == conditionStatus && c.Reason == reason && c.Message == message && c.ObservedGeneration == spc.Generation {
+		return
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: spc.Generation,
+	})
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (r *SecretProviderClassStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// status updates don't change the generation, so the status writes by
+		// the driver pods don't trigger a reconcile
+		For(&secretsstorev1.SecretProviderClass{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &secretsstorev1.SecretProviderClassPodStatus{}}, handler.EnqueueRequestsFromMapFunc(spcPodStatusToSPC)).
+		Complete(r)
+}
+
+// spcPodStatusToSPC maps the spc pod status to the reconcile request for the spc it references
+func spcPodStatusToSPC(obj client.Object) []reconcile.Request {
+	spcps, ok := obj.(*secretsstorev1.SecretProviderClassPodStatus)
+	if !ok || !referencesSecretProviderClass(spcps) || spcps.Status.SecretProviderClassName == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: spcps.Namespace, Name: spcps.Status.SecretProviderClassName}},
+	}
+}
+
+// referencesSecretProviderClass returns true if the spc pod status references a namespaced
+// secret provider class rather than a cluster secret provider class
+func referencesSecretProviderClass(spcps *secretsstorev1.SecretProviderClassPodStatus) bool {
+	return spcps.Status.SecretProviderClassKind == "" || spcps.Status.SecretProviderClassKind == secretsstorev1.SecretProviderClassKind
+}
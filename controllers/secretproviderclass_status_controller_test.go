@@ -0,0 +1,347 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+type fakeProviderChecker map[string]bool
+
+func (f fakeProviderChecker) ProviderExists(provider string) bool {
+	return f[provider]
+}
+
+func newSPCWithStatus(byPod ...*secretsstorev1.ByPodStatus) *secretsstorev1.SecretProviderClass {
+	return &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "spc1",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: secretsstorev1.SecretProviderClassSpec{
+			Provider:   "provider1",
+			Parameters: map[string]string{"foo": "bar"},
+		},
+		Status: secretsstorev1.SecretProviderClassStatus{
+			ByPod: byPod,
+		},
+	}
+}
+
+func newSPCPSWithCondition(name, node string, conditions ...metav1.Condition) *secretsstorev1.SecretProviderClassPodStatus {
+	spcps := newSecretProviderClassPodStatus(name, "default", node)
+	spcps.Status.Conditions = conditions
+	return spcps
+}
+
+func TestSecretProviderClassStatusReconcile(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := metav1.NewTime(now.Add(-time.Minute))
+	stale := metav1.NewTime(now.Add(-3 * time.Hour))
+
+	tests := []struct {
+		name                      string
+		spc                       *secretsstorev1.SecretProviderClass
+		spcps                     []*secretsstorev1.SecretProviderClassPodStatus
+		providers                 fakeProviderChecker
+		expectedByPod             int
+		expectedPodCount          int32
+		expectedNodeCount         int32
+		expectedConditions        map[string]metav1.ConditionStatus
+		expectedProviderNotFound  string
+		expectedLastUpdateTimeNow bool
+	}{
+		{
+			name: "pods on node with failed mount",
+			spc:  newSPCWithStatus(),
+			spcps: []*secretsstorev1.SecretProviderClassPodStatus{
+				newSPCPSWithCondition("spcps1", "node1"),
+				newSPCPSWithCondition("spcps2", "node1", metav1.Condition{Type: secretsstorev1.ConditionTypeMounted, Status: metav1.ConditionFalse, Reason: "FailedToMount"}),
+				newSPCPSWithCondition("spcps3", "node2"),
+			},
+			providers:         fakeProviderChecker{"provider1": true},
+			expectedByPod:     1,
+			expectedPodCount:  2,
+			expectedNodeCount: 1,
+			expectedConditions: map[string]metav1.ConditionStatus{
+				secretsstorev1.ConditionTypeValid:             metav1.ConditionTrue,
+				secretsstorev1.ConditionTypeInUse:             metav1.ConditionTrue,
+				secretsstorev1.ConditionTypeProviderAvailable: metav1.ConditionTrue,
+				secretsstorev1.ConditionTypeMountsHealthy:     metav1.ConditionFalse,
+				secretsstorev1.ConditionTypeRotationsHealthy:  metav1.ConditionTrue,
+			},
+			expectedLastUpdateTimeNow: true,
+		},
+		{
+			name: "provider not found on other node",
+			spc: newSPCWithStatus(&secretsstorev1.ByPodStatus{
+				ID: "driver2", Namespace: "kube-system", NodeName: "node2", PodCount: 3, LastUpdateTime: &recent,
+			}),
+			spcps:             []*secretsstorev1.SecretProviderClassPodStatus{newSPCPSWithCondition("spcps1", "node1")},
+			providers:         fakeProviderChecker{"provider1": true},
+			expectedByPod:     2,
+			expectedPodCount:  4,
+			expectedNodeCount: 2,
+			expectedConditions: map[string]metav1.ConditionStatus{
+				secretsstorev1.ConditionTypeInUse:             metav1.ConditionTrue,
+				secretsstorev1.ConditionTypeProviderAvailable: metav1.ConditionFalse,
+			},
+			expectedProviderNotFound:  "provider provider1 not found on nodes: node2",
+			expectedLastUpdateTimeNow: true,
+		},
+		{
+			name: "stale entry is pruned and own entry is removed when not in use",
+			spc: newSPCWithStatus(
+				&secretsstorev1.ByPodStatus{ID: "driver1", Namespace: "kube-system", NodeName: "node1", PodCount: 1, ProviderAvailable: true, LastUpdateTime: &recent},
+				&secretsstorev1.ByPodStatus{ID: "driver2", Namespace: "kube-system", NodeName: "node2", PodCount: 1, ProviderAvailable: true, LastUpdateTime: &stale},
+			),
+			providers:         fakeProviderChecker{"provider1": true},
+			expectedByPod:     0,
+			expectedPodCount:  0,
+			expectedNodeCount: 0,
+			expectedConditions: map[string]metav1.ConditionStatus{
+				secretsstorev1.ConditionTypeInUse:             metav1.ConditionFalse,
+				secretsstorev1.ConditionTypeProviderAvailable: metav1.ConditionUnknown,
+				secretsstorev1.ConditionTypeMountsHealthy:     metav1.ConditionUnknown,
+			},
+		},
+		{
+			name: "unchanged own entry keeps last update time",
+			spc: newSPCWithStatus(
+				&secretsstorev1.ByPodStatus{ID: "driver1", Namespace: "kube-system", NodeName: "node1", PodCount: 1, ProviderAvailable: true, LastUpdateTime: &recent},
+			),
+			spcps:             []*secretsstorev1.SecretProviderClassPodStatus{newSPCPSWithCondition("spcps1", "node1")},
+			providers:         fakeProviderChecker{"provider1": true},
+			expectedByPod:     1,
+			expectedPodCount:  1,
+			expectedNodeCount: 1,
+			expectedConditions: map[string]metav1.ConditionStatus{
+				secretsstorev1.ConditionTypeProviderAvailable: metav1.ConditionTrue,
+			},
+		},
+		{
+			name:              "invalid spc",
+			spc:               &secretsstorev1.SecretProviderClass{ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"}},
+			providers:         fakeProviderChecker{},
+			expectedByPod:     0,
+			expectedPodCount:  0,
+			expectedNodeCount: 0,
+			expectedConditions: map[string]metav1.ConditionStatus{
+				secretsstorev1.ConditionTypeValid: metav1.ConditionFalse,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme, err := setupScheme()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			initObjects := []runtime.Object{test.spc}
+			for _, spcps := range test.spcps {
+				initObjects = append(initObjects, spcps)
+			}
+			c := fake.NewFakeClientWithScheme(scheme, initObjects...)
+			r := &SecretProviderClassStatusReconciler{
+				Client:          c,
+				reader:          c,
+				nodeID:          "node1",
+				podName:         "driver1",
+				podNamespace:    "kube-system",
+				providerClients: test.providers,
+				now:             func() time.Time { return now },
+			}
+
+			key := types.NamespacedName{Namespace: "default", Name: "spc1"}
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.RequeueAfter).To(Equal(spcStatusResyncPeriod))
+
+			spc := &secretsstorev1.SecretProviderClass{}
+			g.Expect(c.Get(context.TODO(), key, spc)).To(Succeed())
+			g.Expect(spc.Status.ByPod).To(HaveLen(test.expectedByPod))
+			g.Expect(spc.Status.PodCount).To(Equal(test.expectedPodCount))
+			g.Expect(spc.Status.NodeCount).To(Equal(test.expectedNodeCount))
+			for conditionType, status := range test.expectedConditions {
+				cond := meta.FindStatusCondition(spc.Status.Conditions, conditionType)
+				g.Expect(cond).NotTo(BeNil(), conditionType)
+				g.Expect(cond.Status).To(Equal(status), conditionType)
+			}
+			if test.expectedProviderNotFound != "" {
+				cond := meta.FindStatusCondition(spc.Status.Conditions, secretsstorev1.ConditionTypeProviderAvailable)
+				g.Expect(cond.Message).To(Equal(test.expectedProviderNotFound))
+			}
+			for _, byPod := range spc.Status.ByPod {
+				if byPod.ID != "driver1" {
+					continue
+				}
+				if test.expectedLastUpdateTimeNow {
+					g.Expect(byPod.LastUpdateTime.Time.Equal(now)).To(BeTrue())
+				} else {
+					g.Expect(byPod.LastUpdateTime.Time.Equal(recent.Time)).To(BeTrue())
+				}
+			}
+		})
+	}
+}
+
+func TestSecretProviderClassStatusReconcileUnchanged(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := fake.NewFakeClientWithScheme(scheme, newSPCWithStatus(), newSPCPSWithCondition("spcps1", "node1"))
+	r := &SecretProviderClassStatusReconciler{
+		Client:          c,
+		reader:          c,
+		nodeID:          "node1",
+		podName:         "driver1",
+		podNamespace:    "kube-system",
+		providerClients: fakeProviderChecker{"provider1": true},
+		now:             func() time.Time { return now },
+	}
+
+	key := types.NamespacedName{Namespace: "default", Name: "spc1"}
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	spc := &secretsstorev1.SecretProviderClass{}
+	g.Expect(c.Get(context.TODO(), key, spc)).To(Succeed())
+	g.Expect(spc.Status.ByPod).To(HaveLen(1))
+	resourceVersion := spc.ResourceVersion
+
+	// the status isn't written again until the heartbeat period elapses
+	now = now.Add(byPodHeartbeatPeriod - time.Minute)
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get(context.TODO(), key, spc)).To(Succeed())
+	g.Expect(spc.ResourceVersion).To(Equal(resourceVersion))
+
+	now = now.Add(time.Minute)
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get(context.TODO(), key, spc)).To(Succeed())
+	g.Expect(spc.ResourceVersion).NotTo(Equal(resourceVersion))
+	g.Expect(spc.Status.ByPod[0].LastUpdateTime.Time.Equal(now)).To(BeTrue())
+}
+
+func TestSecretProviderClassStatusReconcileConcurrentEntry(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := metav1.NewTime(now.Add(-time.Minute))
+	spc := newSPCWithStatus(&secretsstorev1.ByPodStatus{ID: "driver1", Namespace: "kube-system", NodeName: "node1", PodCount: 2, LastUpdateTime: &recent})
+	c := fake.NewFakeClientWithScheme(scheme, spc, newSPCPSWithCondition("spcps1", "node1"))
+	key := types.NamespacedName{Namespace: "default", Name: "spc1"}
+	cached := &secretsstorev1.SecretProviderClass{}
+	g.Expect(c.Get(context.TODO(), key, cached)).To(Succeed())
+
+	// another driver pod adds its entry after the spc was read by this driver pod
+	g.Expect(c.Get(context.TODO(), key, spc)).To(Succeed())
+	spc.Status.ByPod = append([]*secretsstorev1.ByPodStatus{{ID: "driver2", Namespace: "kube-system", NodeName: "node2", PodCount: 1, LastUpdateTime: &recent}}, spc.Status.ByPod...)
+	g.Expect(c.Status().Update(context.TODO(), spc)).To(Succeed())
+
+	r := &SecretProviderClassStatusReconciler{
+		Client:          c,
+		reader:          &staticReader{Client: c, spc: cached},
+		nodeID:          "node1",
+		podName:         "driver1",
+		podNamespace:    "kube-system",
+		providerClients: fakeProviderChecker{"provider1": true},
+		now:             func() time.Time { return now },
+	}
+	// the own entry moved, so the patch fails instead of overwriting the new entry
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(c.Get(context.TODO(), key, spc)).To(Succeed())
+	g.Expect(spc.Status.ByPod).To(HaveLen(2))
+	g.Expect(spc.Status.ByPod[0].ID).To(Equal("driver2"))
+	g.Expect(spc.Status.ByPod[1].PodCount).To(Equal(int32(2)))
+
+	r.reader = c
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get(context.TODO(), key, spc)).To(Succeed())
+	g.Expect(spc.Status.ByPod).To(HaveLen(2))
+	g.Expect(spc.Status.ByPod[0].ID).To(Equal("driver2"))
+	g.Expect(spc.Status.ByPod[1].PodCount).To(Equal(int32(1)))
+	g.Expect(spc.Status.PodCount).To(Equal(int32(2)))
+}
+
+// staticReader returns a stale copy of the spc, like a cache that hasn't
+// observed the latest update yet
+type staticReader struct {
+	client.Client
+	spc *secretsstorev1.SecretProviderClass
+}
+
+func (s *staticReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if spc, ok := obj.(*secretsstorev1.SecretProviderClass); ok {
+		s.spc.DeepCopyInto(spc)
+		return nil
+	}
+	return s.Client.Get(ctx, key, obj)
+}
+
+func TestSecretProviderClassStatusReconcileNotFound(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	c := fake.NewFakeClientWithScheme(scheme)
+	r := &SecretProviderClassStatusReconciler{
+		Client:          c,
+		reader:          c,
+		nodeID:          "node1",
+		providerClients: fakeProviderChecker{},
+		now:             time.Now,
+	}
+
+	result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "spc1"}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(ctrl.Result{}))
+}
+
+func TestSPCPodStatusToSPC(t *testing.T) {
+	g := NewWithT(t)
+
+	requests := spcPodStatusToSPC(newSecretProviderClassPodStatus("spcps1", "default", "node1"))
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].NamespacedName).To(Equal(types.NamespacedName{Namespace: "default", Name: "spc1"}))
+}
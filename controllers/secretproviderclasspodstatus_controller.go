@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -33,11 +34,13 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned/scheme"
+	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/fileutil"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/secretutil"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/spcpsutil"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -49,6 +52,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
@@ -57,6 +61,15 @@ const (
 	SecretManagedLabel         = "secrets-store.csi.k8s.io/managed"
 	SecretUsedLabel            = "secrets-store.csi.k8s.io/used"
 	secretCreationFailedReason = "FailedToCreateSecret"
+
+	secretDriftCorrectedReason        = "SecretDriftCorrected"
+	secretDriftCorrectionFailedReason = "FailedToCorrectSecretDrift"
+	secretConflictReason              = "SecretConflict"
+
+	configMapCreationFailedReason = "FailedToCreateConfigMap"
+
+	configMapDriftCorrectedReason        = "ConfigMapDriftCorrected"
+	configMapDriftCorrectionFailedReason = "FailedToCorrectConfigMapDrift"
 )
 
 // SecretProviderClassPodStatusReconciler reconciles a SecretProviderClassPodStatus object
@@ -66,8 +79,10 @@ type SecretProviderClassPodStatusReconciler struct {
 	scheme        *apiruntime.Scheme
 	nodeID        string
 	reader        client.Reader
+	apiReader     client.Reader
 	writer        client.Writer
 	eventRecorder record.EventRecorder
+	reporter      StatsReporter
 }
 
 // New creates a new SecretProviderClassPodStatusReconciler
@@ -83,8 +98,10 @@ func New(mgr manager.Manager, nodeID string) (*SecretProviderClassPodStatusRecon
 		scheme:        mgr.GetScheme(),
 		nodeID:        nodeID,
 		reader:        mgr.GetCache(),
+		apiReader:     mgr.GetAPIReader(),
 		writer:        mgr.GetClient(),
 		eventRecorder: recorder,
+		reporter:      newStatsReporter(),
 	}, nil
 }
 
@@ -109,9 +126,15 @@ func (r *SecretProviderClassPodStatusReconciler) Patcher(ctx context.Context) er
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	spcPodStatusList := &v1alpha1.SecretProviderClassPodStatusList{}
-	spcMap := make(map[string]v1alpha1.SecretProviderClass)
-	secretOwnerMap := make(map[types.NamespacedName][]metav1.OwnerReference)
+	spcPodStatusList := &secretsstorev1.SecretProviderClassPodStatusList{}
+	spcMap := make(map[string]secretsstorev1.SecretProviderClass)
+	// the owner references for the secrets and configmaps are grouped by the secret provider
+	// class, so an object is only owned by the pods using the secret provider class that owns it
+	secretOwnerMap := make(map[types.NamespacedName]map[string][]metav1.OwnerReference)
+	configMapOwnerMap := make(map[types.NamespacedName]map[string][]metav1.OwnerReference)
+	// the secret provider classes used on the node and the owner references of the pods
+	// using them, keyed by <namespace>/<owner> of the synced secrets
+	usedSPCMap := make(map[string]*usedSecretProviderClass)
 	// get a list of all spc pod status that belong to the node
 	err := r.reader.List(ctx, spcPodStatusList, r.ListOptionsLabelSelector())
 	if err != nil {
@@ -121,16 +144,17 @@ func (r *SecretProviderClassPodStatusReconciler) Patcher(ctx context.Context) er
 	spcPodStatuses := spcPodStatusList.Items
 	for i := range spcPodStatuses {
 		spcName := spcPodStatuses[i].Status.SecretProviderClassName
-		spc := &v1alpha1.SecretProviderClass{}
+		spc := &secretsstorev1.SecretProviderClass{}
 		namespace := spcPodStatuses[i].Namespace
+		spcKey := spcPodStatuses[i].Status.SecretProviderClassKind + "/" + namespace + "/" + spcName
 
-		if val, exists := spcMap[namespace+"/"+spcName]; exists {
+		if val, exists := spcMap[spcKey]; exists {
 			spc = &val
 		} else {
-			if err := r.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: spcName}, spc); err != nil {
+			if spc, err = r.getSecretProviderClass(ctx, r.reader, &spcPodStatuses[i]); err != nil {
 				return fmt.Errorf("failed to get spc %s, err: %+v", spcName, err)
 			}
-			spcMap[namespace+"/"+spcName] = *spc
+			spcMap[spcKey] = *spc
 		}
 		// get the pod and check if the pod has a owner reference
 		pod := &v1.Pod{}
@@ -165,18 +189,41 @@ func (r *SecretProviderClassPodStatusReconciler) Patcher(ctx context.Context) er
 			ownerRefs = append(ownerRefs, ref)
 		}
 
+		owner := SecretOwner(&spcPodStatuses[i])
+		usedKey := namespace + "/" + owner
+		if _, exists := usedSPCMap[usedKey]; !exists {
+			usedSPCMap[usedKey] = &usedSecretProviderClass{spc: spc, spcPodStatus: &spcPodStatuses[i]}
+		}
+		usedSPCMap[usedKey].ownerRefs = append(usedSPCMap[usedKey].ownerRefs, ownerRefs...)
 		for _, secret := range spc.Spec.SecretObjects {
+			// secrets with the Retain policy don't get owner references, so they
+			// aren't garbage collected when the last pod using them is deleted
+			if SecretRetainPolicy(secret) == secretsstorev1.RetainPolicyRetain {
+				continue
+			}
 			key := types.NamespacedName{Name: secret.SecretName, Namespace: namespace}
-			val, exists := secretOwnerMap[key]
-			if exists {
-				secretOwnerMap[key] = append(val, ownerRefs...)
-			} else {
-				secretOwnerMap[key] = ownerRefs
+			if _, exists := secretOwnerMap[key]; !exists {
+				secretOwnerMap[key] = make(map[string][]metav1.OwnerReference)
+			}
+			secretOwnerMap[key][owner] = append(secretOwnerMap[key][owner], ownerRefs...)
+		}
+		for _, configMap := range spc.Spec.ConfigMapObjects {
+			key := types.NamespacedName{Name: configMap.ConfigMapName, Namespace: namespace}
+			if _, exists := configMapOwnerMap[key]; !exists {
+				configMapOwnerMap[key] = make(map[string][]metav1.OwnerReference)
 			}
+			configMapOwnerMap[key][owner] = append(configMapOwnerMap[key][owner], ownerRefs...)
 		}
 	}
 
-	for secret, owners := range secretOwnerMap {
+	for secret, ownersBySPC := range secretOwnerMap {
+		var owners []metav1.OwnerReference
+		if owners, err = r.secretOwnerRefs(ctx, secret, ownersBySPC); err != nil {
+			return err
+		}
+		if len(owners) == 0 {
+			continue
+		}
 		patchFn := func() (bool, error) {
 			if err := r.patchSecretWithOwnerRef(ctx, secret.Name, secret.Namespace, owners...); err != nil {
 				if !apierrors.IsConflict(err) || !apierrors.IsTimeout(err) {
@@ -196,20 +243,178 @@ func (r *SecretProviderClassPodStatusReconciler) Patcher(ctx context.Context) er
 		}
 	}
 
+	for configMap, ownersBySPC := range configMapOwnerMap {
+		var owners []metav1.OwnerReference
+		if owners, err = r.configMapOwnerRefs(ctx, configMap, ownersBySPC); err != nil {
+			return err
+		}
+		if len(owners) == 0 {
+			continue
+		}
+		patchFn := func() (bool, error) {
+			if err := r.patchConfigMapWithOwnerRef(ctx, configMap.Name, configMap.Namespace, owners...); err != nil {
+				if !apierrors.IsConflict(err) && !apierrors.IsTimeout(err) {
+					klog.ErrorS(err, "failed to set owner ref for configmap", "configmap", klog.ObjectRef{Namespace: configMap.Namespace, Name: configMap.Name})
+				}
+				return false, nil
+			}
+			return true, nil
+		}
+		if err := wait.ExponentialBackoff(wait.Backoff{
+			Steps:    5,
+			Duration: 1 * time.Millisecond,
+			Factor:   1.0,
+			Jitter:   0.1,
+		}, patchFn); err != nil {
+			return err
+		}
+	}
+
+	if err = r.garbageCollectSecrets(ctx, usedSPCMap); err != nil {
+		return err
+	}
+
 	klog.V(5).Infof("patcher completed")
 	return nil
 }
 
+// usedSecretProviderClass is a secret provider class used on the node with one of the spc pod
+// statuses referencing it and the owner references of the pods using it.
+type usedSecretProviderClass struct {
+	spc          *secretsstorev1.SecretProviderClass
+	spcPodStatus *secretsstorev1.SecretProviderClassPodStatus
+	ownerRefs    []metav1.OwnerReference
+}
+
+// garbageCollectSecrets handles the managed secrets owned by the secret provider classes used on the node
+// that are removed from the secret objects or have the Retain policy. Removed secrets with the Delete policy
+// are deleted. The owner references of the pods on the node are removed from secrets with the Retain policy,
+// so the secrets are kept after the last pod using them is deleted.
+func (r *SecretProviderClassPodStatusReconciler) garbageCollectSecrets(ctx context.Context, usedSPCMap map[string]*usedSecretProviderClass) error {
+	secretList := &corev1.SecretList{}
+	if err := r.Client.List(ctx, secretList, client.MatchingLabels{SecretManagedLabel: "true"}); err != nil {
+		return fmt.Errorf("failed to list managed secrets, err: %+v", err)
+	}
+
+	var errs []error
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		// secrets synced before ownership was tracked aren't garbage collected
+		owner, ok := secret.Annotations[SecretOwnerAnnotation]
+		if !ok {
+			continue
+		}
+		used, ok := usedSPCMap[secret.Namespace+"/"+owner]
+		if !ok {
+			continue
+		}
+
+		declared, retainPolicy := secretObjectPolicy(used.spc, secret)
+		var err error
+		switch {
+		case retainPolicy == secretsstorev1.RetainPolicyRetain:
+			err = r.removeSecretOwnerRefs(ctx, secret, used.ownerRefs)
+		case !declared:
+			// the cached secret provider class can be older than the secret that's synced from
+			// it, so the secret is only deleted if the latest secret provider class doesn't
+			// declare it either
+			var spc *secretsstorev1.SecretProviderClass
+			if spc, err = r.getSecretProviderClass(ctx, r.apiReader, used.spcPodStatus); err != nil {
+				err = fmt.Errorf("failed to get spc %s, err: %+v", used.spcPodStatus.Status.SecretProviderClassName, err)
+				break
+			}
+			if declared, _ = secretObjectPolicy(spc, secret); declared {
+				klog.V(5).InfoS("secret is declared in the latest secret provider class, skipping delete", "secret", klog.KObj(secret))
+				break
+			}
+			err = r.deleteK8sSecret(ctx, secret)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// secretObjectPolicy returns if the secret is declared in the secret objects of the secret provider
+// class and its retain policy. The retain policy of a secret that isn't declared is the policy it
+// was synced with.
+func secretObjectPolicy(spc *secretsstorev1.SecretProviderClass, secret *corev1.Secret) (bool, secretsstorev1.RetainPolicy) {
+	for _, secretObj := range spc.Spec.SecretObjects {
+		if strings.TrimSpace(secretObj.SecretName) == secret.Name {
+			return true, SecretRetainPolicy(secretObj)
+		}
+	}
+	return false, secretsstorev1.RetainPolicy(secret.Annotations[RetainPolicyAnnotation])
+}
+
+// secretOwnerRefs returns the owner references to add to the secret
+func (r *SecretProviderClassPodStatusReconciler) secretOwnerRefs(ctx context.Context, key types.NamespacedName, ownersBySPC map[string][]metav1.OwnerReference) ([]metav1.OwnerReference, error) {
+	return r.ownerRefs(ctx, &corev1.Secret{}, key, ownersBySPC)
+}
+
+// configMapOwnerRefs returns the owner references to add to the configmap
+func (r *SecretProviderClassPodStatusReconciler) configMapOwnerRefs(ctx context.Context, key types.NamespacedName, ownersBySPC map[string][]metav1.OwnerReference) ([]metav1.OwnerReference, error) {
+	return r.ownerRefs(ctx, &corev1.ConfigMap{}, key, ownersBySPC)
+}
+
+// ownerRefs returns the owner references to add to the object with key. If the object is owned
+// by a secret provider class, only the owner references of the pods using that secret provider
+// class are returned. Objects that are synced before ownership was tracked get all the owner
+// references. Objects that aren't in the cache aren't managed by the driver and get none.
+func (r *SecretProviderClassPodStatusReconciler) ownerRefs(ctx context.Context, obj client.Object, key types.NamespacedName, ownersBySPC map[string][]metav1.OwnerReference) ([]metav1.OwnerReference, error) {
+	if err := r.Client.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if owner, ok := obj.GetAnnotations()[SecretOwnerAnnotation]; ok {
+		return ownersBySPC[owner], nil
+	}
+	var ownerRefs []metav1.OwnerReference
+	for _, refs := range ownersBySPC {
+		ownerRefs = append(ownerRefs, refs...)
+	}
+	return ownerRefs, nil
+}
+
+// getSecretProviderClass returns the secret provider class referenced by the spc pod status from
+// the reader. For a cluster secret provider class, the spc pod status namespace must be selected
+// by the namespace selector.
+func (r *SecretProviderClassPodStatusReconciler) getSecretProviderClass(ctx context.Context, reader client.Reader, spcPodStatus *secretsstorev1.SecretProviderClassPodStatus) (*secretsstorev1.SecretProviderClass, error) {
+	name := spcPodStatus.Status.SecretProviderClassName
+	if spcPodStatus.Status.SecretProviderClassKind != secretsstorev1.ClusterSecretProviderClassKind {
+		spc := &secretsstorev1.SecretProviderClass{}
+		if err := reader.Get(ctx, client.ObjectKey{Namespace: spcPodStatus.Namespace, Name: name}, spc); err != nil {
+			return nil, err
+		}
+		return spc, nil
+	}
+
+	cspc := &secretsstorev1.ClusterSecretProviderClass{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: name}, cspc); err != nil {
+		return nil, err
+	}
+	namespace := &corev1.Namespace{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: spcPodStatus.Namespace}, namespace); err != nil {
+		return nil, err
+	}
+	return k8sutil.SecretProviderClassForNamespace(cspc, namespace)
+}
+
 // ListOptionsLabelSelector returns a ListOptions with a label selector for node name.
 func (r *SecretProviderClassPodStatusReconciler) ListOptionsLabelSelector() client.ListOption {
 	return client.MatchingLabels(map[string]string{
-		v1alpha1.InternalNodeLabel: r.nodeID,
+		secretsstorev1.InternalNodeLabel: r.nodeID,
 	})
 }
 
 // +kubebuilder:rbac:groups=secrets-store.csi.x-k8s.io,resources=secretproviderclasspodstatuses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=secrets-store.csi.x-k8s.io,resources=secretproviderclasspodstatuses/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=secrets-store.csi.x-k8s.io,resources=secretproviderclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=secrets-store.csi.x-k8s.io,resources=clustersecretproviderclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
@@ -219,7 +424,7 @@ func (r *SecretProviderClassPodStatusReconciler) Reconcile(ctx context.Context,
 
 	klog.InfoS("reconcile started", "spcps", req.NamespacedName.String())
 
-	spcPodStatus := &v1alpha1.SecretProviderClassPodStatus{}
+	spcPodStatus := &secretsstorev1.SecretProviderClassPodStatus{}
 	if err := r.reader.Get(ctx, req.NamespacedName, spcPodStatus); err != nil {
 		if apierrors.IsNotFound(err) {
 			klog.InfoS("reconcile complete", "spcps", req.NamespacedName.String())
@@ -248,104 +453,376 @@ func (r *SecretProviderClassPodStatusReconciler) Reconcile(ctx context.Context,
 	}
 
 	spcName := spcPodStatus.Status.SecretProviderClassName
-	spc := &v1alpha1.SecretProviderClass{}
-	if err := r.reader.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: spcName}, spc); err != nil {
+	spc, err := r.getSecretProviderClass(ctx, r.reader, spcPodStatus)
+	if err != nil {
 		klog.ErrorS(err, "failed to get spc", "spc", spcName)
+		if errors.Is(err, k8sutil.ErrNamespaceNotAllowed) {
+			r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.NamespaceNotAllowed, err.Error())
+			return ctrl.Result{}, nil
+		}
 		if apierrors.IsNotFound(err) {
+			r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.SecretProviderClassNotFound, fmt.Sprintf("failed to get spc %s/%s, err: %+v", req.Namespace, spcName, err))
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
-	if len(spc.Spec.SecretObjects) == 0 {
-		klog.InfoS("no secret objects defined for spc, nothing to reconcile", "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
+	if len(spc.Spec.SecretObjects) == 0 && len(spc.Spec.ConfigMapObjects) == 0 {
+		klog.InfoS("no secret or configmap objects defined for spc, nothing to reconcile", "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
+		// the secret objects could have been removed from the spc after the secrets were synced
+		if spcpsutil.RemoveCondition(spcPodStatus, secretsstorev1.ConditionTypeSecretsSynced) {
+			if err := r.writer.Update(ctx, spcPodStatus); err != nil {
+				klog.ErrorS(err, "failed to remove secrets synced condition from spc pod status", "spcps", klog.KObj(spcPodStatus))
+				return ctrl.Result{}, err
+			}
+		}
 		return ctrl.Result{}, nil
 	}
 
 	// determine which pod volume this is associated with
-	podVol := k8sutil.SPCVolume(pod, spc.Name)
+	podVol := k8sutil.SPCVolume(pod, spcPodStatus.Status.SecretProviderClassKind, spc.Name)
 	if podVol == nil {
-		return ctrl.Result{}, fmt.Errorf("failed to find secret provider class pod status volume for pod %s/%s", req.Namespace, spcPodStatus.Status.PodName)
+		err := fmt.Errorf("failed to find secret provider class pod status volume for pod %s/%s", req.Namespace, spcPodStatus.Status.PodName)
+		r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.PodVolumeNotFound, err.Error())
+		return ctrl.Result{}, err
 	}
 
 	// validate TargetPath
 	if fileutil.GetPodUIDFromTargetPath(spcPodStatus.Status.TargetPath) != string(pod.UID) {
-		return ctrl.Result{}, fmt.Errorf("secret provider class pod status targetPath did not match pod UID for pod %s/%s", req.Namespace, spcPodStatus.Status.PodName)
+		err := fmt.Errorf("secret provider class pod status targetPath did not match pod UID for pod %s/%s", req.Namespace, spcPodStatus.Status.PodName)
+		r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.UnexpectedTargetPath, err.Error())
+		return ctrl.Result{}, err
 	}
 	if fileutil.GetVolumeNameFromTargetPath(spcPodStatus.Status.TargetPath) != podVol.Name {
-		return ctrl.Result{}, fmt.Errorf("secret provider class pod status volume name did not match pod Volume for pod %s/%s", req.Namespace, spcPodStatus.Status.PodName)
+		err := fmt.Errorf("secret provider class pod status volume name did not match pod Volume for pod %s/%s", req.Namespace, spcPodStatus.Status.PodName)
+		r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.UnexpectedTargetPath, err.Error())
+		return ctrl.Result{}, err
 	}
 
 	files, err := fileutil.GetMountedFiles(spcPodStatus.Status.TargetPath)
 	if err != nil {
 		r.generateEvent(pod, corev1.EventTypeWarning, secretCreationFailedReason, fmt.Sprintf("failed to get mounted files, err: %+v", err))
 		klog.ErrorS(err, "failed to get mounted files", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+		r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.FailedToGetMountedFiles, fmt.Sprintf("failed to get mounted files, err: %+v", err))
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
 	}
 	errs := make([]error, 0)
+	// errorReason is the reason for the last error encountered while syncing the secrets
+	var errorReason string
+	// conflicts are the messages for the secrets that aren't managed by the driver or are
+	// owned by another secret provider class
+	var conflicts []string
 	for _, secretObj := range spc.Spec.SecretObjects {
 		secretName := strings.TrimSpace(secretObj.SecretName)
 
 		if err = secretutil.ValidateSecretObject(*secretObj); err != nil {
 			klog.ErrorS(err, "failed to validate secret object in spc", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.InvalidSecretObject
 			errs = append(errs, fmt.Errorf("failed to validate secret object in spc %s/%s, err: %+v", spc.Namespace, spc.Name, err))
 			continue
 		}
 		exists, err := r.secretExists(ctx, secretName, req.Namespace)
 		if err != nil {
 			klog.ErrorS(err, "failed to check if secret exists", "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToCreateSecret
 			errs = append(errs, fmt.Errorf("failed to check if secret %s exists, err: %+v", secretName, err))
 			continue
 		}
 
-		var funcs []func() (bool, error)
+		secretType := secretutil.GetSecretType(strings.TrimSpace(secretObj.Type))
+
+		var datamap map[string][]byte
+		if datamap, err = secretutil.BuildSecretData(*secretObj, secretType, files); err != nil {
+			r.generateEvent(pod, corev1.EventTypeWarning, secretCreationFailedReason, fmt.Sprintf("failed to get data in spc %s/%s for secret %s, err: %+v", req.Namespace, spcName, secretName, err))
+			klog.ErrorS(err, "failed to get data in spc for secret", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToGetSecretData
+			errs = append(errs, fmt.Errorf("failed to get data in spc %s/%s for secret %s, err: %+v", req.Namespace, spcName, secretName, err))
+			continue
+		}
+		if err = secretutil.ValidateSecretData(secretType, datamap); err != nil {
+			r.generateEvent(pod, corev1.EventTypeWarning, secretCreationFailedReason, fmt.Sprintf("invalid data in spc %s/%s for secret %s, err: %+v", req.Namespace, spcName, secretName, err))
+			klog.ErrorS(err, "invalid data in spc for secret", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.InvalidSecretData
+			errs = append(errs, fmt.Errorf("invalid data in spc %s/%s for secret %s, err: %+v", req.Namespace, spcName, secretName, err))
+			continue
+		}
+
+		labelsMap := make(map[string]string)
+		for k, v := range secretObj.Labels {
+			labelsMap[k] = v
+		}
+		// Set secrets-store.csi.k8s.io/managed=true label on the secret that's created and managed
+		// by the secrets-store-csi-driver. This label will be used to perform a filtered list watch
+		// only on secrets created and managed by the driver
+		labelsMap[SecretManagedLabel] = "true"
 
+		var annotationsMap map[string]string
+		if annotationsMap, err = SecretAnnotations(secretObj, spcPodStatus, time.Now()); err != nil {
+			klog.ErrorS(err, "failed to build annotations for secret", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToCreateSecret
+			errs = append(errs, fmt.Errorf("failed to build annotations for secret %s, err: %+v", secretName, err))
+			continue
+		}
+		immutable := secretObj.Immutable
+
+		secret := &corev1.Secret{}
+		secretKey := types.NamespacedName{Namespace: req.Namespace, Name: secretName}
 		if !exists {
-			secretType := secretutil.GetSecretType(strings.TrimSpace(secretObj.Type))
+			var alreadyExists bool
+			createFn := func() (bool, error) {
+				err := r.createK8sSecret(ctx, secretName, req.Namespace, datamap, labelsMap, annotationsMap, secretType, immutable)
+				if apierrors.IsAlreadyExists(err) {
+					alreadyExists = true
+					return true, nil
+				}
+				if err != nil {
+					klog.ErrorS(err, "failed to create Kubernetes secret", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spcps", klog.KObj(spcPodStatus))
+					return false, nil
+				}
+				return true, nil
+			}
+			if err := wait.ExponentialBackoff(wait.Backoff{
+				Steps:    5,
+				Duration: 1 * time.Millisecond,
+				Factor:   1.0,
+				Jitter:   0.1,
+			}, createFn); err != nil {
+				r.generateEvent(pod, corev1.EventTypeWarning, secretCreationFailedReason, err.Error())
+				r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.FailedToCreateSecret, fmt.Sprintf("failed to create secret %s, err: %+v", secretName, err))
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, err
+			}
+			if !alreadyExists {
+				continue
+			}
+			// a secret that isn't managed by the driver already exists. The cache only holds
+			// the managed secrets, so the secret is read from the API server.
+			if err = r.apiReader.Get(ctx, secretKey, secret); err != nil {
+				klog.ErrorS(err, "failed to get existing secret", "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+				errorReason = internalerrors.FailedToCreateSecret
+				errs = append(errs, fmt.Errorf("failed to get existing secret %s, err: %+v", secretName, err))
+				continue
+			}
+		} else if err = r.Client.Get(ctx, secretKey, secret); err != nil {
+			klog.ErrorS(err, "failed to get secret", "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToPatchSecret
+			errs = append(errs, fmt.Errorf("failed to get secret %s, err: %+v", secretName, err))
+			continue
+		}
 
-			datamap := make(map[string][]byte)
-			if datamap, err = secretutil.GetSecretData(secretObj.Data, secretType, files); err != nil {
-				r.generateEvent(pod, corev1.EventTypeWarning, secretCreationFailedReason, fmt.Sprintf("failed to get data in spc %s/%s for secret %s, err: %+v", req.Namespace, spcName, secretName, err))
-				klog.ErrorS(err, "failed to get data in spc for secret", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spcps", klog.KObj(spcPodStatus))
-				errs = append(errs, fmt.Errorf("failed to get data in spc %s/%s for secret %s, err: %+v", req.Namespace, spcName, secretName, err))
+		if conflict := SecretConflict(secret, SecretOwner(spcPodStatus)); len(conflict) > 0 {
+			conflicts = append(conflicts, conflict)
+			switch {
+			case secretObj.ConflictPolicy == secretsstorev1.ConflictPolicyOverwrite:
+				desired := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   req.Namespace,
+						Name:        secretName,
+						Labels:      labelsMap,
+						Annotations: annotationsMap,
+					},
+					Type: secretType,
+					Data: datamap,
+				}
+				if immutable {
+					desired.Immutable = &immutable
+				}
+				if err = r.replaceK8sSecret(ctx, secret, desired); err != nil {
+					klog.ErrorS(err, "failed to overwrite secret", "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+					errorReason = internalerrors.FailedToCreateSecret
+					errs = append(errs, fmt.Errorf("failed to overwrite secret %s, err: %+v", secretName, err))
+					continue
+				}
+				r.generateEvent(pod, corev1.EventTypeWarning, secretConflictReason, fmt.Sprintf("%s, overwrote the secret as the conflict policy is %s", conflict, secretsstorev1.ConflictPolicyOverwrite))
+				continue
+			case secretObj.ConflictPolicy == secretsstorev1.ConflictPolicyAdopt && len(secret.Annotations[SecretOwnerAnnotation]) == 0:
+				// the drift correction sets the managed label and the owner annotation on the secret
+				r.generateEvent(pod, corev1.EventTypeWarning, secretConflictReason, fmt.Sprintf("%s, adopting the secret as the conflict policy is %s", conflict, secretsstorev1.ConflictPolicyAdopt))
+			default:
+				r.generateEvent(pod, corev1.EventTypeWarning, secretConflictReason, conflict)
+				klog.InfoS("secret conflict", "conflict", conflict, "conflictPolicy", secretObj.ConflictPolicy, "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
+				errorReason = internalerrors.SecretConflict
+				errs = append(errs, errors.New(conflict))
 				continue
 			}
+		}
+
+		// the driver on every node that mounts the spc syncs the secret. The data is only corrected if
+		// the mounted objects are at least as new as the ones the secret was synced from, so the nodes
+		// don't overwrite each other while the objects are rotated.
+		if SyncedFromNewerVersions(secret, spcPodStatus) {
+			klog.V(5).InfoS("secret synced from newer object versions, skipping data drift correction", "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
+			datamap = secret.Data
+			annotationsMap[ObjectVersionsAnnotation] = secret.Annotations[ObjectVersionsAnnotation]
+		}
 
-			labelsMap := make(map[string]string)
-			if secretObj.Labels != nil {
-				labelsMap = secretObj.Labels
+		// correct any drift from the desired state caused by changes in the spc or edits to the secret
+		var drift []string
+		if drift, err = r.correctSecretDrift(ctx, secret, datamap, labelsMap, annotationsMap, secretType, immutable); err != nil {
+			r.generateEvent(pod, corev1.EventTypeWarning, secretDriftCorrectionFailedReason, fmt.Sprintf("failed to correct drift in secret %s, err: %+v", secretName, err))
+			klog.ErrorS(err, "failed to correct drift in secret", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToPatchSecret
+			errs = append(errs, fmt.Errorf("failed to correct drift in secret %s, err: %+v", secretName, err))
+			continue
+		}
+		if len(drift) > 0 {
+			for _, driftType := range drift {
+				r.reporter.reportSecretDriftCtMetric(driftType)
 			}
-			// Set secrets-store.csi.k8s.io/managed=true label on the secret that's created and managed
-			// by the secrets-store-csi-driver. This label will be used to perform a filtered list watch
-			// only on secrets created and managed by the driver
-			labelsMap[SecretManagedLabel] = "true"
+			r.generateEvent(pod, corev1.EventTypeNormal, secretDriftCorrectedReason, fmt.Sprintf("corrected drift in %s of secret %s", strings.Join(drift, ", "), secretName))
+			klog.InfoS("corrected drift in secret", "drift", drift, "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
+		}
+	}
+	for _, configMapObj := range spc.Spec.ConfigMapObjects {
+		configMapName := strings.TrimSpace(configMapObj.ConfigMapName)
+
+		if err = secretutil.ValidateConfigMapObject(*configMapObj); err != nil {
+			klog.ErrorS(err, "failed to validate configmap object in spc", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.InvalidConfigMapObject
+			errs = append(errs, fmt.Errorf("failed to validate configmap object in spc %s/%s, err: %+v", spc.Namespace, spc.Name, err))
+			continue
+		}
+		exists, err := r.configMapExists(ctx, configMapName, req.Namespace)
+		if err != nil {
+			klog.ErrorS(err, "failed to check if configmap exists", "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToCreateConfigMap
+			errs = append(errs, fmt.Errorf("failed to check if configmap %s exists, err: %+v", configMapName, err))
+			continue
+		}
+
+		data, binaryData, err := secretutil.GetConfigMapData(configMapObj.Data, files)
+		if err != nil {
+			r.generateEvent(pod, corev1.EventTypeWarning, configMapCreationFailedReason, fmt.Sprintf("failed to get data in spc %s/%s for configmap %s, err: %+v", req.Namespace, spcName, configMapName, err))
+			klog.ErrorS(err, "failed to get data in spc for configmap", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToGetConfigMapData
+			errs = append(errs, fmt.Errorf("failed to get data in spc %s/%s for configmap %s, err: %+v", req.Namespace, spcName, configMapName, err))
+			continue
+		}
+
+		labelsMap := make(map[string]string)
+		for k, v := range configMapObj.Labels {
+			labelsMap[k] = v
+		}
+		// Set secrets-store.csi.k8s.io/managed=true label on the configmap so the driver only
+		// watches the configmaps it created and manages
+		labelsMap[SecretManagedLabel] = "true"
 
+		var annotationsMap map[string]string
+		if annotationsMap, err = ConfigMapAnnotations(spcPodStatus, time.Now()); err != nil {
+			klog.ErrorS(err, "failed to build annotations for configmap", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToCreateConfigMap
+			errs = append(errs, fmt.Errorf("failed to build annotations for configmap %s, err: %+v", configMapName, err))
+			continue
+		}
+
+		configMap := &corev1.ConfigMap{}
+		configMapKey := types.NamespacedName{Namespace: req.Namespace, Name: configMapName}
+		if !exists {
+			var alreadyExists bool
 			createFn := func() (bool, error) {
-				if err := r.createK8sSecret(ctx, secretName, req.Namespace, datamap, labelsMap, secretType); err != nil {
-					klog.ErrorS(err, "failed to create Kubernetes secret", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "secret", klog.ObjectRef{Namespace: req.Namespace, Name: secretName}, "spcps", klog.KObj(spcPodStatus))
+				err := r.createK8sConfigMap(ctx, configMapName, req.Namespace, data, binaryData, labelsMap, annotationsMap)
+				if apierrors.IsAlreadyExists(err) {
+					alreadyExists = true
+					return true, nil
+				}
+				if err != nil {
+					klog.ErrorS(err, "failed to create Kubernetes configmap", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spcps", klog.KObj(spcPodStatus))
 					return false, nil
 				}
 				return true, nil
 			}
-			funcs = append(funcs, createFn)
-		}
-
-		for _, f := range funcs {
 			if err := wait.ExponentialBackoff(wait.Backoff{
 				Steps:    5,
 				Duration: 1 * time.Millisecond,
 				Factor:   1.0,
 				Jitter:   0.1,
-			}, f); err != nil {
-				r.generateEvent(pod, corev1.EventTypeWarning, secretCreationFailedReason, err.Error())
+			}, createFn); err != nil {
+				r.generateEvent(pod, corev1.EventTypeWarning, configMapCreationFailedReason, err.Error())
+				r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, internalerrors.FailedToCreateConfigMap, fmt.Sprintf("failed to create configmap %s, err: %+v", configMapName, err))
 				return ctrl.Result{RequeueAfter: 5 * time.Second}, err
 			}
+			if !alreadyExists {
+				continue
+			}
+			// a configmap that isn't managed by the driver already exists. The cache only holds
+			// the managed configmaps, so the configmap is read from the API server.
+			if err = r.apiReader.Get(ctx, configMapKey, configMap); err != nil {
+				klog.ErrorS(err, "failed to get existing configmap", "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+				errorReason = internalerrors.FailedToCreateConfigMap
+				errs = append(errs, fmt.Errorf("failed to get existing configmap %s, err: %+v", configMapName, err))
+				continue
+			}
+		} else if err = r.Client.Get(ctx, configMapKey, configMap); err != nil {
+			klog.ErrorS(err, "failed to get configmap", "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToPatchConfigMap
+			errs = append(errs, fmt.Errorf("failed to get configmap %s, err: %+v", configMapName, err))
+			continue
+		}
+
+		if conflict := ConfigMapConflict(configMap, SecretOwner(spcPodStatus)); len(conflict) > 0 {
+			conflicts = append(conflicts, conflict)
+			switch {
+			case configMapObj.ConflictPolicy == secretsstorev1.ConflictPolicyOverwrite:
+				desired := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   req.Namespace,
+						Name:        configMapName,
+						Labels:      labelsMap,
+						Annotations: annotationsMap,
+					},
+					Data:       data,
+					BinaryData: binaryData,
+				}
+				if err = r.replaceK8sConfigMap(ctx, configMap, desired); err != nil {
+					klog.ErrorS(err, "failed to overwrite configmap", "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
+					errorReason = internalerrors.FailedToCreateConfigMap
+					errs = append(errs, fmt.Errorf("failed to overwrite configmap %s, err: %+v", configMapName, err))
+					continue
+				}
+				r.generateEvent(pod, corev1.EventTypeWarning, secretConflictReason, fmt.Sprintf("%s, overwrote the configmap as the conflict policy is %s", conflict, secretsstorev1.ConflictPolicyOverwrite))
+				continue
+			case configMapObj.ConflictPolicy == secretsstorev1.ConflictPolicyAdopt && len(configMap.Annotations[SecretOwnerAnnotation]) == 0:
+				// the drift correction sets the managed label and the owner annotation on the configmap
+				r.generateEvent(pod, corev1.EventTypeWarning, secretConflictReason, fmt.Sprintf("%s, adopting the configmap as the conflict policy is %s", conflict, secretsstorev1.ConflictPolicyAdopt))
+			default:
+				r.generateEvent(pod, corev1.EventTypeWarning, secretConflictReason, conflict)
+				klog.InfoS("configmap conflict", "conflict", conflict, "conflictPolicy", configMapObj.ConflictPolicy, "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
+				errorReason = internalerrors.SecretConflict
+				errs = append(errs, errors.New(conflict))
+				continue
+			}
+		}
+
+		// the configmaps are synced by every node in the same way as the secrets
+		if SyncedFromNewerVersions(configMap, spcPodStatus) {
+			klog.V(5).InfoS("configmap synced from newer object versions, skipping data drift correction", "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
+			data, binaryData = configMap.Data, configMap.BinaryData
+			annotationsMap[ObjectVersionsAnnotation] = configMap.Annotations[ObjectVersionsAnnotation]
+		}
+
+		// correct any drift from the desired state caused by changes in the spc or edits to the configmap
+		var drift []string
+		if drift, err = r.correctConfigMapDrift(ctx, configMap, data, binaryData, labelsMap, annotationsMap); err != nil {
+			r.generateEvent(pod, corev1.EventTypeWarning, configMapDriftCorrectionFailedReason, fmt.Sprintf("failed to correct drift in configmap %s, err: %+v", configMapName, err))
+			klog.ErrorS(err, "failed to correct drift in configmap", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spcps", klog.KObj(spcPodStatus))
+			errorReason = internalerrors.FailedToPatchConfigMap
+			errs = append(errs, fmt.Errorf("failed to correct drift in configmap %s, err: %+v", configMapName, err))
+			continue
+		}
+		if len(drift) > 0 {
+			for _, driftType := range drift {
+				r.reporter.reportConfigMapDriftCtMetric(driftType)
+			}
+			r.generateEvent(pod, corev1.EventTypeNormal, configMapDriftCorrectedReason, fmt.Sprintf("corrected drift in %s of configmap %s", strings.Join(drift, ", "), configMapName))
+			klog.InfoS("corrected drift in configmap", "drift", drift, "configmap", klog.ObjectRef{Namespace: req.Namespace, Name: configMapName}, "spc", klog.KObj(spc), "spcps", klog.KObj(spcPodStatus))
 		}
 	}
+	r.setSecretConflictCondition(ctx, spcPodStatus, conflicts)
 
 	if len(errs) > 0 {
+		r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionFalse, errorReason, utilerrors.NewAggregate(errs).Error())
 		return ctrl.Result{Requeue: true}, nil
 	}
+	r.setSecretsSyncedCondition(ctx, spcPodStatus, metav1.ConditionTrue, secretsstorev1.SyncSucceededReason, "")
 
 	klog.InfoS("reconcile complete", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "spcps", klog.KObj(spcPodStatus))
 	// requeue the spc pod status again after 5mins to check if secret and ownerRef exists
@@ -356,7 +833,7 @@ func (r *SecretProviderClassPodStatusReconciler) Reconcile(ctx context.Context,
 
 func (r *SecretProviderClassPodStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.SecretProviderClassPodStatus{}).
+		For(&secretsstorev1.SecretProviderClassPodStatus{}).
 		WithEventFilter(r.belongsToNodePredicate()).
 		Complete(r)
 }
@@ -382,7 +859,7 @@ func (r *SecretProviderClassPodStatusReconciler) belongsToNodePredicate() predic
 // processIfBelongsToNode determines if the secretproviderclasspodstatus belongs to the node based on the
 // internal.secrets-store.csi.k8s.io/node-name: <node name> label. If belongs to node, then the spcps is processed.
 func (r *SecretProviderClassPodStatusReconciler) processIfBelongsToNode(objMeta metav1.Object) bool {
-	node, ok := objMeta.GetLabels()[v1alpha1.InternalNodeLabel]
+	node, ok := objMeta.GetLabels()[secretsstorev1.InternalNodeLabel]
 	if !ok {
 		return false
 	}
@@ -393,24 +870,45 @@ func (r *SecretProviderClassPodStatusReconciler) processIfBelongsToNode(objMeta
 }
 
 // createK8sSecret creates K8s secret with data from mounted files
-// If a secret with the same name already exists in the namespace of the pod, the error is nil.
-func (r *SecretProviderClassPodStatusReconciler) createK8sSecret(ctx context.Context, name, namespace string, datamap map[string][]byte, labelsmap map[string]string, secretType corev1.SecretType) error {
+func (r *SecretProviderClassPodStatusReconciler) createK8sSecret(ctx context.Context, name, namespace string, datamap map[string][]byte, labelsmap, annotationsmap map[string]string, secretType corev1.SecretType, immutable bool) error {
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-			Name:      name,
-			Labels:    labelsmap,
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      labelsmap,
+			Annotations: annotationsmap,
 		},
 		Type: secretType,
 		Data: datamap,
 	}
+	if immutable {
+		secret.Immutable = &immutable
+	}
 
 	err := r.writer.Create(ctx, secret)
 	if err == nil {
 		klog.InfoS("successfully created Kubernetes secret", "secret", klog.ObjectRef{Namespace: namespace, Name: name})
 		return nil
 	}
-	if apierrors.IsAlreadyExists(err) {
+	return err
+}
+
+// createK8sConfigMap creates K8s configmap with data from mounted files
+func (r *SecretProviderClassPodStatusReconciler) createK8sConfigMap(ctx context.Context, name, namespace string, data map[string]string, binaryData map[string][]byte, labelsmap, annotationsmap map[string]string) error {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      labelsmap,
+			Annotations: annotationsmap,
+		},
+		Data:       data,
+		BinaryData: binaryData,
+	}
+
+	err := r.writer.Create(ctx, configMap)
+	if err == nil {
+		klog.InfoS("successfully created Kubernetes configmap", "configmap", klog.ObjectRef{Namespace: namespace, Name: name})
 		return nil
 	}
 	return err
@@ -418,43 +916,91 @@ func (r *SecretProviderClassPodStatusReconciler) createK8sSecret(ctx context.Con
 
 // patchSecretWithOwnerRef patches the secret owner reference with the spc pod status
 func (r *SecretProviderClassPodStatusReconciler) patchSecretWithOwnerRef(ctx context.Context, name, namespace string, ownerRefs ...metav1.OwnerReference) error {
-	secret := &corev1.Secret{}
-	secretKey := types.NamespacedName{
+	return r.patchWithOwnerRef(ctx, &corev1.Secret{}, name, namespace, ownerRefs...)
+}
+
+// patchConfigMapWithOwnerRef patches the configmap owner reference with the spc pod status
+func (r *SecretProviderClassPodStatusReconciler) patchConfigMapWithOwnerRef(ctx context.Context, name, namespace string, ownerRefs ...metav1.OwnerReference) error {
+	return r.patchWithOwnerRef(ctx, &corev1.ConfigMap{}, name, namespace, ownerRefs...)
+}
+
+// patchWithOwnerRef adds the owner references that are missing to the object with name and namespace
+func (r *SecretProviderClassPodStatusReconciler) patchWithOwnerRef(ctx context.Context, obj client.Object, name, namespace string, ownerRefs ...metav1.OwnerReference) error {
+	key := types.NamespacedName{
 		Namespace: namespace,
 		Name:      name,
 	}
-	if err := r.Client.Get(ctx, secretKey, secret); err != nil {
+	if err := r.Client.Get(ctx, key, obj); err != nil {
 		if apierrors.IsNotFound(err) {
-			klog.V(5).InfoS("secret not found for patching", "secret", klog.ObjectRef{Namespace: namespace, Name: name})
+			klog.V(5).InfoS("object not found for patching", "object", klog.ObjectRef{Namespace: namespace, Name: name})
 			return nil
 		}
 		return err
 	}
 
-	patch := client.MergeFromWithOptions(secret.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
 	needsPatch := false
 
-	secretOwnerRefs := secret.GetOwnerReferences()
-	secretOwnerMap := make(map[string]types.UID)
-	for _, or := range secretOwnerRefs {
-		secretOwnerMap[or.Name] = or.UID
+	objOwnerRefs := obj.GetOwnerReferences()
+	objOwnerMap := make(map[string]types.UID)
+	for _, or := range objOwnerRefs {
+		objOwnerMap[or.Name] = or.UID
 	}
 
 	for i := range ownerRefs {
-		if _, exists := secretOwnerMap[ownerRefs[i].Name]; exists {
+		if _, exists := objOwnerMap[ownerRefs[i].Name]; exists {
 			continue
 		}
 		// add to map for tracking
-		secretOwnerMap[ownerRefs[i].Name] = ownerRefs[i].UID
+		objOwnerMap[ownerRefs[i].Name] = ownerRefs[i].UID
 		needsPatch = true
 		klog.V(5).Infof("Adding %s/%s as owner ref for %s/%s", ownerRefs[i].APIVersion, ownerRefs[i].Name, namespace, name)
-		secretOwnerRefs = append(secretOwnerRefs, ownerRefs[i])
+		objOwnerRefs = append(objOwnerRefs, ownerRefs[i])
 	}
 
 	if needsPatch {
-		secret.SetOwnerReferences(secretOwnerRefs)
-		return r.writer.Patch(ctx, secret, patch)
+		obj.SetOwnerReferences(objOwnerRefs)
+		return r.writer.Patch(ctx, obj, patch)
+	}
+	return nil
+}
+
+// removeSecretOwnerRefs removes the owner references from the secret
+func (r *SecretProviderClassPodStatusReconciler) removeSecretOwnerRefs(ctx context.Context, secret *corev1.Secret, ownerRefs []metav1.OwnerReference) error {
+	remove := make(map[types.UID]bool, len(ownerRefs))
+	for _, ref := range ownerRefs {
+		remove[ref.UID] = true
+	}
+	var secretOwnerRefs []metav1.OwnerReference
+	for _, ref := range secret.OwnerReferences {
+		if !remove[ref.UID] {
+			secretOwnerRefs = append(secretOwnerRefs, ref)
+		}
+	}
+	if len(secretOwnerRefs) == len(secret.OwnerReferences) {
+		return nil
 	}
+
+	patch := client.MergeFromWithOptions(secret.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	secret.OwnerReferences = secretOwnerRefs
+	if err := r.writer.Patch(ctx, secret, patch); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to remove owner references from secret %s/%s, err: %+v", secret.Namespace, secret.Name, err)
+	}
+	klog.InfoS("removed owner references from retained secret", "secret", klog.KObj(secret))
+	return nil
+}
+
+// deleteK8sSecret deletes the secret that's no longer declared in the secret objects. The UID and
+// resource version preconditions ensure a secret that's created again with the same name or synced
+// since it was listed isn't deleted.
+func (r *SecretProviderClassPodStatusReconciler) deleteK8sSecret(ctx context.Context, secret *corev1.Secret) error {
+	if err := r.writer.Delete(ctx, secret, client.Preconditions{UID: &secret.UID, ResourceVersion: &secret.ResourceVersion}); err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete secret %s/%s, err: %+v", secret.Namespace, secret.Name, err)
+	}
+	klog.InfoS("deleted secret removed from secret objects", "secret", klog.KObj(secret))
 	return nil
 }
 
@@ -475,6 +1021,216 @@ func (r *SecretProviderClassPodStatusReconciler) secretExists(ctx context.Contex
 	return false, err
 }
 
+// correctSecretDrift compares the live secret with the desired data, labels, annotations and type
+// and corrects any difference. The secret is replaced if the type changed, as the type of a secret
+// can't be updated, or if the data of an immutable secret changed. Returns the drifted fields.
+// Labels and annotations are add-only: a desired key that's missing or set to another value is
+// corrected, while keys that aren't desired are kept as they can't be told apart from the ones
+// set by other controllers.
+func (r *SecretProviderClassPodStatusReconciler) correctSecretDrift(ctx context.Context, secret *corev1.Secret, datamap map[string][]byte, labelsmap, annotationsmap map[string]string, secretType corev1.SecretType, immutable bool) ([]string, error) {
+	namespace, name := secret.Namespace, secret.Name
+	var drift []string
+	currentDataSHA, err := secretutil.GetSHAFromSecret(secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute SHA for %s/%s current data, err: %+v", namespace, name, err)
+	}
+	desiredDataSHA, err := secretutil.GetSHAFromSecret(datamap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute SHA for %s/%s desired data, err: %+v", namespace, name, err)
+	}
+	dataChanged := currentDataSHA != desiredDataSHA
+	if dataChanged {
+		drift = append(drift, "data")
+	}
+	for k, v := range labelsmap {
+		if cv, ok := secret.Labels[k]; !ok || cv != v {
+			drift = append(drift, "labels")
+			break
+		}
+	}
+	if SecretAnnotationsChanged(secret.Annotations, annotationsmap) {
+		drift = append(drift, "annotations")
+	}
+	typeChanged := secret.Type != secretType
+	if typeChanged {
+		drift = append(drift, "type")
+	}
+	if len(drift) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(secret.Labels)+len(labelsmap))
+	for k, v := range secret.Labels {
+		labels[k] = v
+	}
+	for k, v := range labelsmap {
+		labels[k] = v
+	}
+	annotations := make(map[string]string, len(secret.Annotations)+len(annotationsmap))
+	for k, v := range secret.Annotations {
+		annotations[k] = v
+	}
+	for k, v := range annotationsmap {
+		annotations[k] = v
+	}
+	// the last sync time is only updated when the data changes
+	if t, ok := secret.Annotations[LastSyncTimeAnnotation]; ok && !dataChanged {
+		annotations[LastSyncTimeAnnotation] = t
+	}
+
+	if typeChanged || (dataChanged && (immutable || (secret.Immutable != nil && *secret.Immutable))) {
+		newSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       namespace,
+				Name:            name,
+				Labels:          labels,
+				Annotations:     annotations,
+				OwnerReferences: secret.OwnerReferences,
+			},
+			Type: secretType,
+			Data: datamap,
+		}
+		if immutable {
+			newSecret.Immutable = &immutable
+		}
+		return drift, r.replaceK8sSecret(ctx, secret, newSecret)
+	}
+
+	patch := client.MergeFromWithOptions(secret.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	secret.Data = datamap
+	secret.Labels = labels
+	secret.Annotations = annotations
+	return drift, r.writer.Patch(ctx, secret, patch)
+}
+
+// replaceK8sSecret deletes the current secret and creates the desired secret
+func (r *SecretProviderClassPodStatusReconciler) replaceK8sSecret(ctx context.Context, current, desired *corev1.Secret) error {
+	// the preconditions ensure the secret isn't deleted if it has been modified since it was read
+	if err := r.writer.Delete(ctx, current, client.Preconditions{UID: &current.UID, ResourceVersion: &current.ResourceVersion}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := r.writer.Create(ctx, desired); err != nil {
+		return err
+	}
+	klog.InfoS("successfully replaced Kubernetes secret", "secret", klog.KObj(desired))
+	return nil
+}
+
+// correctConfigMapDrift compares the live configmap with the desired data, labels and annotations
+// and corrects any difference in the same way as correctSecretDrift. Returns the drifted fields.
+func (r *SecretProviderClassPodStatusReconciler) correctConfigMapDrift(ctx context.Context, configMap *corev1.ConfigMap, data map[string]string, binaryData map[string][]byte, labelsmap, annotationsmap map[string]string) ([]string, error) {
+	namespace, name := configMap.Namespace, configMap.Name
+	var drift []string
+	currentDataSHA, err := secretutil.GetSHAFromConfigMap(configMap.Data, configMap.BinaryData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute SHA for %s/%s current data, err: %+v", namespace, name, err)
+	}
+	desiredDataSHA, err := secretutil.GetSHAFromConfigMap(data, binaryData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute SHA for %s/%s desired data, err: %+v", namespace, name, err)
+	}
+	dataChanged := currentDataSHA != desiredDataSHA
+	if dataChanged {
+		drift = append(drift, "data")
+	}
+	for k, v := range labelsmap {
+		if cv, ok := configMap.Labels[k]; !ok || cv != v {
+			drift = append(drift, "labels")
+			break
+		}
+	}
+	if SecretAnnotationsChanged(configMap.Annotations, annotationsmap) {
+		drift = append(drift, "annotations")
+	}
+	if len(drift) == 0 {
+		return nil, nil
+	}
+
+	patch := client.MergeFromWithOptions(configMap.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	labels := make(map[string]string, len(configMap.Labels)+len(labelsmap))
+	for k, v := range configMap.Labels {
+		labels[k] = v
+	}
+	for k, v := range labelsmap {
+		labels[k] = v
+	}
+	annotations := make(map[string]string, len(configMap.Annotations)+len(annotationsmap))
+	for k, v := range configMap.Annotations {
+		annotations[k] = v
+	}
+	for k, v := range annotationsmap {
+		annotations[k] = v
+	}
+	// the last sync time is only updated when the data changes
+	if t, ok := configMap.Annotations[LastSyncTimeAnnotation]; ok && !dataChanged {
+		annotations[LastSyncTimeAnnotation] = t
+	}
+	configMap.Data = data
+	configMap.BinaryData = binaryData
+	configMap.Labels = labels
+	configMap.Annotations = annotations
+	return drift, r.writer.Patch(ctx, configMap, patch)
+}
+
+// replaceK8sConfigMap deletes the current configmap and creates the desired configmap
+func (r *SecretProviderClassPodStatusReconciler) replaceK8sConfigMap(ctx context.Context, current, desired *corev1.ConfigMap) error {
+	// the preconditions ensure the configmap isn't deleted if it has been modified since it was read
+	if err := r.writer.Delete(ctx, current, client.Preconditions{UID: &current.UID, ResourceVersion: &current.ResourceVersion}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := r.writer.Create(ctx, desired); err != nil {
+		return err
+	}
+	klog.InfoS("successfully replaced Kubernetes configmap", "configmap", klog.KObj(desired))
+	return nil
+}
+
+// configMapExists checks if the configmap with name and namespace already exists
+func (r *SecretProviderClassPodStatusReconciler) configMapExists(ctx context.Context, name, namespace string) (bool, error) {
+	o := &v1.ConfigMap{}
+	configMapKey := types.NamespacedName{
+		Namespace: namespace,
+		Name:      name,
+	}
+	err := r.Client.Get(ctx, configMapKey, o)
+	if err == nil {
+		return true, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// setSecretsSyncedCondition sets the SecretsSynced condition in the spc pod status. The update is
+// skipped if the condition is unchanged. A failed update is only logged as the update event from
+// the conflicting change triggers another reconcile.
+func (r *SecretProviderClassPodStatusReconciler) setSecretsSyncedCondition(ctx context.Context, spcPodStatus *secretsstorev1.SecretProviderClassPodStatus, status metav1.ConditionStatus, reason, message string) {
+	if !spcpsutil.SetCondition(spcPodStatus, secretsstorev1.ConditionTypeSecretsSynced, status, reason, message) {
+		return
+	}
+	if err := r.writer.Update(ctx, spcPodStatus); err != nil {
+		klog.ErrorS(err, "failed to update secrets synced condition in spc pod status", "spcps", klog.KObj(spcPodStatus))
+	}
+}
+
+// setSecretConflictCondition sets the SecretConflict condition in the spc pod status if there are
+// conflicts and removes it otherwise. The update is skipped if the condition is unchanged.
+func (r *SecretProviderClassPodStatusReconciler) setSecretConflictCondition(ctx context.Context, spcPodStatus *secretsstorev1.SecretProviderClassPodStatus, conflicts []string) {
+	var changed bool
+	if len(conflicts) > 0 {
+		changed = spcpsutil.SetCondition(spcPodStatus, secretsstorev1.ConditionTypeSecretConflict, metav1.ConditionTrue, internalerrors.SecretConflict, strings.Join(conflicts, "; "))
+	} else {
+		changed = spcpsutil.RemoveCondition(spcPodStatus, secretsstorev1.ConditionTypeSecretConflict)
+	}
+	if !changed {
+		return
+	}
+	if err := r.writer.Update(ctx, spcPodStatus); err != nil {
+		klog.ErrorS(err, "failed to update secret conflict condition in spc pod status", "spcps", klog.KObj(spcPodStatus))
+	}
+}
+
 // generateEvent generates an event
 func (r *SecretProviderClassPodStatusReconciler) generateEvent(obj runtime.Object, eventType, reason, message string) {
 	if obj != nil {
@@ -18,6 +18,8 @@ package controllers
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -27,15 +29,18 @@ import (
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 )
 
 var (
@@ -44,7 +49,7 @@ var (
 
 func setupScheme() (*runtime.Scheme, error) {
 	scheme := runtime.NewScheme()
-	if err := v1alpha1.AddToScheme(scheme); err != nil {
+	if err := secretsstorev1.AddToScheme(scheme); err != nil {
 		return nil, err
 	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
@@ -64,16 +69,16 @@ func newSecret(name, namespace string, labels map[string]string) *v1.Secret {
 	}
 }
 
-func newSecretProviderClassPodStatus(name, namespace, node string) *v1alpha1.SecretProviderClassPodStatus {
-	return &v1alpha1.SecretProviderClassPodStatus{
+func newSecretProviderClassPodStatus(name, namespace, node string) *secretsstorev1.SecretProviderClassPodStatus {
+	return &secretsstorev1.SecretProviderClassPodStatus{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            name,
 			Namespace:       namespace,
-			Labels:          map[string]string{v1alpha1.InternalNodeLabel: node},
+			Labels:          map[string]string{secretsstorev1.InternalNodeLabel: node},
 			UID:             "72a0ecb8-c6e5-41e1-8da1-25e37ec61b26",
 			ResourceVersion: "73659",
 		},
-		Status: v1alpha1.SecretProviderClassPodStatusStatus{
+		Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 			PodName:                 "pod1",
 			TargetPath:              "/var/lib/kubelet/pods/d8771ddf-935a-4199-a20b-f35f71c1d9e7/volumes/kubernetes.io~csi/secrets-store-inline/mount",
 			SecretProviderClassName: "spc1",
@@ -82,15 +87,15 @@ func newSecretProviderClassPodStatus(name, namespace, node string) *v1alpha1.Sec
 	}
 }
 
-func newSecretProviderClass(name, namespace string) *v1alpha1.SecretProviderClass {
-	return &v1alpha1.SecretProviderClass{
+func newSecretProviderClass(name, namespace string) *secretsstorev1.SecretProviderClass {
+	return &secretsstorev1.SecretProviderClass{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
 		},
-		Spec: v1alpha1.SecretProviderClassSpec{
+		Spec: secretsstorev1.SecretProviderClassSpec{
 			Provider: "provider1",
-			SecretObjects: []*v1alpha1.SecretObject{
+			SecretObjects: []*secretsstorev1.SecretObject{
 				{
 					SecretName: "secret1",
 					Type:       "Opaque",
@@ -114,9 +119,11 @@ func newReconciler(client client.Client, scheme *runtime.Scheme, nodeID string)
 	return &SecretProviderClassPodStatusReconciler{
 		Client:        client,
 		reader:        client,
+		apiReader:     client,
 		writer:        client,
 		scheme:        scheme,
 		eventRecorder: fakeRecorder,
+		reporter:      newStatsReporter(),
 		mutex:         &sync.Mutex{},
 		nodeID:        nodeID,
 	}
@@ -146,6 +153,36 @@ func TestSecretExists(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 }
 
+func TestSetSecretsSyncedCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	initObjects := []runtime.Object{
+		newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1"),
+	}
+
+	client := fake.NewFakeClientWithScheme(scheme, initObjects...)
+	reconciler := newReconciler(client, scheme, "node1")
+
+	key := types.NamespacedName{Name: "pod1-default-spc1", Namespace: "default"}
+	spcps := &secretsstorev1.SecretProviderClassPodStatus{}
+	err = client.Get(context.TODO(), key, spcps)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	reconciler.setSecretsSyncedCondition(context.TODO(), spcps, metav1.ConditionFalse, "FailedToCreateSecret", "failed to create secret secret1")
+
+	updated := &secretsstorev1.SecretProviderClassPodStatus{}
+	err = client.Get(context.TODO(), key, updated)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Status.Conditions).To(HaveLen(1))
+	g.Expect(updated.Status.Conditions[0].Type).To(Equal(secretsstorev1.ConditionTypeSecretsSynced))
+	g.Expect(updated.Status.Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(updated.Status.Conditions[0].Reason).To(Equal("FailedToCreateSecret"))
+	g.Expect(updated.Status.Conditions[0].Message).To(Equal("failed to create secret secret1"))
+}
+
 func TestPatchSecretWithOwnerRef(t *testing.T) {
 	g := NewWithT(t)
 
@@ -197,16 +234,20 @@ func TestCreateK8sSecret(t *testing.T) {
 	reconciler := newReconciler(client, scheme, "node1")
 
 	// secret already exists
-	err = reconciler.createK8sSecret(context.TODO(), "my-secret", "default", nil, labels, v1.SecretTypeOpaque)
-	g.Expect(err).NotTo(HaveOccurred())
+	err = reconciler.createK8sSecret(context.TODO(), "my-secret", "default", nil, labels, nil, v1.SecretTypeOpaque, false)
+	g.Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
 
-	err = reconciler.createK8sSecret(context.TODO(), "my-secret2", "default", nil, labels, v1.SecretTypeOpaque)
+	annotations := map[string]string{SecretOwnerAnnotation: "SecretProviderClass/spc1"}
+	err = reconciler.createK8sSecret(context.TODO(), "my-secret2", "default", nil, labels, annotations, v1.SecretTypeOpaque, true)
 	g.Expect(err).NotTo(HaveOccurred())
 	secret := &v1.Secret{}
 	err = client.Get(context.TODO(), types.NamespacedName{Name: "my-secret2", Namespace: "default"}, secret)
 	g.Expect(err).NotTo(HaveOccurred())
 
 	g.Expect(secret.Labels).To(Equal(labels))
+	g.Expect(secret.Annotations).To(Equal(annotations))
+	g.Expect(secret.Immutable).NotTo(BeNil())
+	g.Expect(*secret.Immutable).To(BeTrue())
 
 	g.Expect(secret.Name).To(Equal("my-secret2"))
 }
@@ -259,7 +300,7 @@ func TestPatcherForStaticPod(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 
 	g.Expect(len(secret.OwnerReferences)).To(Equal(1))
-	g.Expect(secret.OwnerReferences[0].APIVersion).To(Equal(v1alpha1.GroupVersion.String()))
+	g.Expect(secret.OwnerReferences[0].APIVersion).To(Equal(secretsstorev1.GroupVersion.String()))
 	g.Expect(secret.OwnerReferences[0].Kind).To(Equal("SecretProviderClassPodStatus"))
 	g.Expect(secret.OwnerReferences[0].Name).To(Equal("pod1-default-spc1"))
 }
@@ -303,3 +344,586 @@ func TestPatcherForPodWithOwner(t *testing.T) {
 	g.Expect(secret.OwnerReferences[0].Name).To(Equal("pod-6886c65f8f"))
 	g.Expect(secret.OwnerReferences[0].UID).To(Equal(types.UID("f39da13d-7246-4ef5-aed4-a6905f82cbcd")))
 }
+
+func TestCreateK8sConfigMap(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	labels := map[string]string{SecretManagedLabel: "true"}
+
+	initObjects := []runtime.Object{
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "my-configmap", Namespace: "default"}},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, initObjects...)
+	reconciler := newReconciler(client, scheme, "node1")
+
+	annotations := map[string]string{SecretOwnerAnnotation: "SecretProviderClass/spc1"}
+
+	// configmap already exists
+	err = reconciler.createK8sConfigMap(context.TODO(), "my-configmap", "default", nil, nil, labels, annotations)
+	g.Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
+
+	exists, err := reconciler.configMapExists(context.TODO(), "my-configmap2", "default")
+	g.Expect(exists).To(Equal(false))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	err = reconciler.createK8sConfigMap(context.TODO(), "my-configmap2", "default", map[string]string{"ca.crt": "cert"}, map[string][]byte{"file.bin": {0xff}}, labels, annotations)
+	g.Expect(err).NotTo(HaveOccurred())
+	configMap := &v1.ConfigMap{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "my-configmap2", Namespace: "default"}, configMap)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(configMap.Labels).To(Equal(labels))
+	g.Expect(configMap.Annotations).To(Equal(annotations))
+	g.Expect(configMap.Data).To(Equal(map[string]string{"ca.crt": "cert"}))
+	g.Expect(configMap.BinaryData).To(Equal(map[string][]byte{"file.bin": {0xff}}))
+}
+
+func TestPatcherForConfigMap(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	spc := newSecretProviderClass("spc1", "default")
+	spc.Spec.ConfigMapObjects = []*secretsstorev1.ConfigMapObject{
+		{
+			ConfigMapName: "configmap1",
+			Data:          []*secretsstorev1.SecretObjectData{{ObjectName: "ca", Key: "ca.crt"}},
+		},
+	}
+	initObjects := []runtime.Object{
+		newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1"),
+		spc,
+		newPod("pod1", "default", nil),
+		newSecret("secret1", "default", nil),
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "configmap1", Namespace: "default", ResourceVersion: "73659"}},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, initObjects...)
+	reconciler := newReconciler(client, scheme, "node1")
+
+	err = reconciler.Patcher(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// check the spcps has been added as owner to the configmap
+	configMap := &v1.ConfigMap{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "configmap1", Namespace: "default"}, configMap)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(len(configMap.OwnerReferences)).To(Equal(1))
+	g.Expect(configMap.OwnerReferences[0].Kind).To(Equal("SecretProviderClassPodStatus"))
+	g.Expect(configMap.OwnerReferences[0].Name).To(Equal("pod1-default-spc1"))
+}
+
+func TestCorrectSecretDrift(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	labels := map[string]string{SecretManagedLabel: "true", "environment": "test"}
+	annotations := map[string]string{SecretOwnerAnnotation: "SecretProviderClass/spc1", LastSyncTimeAnnotation: "2021-04-02T10:00:00Z"}
+
+	tests := []struct {
+		name                 string
+		secret               *v1.Secret
+		datamap              map[string][]byte
+		secretType           v1.SecretType
+		expectedDrift        []string
+		expectedLabels       map[string]string
+		expectedLastSyncTime string
+	}{
+		{
+			name: "no drift",
+			secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret1", Namespace: "default", Labels: labels, Annotations: annotations, ResourceVersion: "73659"},
+				Type:       v1.SecretTypeOpaque,
+				Data:       map[string][]byte{"key1": []byte("value1")},
+			},
+			datamap:              map[string][]byte{"key1": []byte("value1")},
+			secretType:           v1.SecretTypeOpaque,
+			expectedLabels:       labels,
+			expectedLastSyncTime: "2021-04-02T10:00:00Z",
+		},
+		{
+			name: "hand edited data and removed label",
+			secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "secret1",
+					Namespace:       "default",
+					Labels:          map[string]string{SecretManagedLabel: "true", "owner": "user"},
+					Annotations:     map[string]string{SecretOwnerAnnotation: "SecretProviderClass/spc1", LastSyncTimeAnnotation: "2021-04-01T10:00:00Z"},
+					ResourceVersion: "73659",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{"key1": []byte("edited"), "key2": []byte("value2")},
+			},
+			datamap:              map[string][]byte{"key1": []byte("value1")},
+			secretType:           v1.SecretTypeOpaque,
+			expectedDrift:        []string{"data", "labels"},
+			expectedLabels:       map[string]string{SecretManagedLabel: "true", "environment": "test", "owner": "user"},
+			expectedLastSyncTime: "2021-04-02T10:00:00Z",
+		},
+		{
+			name: "hand edited label value",
+			secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "secret1",
+					Namespace:       "default",
+					Labels:          map[string]string{SecretManagedLabel: "true", "environment": "prod"},
+					Annotations:     annotations,
+					ResourceVersion: "73659",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{"key1": []byte("value1")},
+			},
+			datamap:              map[string][]byte{"key1": []byte("value1")},
+			secretType:           v1.SecretTypeOpaque,
+			expectedDrift:        []string{"labels"},
+			expectedLabels:       labels,
+			expectedLastSyncTime: "2021-04-02T10:00:00Z",
+		},
+		{
+			name: "type changed in spc",
+			secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret1", Namespace: "default", Labels: labels, Annotations: annotations, ResourceVersion: "73659"},
+				Type:       v1.SecretTypeOpaque,
+				Data:       map[string][]byte{"username": []byte("admin")},
+			},
+			datamap:              map[string][]byte{"username": []byte("admin")},
+			secretType:           v1.SecretTypeBasicAuth,
+			expectedDrift:        []string{"type"},
+			expectedLabels:       labels,
+			expectedLastSyncTime: "2021-04-02T10:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, test.secret)
+			reconciler := newReconciler(client, scheme, "node1")
+
+			secret := &v1.Secret{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: "secret1", Namespace: "default"}, secret)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			drift, err := reconciler.correctSecretDrift(context.TODO(), secret, test.datamap, labels, annotations, test.secretType, false)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(drift).To(Equal(test.expectedDrift))
+
+			secret = &v1.Secret{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: "secret1", Namespace: "default"}, secret)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(secret.Data).To(Equal(test.datamap))
+			g.Expect(secret.Type).To(Equal(test.secretType))
+			g.Expect(secret.Labels).To(Equal(test.expectedLabels))
+			g.Expect(secret.Annotations[LastSyncTimeAnnotation]).To(Equal(test.expectedLastSyncTime))
+		})
+	}
+}
+
+func TestReconcileSecretConflict(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name               string
+		conflictPolicy     secretsstorev1.ConflictPolicy
+		existingLabels     map[string]string
+		existingOwner      string
+		expectedData       string
+		expectedOwner      string
+		expectedSyncStatus metav1.ConditionStatus
+	}{
+		{
+			name:               "unmanaged secret with default policy",
+			expectedData:       "old",
+			expectedSyncStatus: metav1.ConditionFalse,
+		},
+		{
+			name:               "unmanaged secret with adopt policy",
+			conflictPolicy:     secretsstorev1.ConflictPolicyAdopt,
+			expectedData:       "new",
+			expectedOwner:      "SecretProviderClass/spc1",
+			expectedSyncStatus: metav1.ConditionTrue,
+		},
+		{
+			name:               "secret owned by another class with adopt policy",
+			conflictPolicy:     secretsstorev1.ConflictPolicyAdopt,
+			existingLabels:     map[string]string{SecretManagedLabel: "true"},
+			existingOwner:      "SecretProviderClass/spc2",
+			expectedData:       "old",
+			expectedOwner:      "SecretProviderClass/spc2",
+			expectedSyncStatus: metav1.ConditionFalse,
+		},
+		{
+			name:               "secret owned by another class with overwrite policy",
+			conflictPolicy:     secretsstorev1.ConflictPolicyOverwrite,
+			existingLabels:     map[string]string{SecretManagedLabel: "true"},
+			existingOwner:      "SecretProviderClass/spc2",
+			expectedData:       "new",
+			expectedOwner:      "SecretProviderClass/spc1",
+			expectedSyncStatus: metav1.ConditionTrue,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			podUID := "d8771ddf-935a-4199-a20b-f35f71c1d9e7"
+			targetPath := filepath.Join(t.TempDir(), "pods", podUID, "volumes", "kubernetes.io~csi", "secrets-store-inline", "mount")
+			g.Expect(os.MkdirAll(targetPath, 0755)).To(Succeed())
+			g.Expect(os.WriteFile(filepath.Join(targetPath, "obj1"), []byte("new"), 0600)).To(Succeed())
+
+			spcPodStatus := newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1")
+			spcPodStatus.Status.TargetPath = targetPath
+			spc := newSecretProviderClass("spc1", "default")
+			spc.Spec.SecretObjects[0].Data = []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "key1"}}
+			spc.Spec.SecretObjects[0].ConflictPolicy = test.conflictPolicy
+			pod := newPod("pod1", "default", nil)
+			pod.UID = types.UID(podUID)
+			pod.Spec.Volumes = []v1.Volume{{
+				Name: "secrets-store-inline",
+				VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+				}},
+			}}
+			secret := newSecret("secret1", "default", test.existingLabels)
+			secret.Data = map[string][]byte{"key1": []byte("old")}
+			if len(test.existingOwner) > 0 {
+				secret.Annotations = map[string]string{SecretOwnerAnnotation: test.existingOwner}
+			}
+
+			client := fake.NewFakeClientWithScheme(scheme, spcPodStatus, spc, pod, secret)
+			reconciler := newReconciler(client, scheme, "node1")
+			recorder := record.NewFakeRecorder(10)
+			reconciler.eventRecorder = recorder
+
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pod1-default-spc1"}})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			secret = &v1.Secret{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: "secret1", Namespace: "default"}, secret)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(secret.Data["key1"])).To(Equal(test.expectedData))
+			g.Expect(secret.Annotations[SecretOwnerAnnotation]).To(Equal(test.expectedOwner))
+
+			updated := &secretsstorev1.SecretProviderClassPodStatus{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: "pod1-default-spc1", Namespace: "default"}, updated)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(meta.IsStatusConditionPresentAndEqual(updated.Status.Conditions, secretsstorev1.ConditionTypeSecretsSynced, test.expectedSyncStatus)).To(BeTrue())
+			if test.expectedSyncStatus == metav1.ConditionFalse {
+				g.Expect(meta.FindStatusCondition(updated.Status.Conditions, secretsstorev1.ConditionTypeSecretsSynced).Reason).To(Equal("SecretConflict"))
+			}
+			// the conflict is reported for every policy
+			g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, secretsstorev1.ConditionTypeSecretConflict)).To(BeTrue())
+			g.Expect(<-recorder.Events).To(HavePrefix("Warning SecretConflict"))
+		})
+	}
+}
+
+func TestReconcileConfigMapConflict(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name               string
+		conflictPolicy     secretsstorev1.ConflictPolicy
+		existingLabels     map[string]string
+		existingOwner      string
+		expectedData       string
+		expectedOwner      string
+		expectedSyncStatus metav1.ConditionStatus
+	}{
+		{
+			name:               "unmanaged configmap with default policy",
+			expectedData:       "old",
+			expectedSyncStatus: metav1.ConditionFalse,
+		},
+		{
+			name:               "unmanaged configmap with adopt policy",
+			conflictPolicy:     secretsstorev1.ConflictPolicyAdopt,
+			expectedData:       "new",
+			expectedOwner:      "SecretProviderClass/spc1",
+			expectedSyncStatus: metav1.ConditionTrue,
+		},
+		{
+			name:               "configmap owned by another class with overwrite policy",
+			conflictPolicy:     secretsstorev1.ConflictPolicyOverwrite,
+			existingLabels:     map[string]string{SecretManagedLabel: "true"},
+			existingOwner:      "SecretProviderClass/spc2",
+			expectedData:       "new",
+			expectedOwner:      "SecretProviderClass/spc1",
+			expectedSyncStatus: metav1.ConditionTrue,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			podUID := "d8771ddf-935a-4199-a20b-f35f71c1d9e7"
+			targetPath := filepath.Join(t.TempDir(), "pods", podUID, "volumes", "kubernetes.io~csi", "secrets-store-inline", "mount")
+			g.Expect(os.MkdirAll(targetPath, 0755)).To(Succeed())
+			g.Expect(os.WriteFile(filepath.Join(targetPath, "obj1"), []byte("new"), 0600)).To(Succeed())
+
+			spcPodStatus := newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1")
+			spcPodStatus.Status.TargetPath = targetPath
+			spc := newSecretProviderClass("spc1", "default")
+			spc.Spec.SecretObjects = nil
+			spc.Spec.ConfigMapObjects = []*secretsstorev1.ConfigMapObject{{
+				ConfigMapName:  "configmap1",
+				Data:           []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "key1"}},
+				ConflictPolicy: test.conflictPolicy,
+			}}
+			pod := newPod("pod1", "default", nil)
+			pod.UID = types.UID(podUID)
+			pod.Spec.Volumes = []v1.Volume{{
+				Name: "secrets-store-inline",
+				VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+				}},
+			}}
+			configMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap1", Namespace: "default", Labels: test.existingLabels},
+				Data:       map[string]string{"key1": "old"},
+			}
+			if len(test.existingOwner) > 0 {
+				configMap.Annotations = map[string]string{SecretOwnerAnnotation: test.existingOwner}
+			}
+
+			client := fake.NewFakeClientWithScheme(scheme, spcPodStatus, spc, pod, configMap)
+			reconciler := newReconciler(client, scheme, "node1")
+			recorder := record.NewFakeRecorder(10)
+			reconciler.eventRecorder = recorder
+
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pod1-default-spc1"}})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			configMap = &v1.ConfigMap{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: "configmap1", Namespace: "default"}, configMap)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(configMap.Data["key1"]).To(Equal(test.expectedData))
+			g.Expect(configMap.Annotations[SecretOwnerAnnotation]).To(Equal(test.expectedOwner))
+
+			updated := &secretsstorev1.SecretProviderClassPodStatus{}
+			err = client.Get(context.TODO(), types.NamespacedName{Name: "pod1-default-spc1", Namespace: "default"}, updated)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(meta.IsStatusConditionPresentAndEqual(updated.Status.Conditions, secretsstorev1.ConditionTypeSecretsSynced, test.expectedSyncStatus)).To(BeTrue())
+			g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, secretsstorev1.ConditionTypeSecretConflict)).To(BeTrue())
+			g.Expect(<-recorder.Events).To(HavePrefix("Warning SecretConflict"))
+		})
+	}
+}
+
+func TestPatcherForSecretOwnedByAnotherClass(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	secret := newSecret("secret1", "default", map[string]string{SecretManagedLabel: "true"})
+	secret.Annotations = map[string]string{SecretOwnerAnnotation: "SecretProviderClass/spc2"}
+	initObjects := []runtime.Object{
+		newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1"),
+		newSecretProviderClass("spc1", "default"),
+		newPod("pod1", "default", nil),
+		secret,
+	}
+	client := fake.NewFakeClientWithScheme(scheme, initObjects...)
+	reconciler := newReconciler(client, scheme, "node1")
+
+	err = reconciler.Patcher(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// check the spcps isn't added as owner to the secret owned by spc2
+	secret = &v1.Secret{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "secret1", Namespace: "default"}, secret)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secret.OwnerReferences).To(BeEmpty())
+}
+
+func TestPatcherForConfigMapOwnedByAnotherClass(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	spc := newSecretProviderClass("spc1", "default")
+	spc.Spec.ConfigMapObjects = []*secretsstorev1.ConfigMapObject{
+		{
+			ConfigMapName: "configmap1",
+			Data:          []*secretsstorev1.SecretObjectData{{ObjectName: "ca", Key: "ca.crt"}},
+		},
+	}
+	initObjects := []runtime.Object{
+		newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1"),
+		spc,
+		newPod("pod1", "default", nil),
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:            "configmap1",
+			Namespace:       "default",
+			Annotations:     map[string]string{SecretOwnerAnnotation: "SecretProviderClass/spc2"},
+			ResourceVersion: "73659",
+		}},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, initObjects...)
+	reconciler := newReconciler(client, scheme, "node1")
+
+	err = reconciler.Patcher(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// check the spcps isn't added as owner to the configmap owned by spc2
+	configMap := &v1.ConfigMap{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: "configmap1", Namespace: "default"}, configMap)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(configMap.OwnerReferences).To(BeEmpty())
+}
+
+func TestPatcherGarbageCollectsSecrets(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	spcPodStatus := newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1")
+	spcPodStatusRef := metav1.OwnerReference{
+		APIVersion: secretsstorev1.GroupVersion.String(),
+		Kind:       "SecretProviderClassPodStatus",
+		Name:       spcPodStatus.Name,
+		UID:        spcPodStatus.UID,
+	}
+	otherRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       "rs1",
+		UID:        "c1e0e6b1-2ec5-4e16-a6b3-d1c1e4f1c2a3",
+	}
+	newManagedSecret := func(name, owner string, retainPolicy secretsstorev1.RetainPolicy) *v1.Secret {
+		secret := newSecret(name, "default", map[string]string{SecretManagedLabel: "true"})
+		secret.Annotations = map[string]string{
+			SecretOwnerAnnotation:  owner,
+			RetainPolicyAnnotation: string(retainPolicy),
+		}
+		secret.OwnerReferences = []metav1.OwnerReference{spcPodStatusRef, otherRef}
+		return secret
+	}
+
+	spc := newSecretProviderClass("spc1", "default")
+	spc.Spec.SecretObjects = append(spc.Spec.SecretObjects, &secretsstorev1.SecretObject{
+		SecretName:   "retained",
+		Type:         "Opaque",
+		RetainPolicy: secretsstorev1.RetainPolicyRetain,
+	})
+	initObjects := []runtime.Object{
+		spcPodStatus,
+		spc,
+		newPod("pod1", "default", nil),
+		newManagedSecret("secret1", "SecretProviderClass/spc1", secretsstorev1.RetainPolicyDelete),
+		newManagedSecret("retained", "SecretProviderClass/spc1", secretsstorev1.RetainPolicyRetain),
+		newManagedSecret("removed", "SecretProviderClass/spc1", secretsstorev1.RetainPolicyDelete),
+		newManagedSecret("removed-retained", "SecretProviderClass/spc1", secretsstorev1.RetainPolicyRetain),
+		// owned by a secret provider class that isn't used on the node
+		newManagedSecret("unused", "SecretProviderClass/spc2", secretsstorev1.RetainPolicyDelete),
+	}
+	client := fake.NewFakeClientWithScheme(scheme, initObjects...)
+	reconciler := newReconciler(client, scheme, "node1")
+
+	err = reconciler.Patcher(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name              string
+		expectedExists    bool
+		expectedOwnerRefs []metav1.OwnerReference
+	}{
+		{
+			name:              "secret1",
+			expectedExists:    true,
+			expectedOwnerRefs: []metav1.OwnerReference{spcPodStatusRef, otherRef},
+		},
+		{
+			name:              "retained",
+			expectedExists:    true,
+			expectedOwnerRefs: []metav1.OwnerReference{otherRef},
+		},
+		{
+			name:           "removed",
+			expectedExists: false,
+		},
+		{
+			name:              "removed-retained",
+			expectedExists:    true,
+			expectedOwnerRefs: []metav1.OwnerReference{otherRef},
+		},
+		{
+			name:              "unused",
+			expectedExists:    true,
+			expectedOwnerRefs: []metav1.OwnerReference{spcPodStatusRef, otherRef},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			secret := &v1.Secret{}
+			err := client.Get(context.TODO(), types.NamespacedName{Name: test.name, Namespace: "default"}, secret)
+			if !test.expectedExists {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(secret.OwnerReferences).To(Equal(test.expectedOwnerRefs))
+		})
+	}
+}
+
+func TestPatcherGarbageCollectWithStaleSecretProviderClass(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newManagedSecret := func(name string) *v1.Secret {
+		secret := newSecret(name, "default", map[string]string{SecretManagedLabel: "true"})
+		secret.Annotations = map[string]string{
+			SecretOwnerAnnotation:  "SecretProviderClass/spc1",
+			RetainPolicyAnnotation: string(secretsstorev1.RetainPolicyDelete),
+		}
+		return secret
+	}
+
+	// the secret provider class in the cache doesn't declare the secret that's added to
+	// the latest secret provider class and synced by another node
+	cachedSPC := newSecretProviderClass("spc1", "default")
+	latestSPC := newSecretProviderClass("spc1", "default")
+	latestSPC.Spec.SecretObjects = append(latestSPC.Spec.SecretObjects, &secretsstorev1.SecretObject{
+		SecretName: "added",
+		Type:       "Opaque",
+	})
+	cachedClient := fake.NewFakeClientWithScheme(scheme,
+		newSecretProviderClassPodStatus("pod1-default-spc1", "default", "node1"),
+		cachedSPC,
+		newPod("pod1", "default", nil),
+		newManagedSecret("secret1"),
+		newManagedSecret("added"),
+		newManagedSecret("removed"),
+	)
+	reconciler := newReconciler(cachedClient, scheme, "node1")
+	reconciler.apiReader = fake.NewFakeClientWithScheme(scheme, latestSPC)
+
+	err = reconciler.Patcher(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	secret := &v1.Secret{}
+	err = cachedClient.Get(context.TODO(), types.NamespacedName{Name: "added", Namespace: "default"}, secret)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = cachedClient.Get(context.TODO(), types.NamespacedName{Name: "removed", Namespace: "default"}, secret)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"runtime"
+
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/label"
+)
+
+var (
+	driftTypeKey        = "drift_type"
+	osTypeKey           = "os_type"
+	secretDriftTotal    metric.Int64Counter
+	configMapDriftTotal metric.Int64Counter
+	runtimeOS           = runtime.GOOS
+)
+
+type reporter struct {
+	meter metric.Meter
+}
+
+type StatsReporter interface {
+	reportSecretDriftCtMetric(driftType string)
+	reportConfigMapDriftCtMetric(driftType string)
+}
+
+func newStatsReporter() StatsReporter {
+	meter := global.Meter("secretsstore")
+	secretDriftTotal = metric.Must(meter).NewInt64Counter("total_sync_k8s_secret_drift", metric.WithDescription("Total number of drifts corrected in synced k8s secrets"))
+	configMapDriftTotal = metric.Must(meter).NewInt64Counter("total_sync_k8s_configmap_drift", metric.WithDescription("Total number of drifts corrected in synced k8s configmaps"))
+	return &reporter{meter: meter}
+}
+
+func (r *reporter) reportSecretDriftCtMetric(driftType string) {
+	labels := []label.KeyValue{label.String(driftTypeKey, driftType), label.String(osTypeKey, runtimeOS)}
+	secretDriftTotal.Add(context.Background(), 1, labels...)
+}
+
+func (r *reporter) reportConfigMapDriftCtMetric(driftType string) {
+	labels := []label.KeyValue{label.String(driftTypeKey, driftType), label.String(osTypeKey, runtimeOS)}
+	configMapDriftTotal.Add(context.Background(), 1, labels...)
+}
@@ -0,0 +1,14 @@
+  {{- if .Values.webhook.conversion.enabled }}
+  conversion:
+    strategy: Webhook
+    webhook:
+      clientConfig:
+        service:
+          name: {{ template "sscd.fullname" . }}-webhook
+          namespace: {{ .Release.Namespace }}
+          path: /convert
+        caBundle: {{ .Values.webhook.caBundle }}
+      conversionReviewVersions:
+      - v1
+      - v1beta1
+  {{- end }}
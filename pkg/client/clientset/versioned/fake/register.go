@@ -24,6 +24,7 @@ import (
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	serializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	secretsstorev1alpha1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
 )
 
@@ -32,6 +33,7 @@ var codecs = serializer.NewCodecFactory(scheme)
 var parameterCodec = runtime.NewParameterCodec(scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	secretsstorev1alpha1.AddToScheme,
+	secretsstorev1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
@@ -27,6 +27,9 @@ const (
 	FailedToMount = "FailedToMount"
 	// SecretProviderClassNotFound error
 	SecretProviderClassNotFound = "SecretProviderClassNotFound"
+	// ProviderThrottled error
+	// Indicates the provider throttled the request.
+	ProviderThrottled = "ProviderThrottled"
 	// FailedToLookupProviderGRPCClient error
 	FailedToLookupProviderGRPCClient = "FailedToLookupProviderGRPCClient"
 	// GRPCProviderError error
@@ -35,6 +38,9 @@ const (
 	FailedToRotate = "FailedToRotate"
 	// PodNotFound error
 	PodNotFound = "PodNotFound"
+	// NamespaceNotAllowed error
+	// Indicates the pod namespace is not selected by the ClusterSecretProviderClass namespace selector.
+	NamespaceNotAllowed = "NamespaceNotAllowed"
 	// NodePublishSecretRefNotFound error
 	// #nosec G101 (Ref: https://github.com/securego/gosec/issues/295)
 	NodePublishSecretRefNotFound = "NodePublishSecretRefNotFound"
@@ -45,4 +51,55 @@ const (
 	PodVolumeNotFound = "PodVolumeNotFound"
 	// FileWriteError error
 	FileWriteError = "FileWriteError"
+	// FailedToGetMountedFiles error
+	FailedToGetMountedFiles = "FailedToGetMountedFiles"
+	// InvalidSecretObject error
+	// Indicates the secret object in SecretProviderClass failed validation.
+	InvalidSecretObject = "InvalidSecretObject"
+	// FailedToGetSecretData error
+	FailedToGetSecretData = "FailedToGetSecretData"
+	// InvalidSecretData error
+	// Indicates the secret data is not valid for the secret type.
+	InvalidSecretData = "InvalidSecretData"
+	// FailedToCreateSecret error
+	FailedToCreateSecret = "FailedToCreateSecret"
+	// FailedToPatchSecret error
+	FailedToPatchSecret = "FailedToPatchSecret"
+	// SecretConflict error
+	// Indicates the secret already exists and isn't managed by the driver or is owned by another secret provider class.
+	SecretConflict = "SecretConflict"
+	// InvalidConfigMapObject error
+	// Indicates the configmap object in SecretProviderClass failed validation.
+	InvalidConfigMapObject = "InvalidConfigMapObject"
+	// FailedToGetConfigMapData error
+	FailedToGetConfigMapData = "FailedToGetConfigMapData"
+	// FailedToCreateConfigMap error
+	FailedToCreateConfigMap = "FailedToCreateConfigMap"
+	// FailedToPatchConfigMap error
+	FailedToPatchConfigMap = "FailedToPatchConfigMap"
+	// FailedToUpdateSecretProviderClassPodStatus error
+	FailedToUpdateSecretProviderClassPodStatus = "FailedToUpdateSecretProviderClassPodStatus"
+	// InvalidSecretProviderClass error
+	// Indicates the SecretProviderClass failed validation.
+	InvalidSecretProviderClass = "InvalidSecretProviderClass"
+	// ProviderNotFound error
+	// Indicates the provider socket is not present on one or more nodes.
+	ProviderNotFound = "ProviderNotFound"
+	// MountsFailed error
+	MountsFailed = "MountsFailed"
+	// RotationsFailed error
+	RotationsFailed = "RotationsFailed"
+	// WorkloadNotFound error
+	// Indicates the pod has no workload to roll out, or to recreate it after an eviction.
+	WorkloadNotFound = "WorkloadNotFound"
+	// PostRotationActionRateLimited error
+	// Indicates the post rotation action was taken on the workload less than the min interval ago.
+	PostRotationActionRateLimited = "PostRotationActionRateLimited"
+	// EvictionBlocked error
+	// Indicates the eviction of the pod would violate a pod disruption budget.
+	EvictionBlocked = "EvictionBlocked"
+	// FailedToRolloutWorkload error
+	FailedToRolloutWorkload = "FailedToRolloutWorkload"
+	// FailedToEvictPod error
+	FailedToEvictPod = "FailedToEvictPod"
 )
@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// ClusterSecretProviderClassLister is a store to list clustersecretproviderclasses
+type ClusterSecretProviderClassLister struct {
+	cache.Store
+}
+
+// GetWithKey returns cluster secret provider class with key from the informer cache
+func (cspcl *ClusterSecretProviderClassLister) GetWithKey(key string) (*secretsstorev1.ClusterSecretProviderClass, error) {
+	s, exists, err := cspcl.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: secretsstorev1.GroupName, Resource: "clustersecretproviderclasses"}, key)
+	}
+	cspc, ok := s.(*secretsstorev1.ClusterSecretProviderClass)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast %T to %s", s, "clustersecretproviderclass")
+	}
+	return cspc, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NamespaceLister is a store used to list namespaces
+type NamespaceLister struct {
+	cache.Store
+}
+
+// GetWithKey returns namespace with key from the informer cache
+func (nl *NamespaceLister) GetWithKey(key string) (*v1.Namespace, error) {
+	n, exists, err := nl.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: v1.GroupName, Resource: "namespaces"}, key)
+	}
+	namespace, ok := n.(*v1.Namespace)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast %T to %s", n, "namespace")
+	}
+	return namespace, nil
+}
@@ -22,7 +22,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 
 	"k8s.io/client-go/tools/cache"
 )
@@ -33,15 +33,15 @@ type SecretProviderClassLister struct {
 }
 
 // GetWithKey returns secret provider class with key from the informer cache
-func (spcl *SecretProviderClassLister) GetWithKey(key string) (*v1alpha1.SecretProviderClass, error) {
+func (spcl *SecretProviderClassLister) GetWithKey(key string) (*secretsstorev1.SecretProviderClass, error) {
 	s, exists, err := spcl.GetByKey(key)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, apierrors.NewNotFound(schema.GroupResource{Group: v1alpha1.GroupName, Resource: "secretproviderclasses"}, key)
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: secretsstorev1.GroupName, Resource: "secretproviderclasses"}, key)
 	}
-	spc, ok := s.(*v1alpha1.SecretProviderClass)
+	spc, ok := s.(*secretsstorev1.SecretProviderClass)
 	if !ok {
 		return nil, fmt.Errorf("failed to cast %T to %s", s, "secretproviderclass")
 	}
@@ -22,7 +22,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 
 	"k8s.io/client-go/tools/cache"
 )
@@ -33,15 +33,15 @@ type SecretProviderClassPodStatusLister struct {
 }
 
 // GetWithKey returns secret provider class pod status with key from the informer cache
-func (spcpsl *SecretProviderClassPodStatusLister) GetWithKey(key string) (*v1alpha1.SecretProviderClassPodStatus, error) {
+func (spcpsl *SecretProviderClassPodStatusLister) GetWithKey(key string) (*secretsstorev1.SecretProviderClassPodStatus, error) {
 	s, exists, err := spcpsl.GetByKey(key)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, apierrors.NewNotFound(schema.GroupResource{Group: v1alpha1.GroupName, Resource: "secretproviderclasspodstatuses"}, key)
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: secretsstorev1.GroupName, Resource: "secretproviderclasspodstatuses"}, key)
 	}
-	spcps, ok := s.(*v1alpha1.SecretProviderClassPodStatus)
+	spcps, ok := s.(*secretsstorev1.SecretProviderClassPodStatus)
 	if !ok {
 		return nil, fmt.Errorf("failed to cast %T to %s", s, "secretproviderclasspodstatus")
 	}
@@ -18,6 +18,7 @@ package k8s
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/client-go/informers/internalinterfaces"
@@ -29,29 +30,47 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	"sigs.k8s.io/secrets-store-csi-driver/controllers"
 	secretsStoreClient "sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned"
-	secretsStoreInformers "sigs.k8s.io/secrets-store-csi-driver/pkg/client/informers/externalversions/apis/v1alpha1"
+	secretsStoreInformers "sigs.k8s.io/secrets-store-csi-driver/pkg/client/informers/externalversions/apis/v1"
 	secretsStoreInternalInterfaces "sigs.k8s.io/secrets-store-csi-driver/pkg/client/informers/externalversions/internalinterfaces"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
 )
 
+// secretProviderClassIndex is the name of the index of the spc pod statuses by the
+// secret provider class they reference
+const secretProviderClassIndex = "secretProviderClass"
+
+// podIndex is the name of the index of the spc pod statuses by the pod they belong to
+const podIndex = "pod"
+
+// nodePublishSecretRefIndex is the name of the index of the pods by the nodePublishSecretRef
+// secrets referenced in their secrets store volumes
+const nodePublishSecretRefIndex = "nodePublishSecretRef"
+
 // Informer holds the shared index informers
 type Informer struct {
 	Pod                          cache.SharedIndexInformer
 	Secret                       cache.SharedIndexInformer
+	ConfigMap                    cache.SharedIndexInformer
 	NodePublishSecretRefSecret   cache.SharedIndexInformer
 	SecretProviderClass          cache.SharedIndexInformer
 	SecretProviderClassPodStatus cache.SharedIndexInformer
+	ClusterSecretProviderClass   cache.SharedIndexInformer
+	Namespace                    cache.SharedIndexInformer
 }
 
 // Lister holds the object lister
 type Lister struct {
 	Pod                          PodLister
 	Secret                       SecretLister
+	ConfigMap                    ConfigMapLister
 	NodePublishSecretRefSecret   SecretLister
 	SecretProviderClass          SecretProviderClassLister
 	SecretProviderClassPodStatus SecretProviderClassPodStatusLister
+	ClusterSecretProviderClass   ClusterSecretProviderClassLister
+	Namespace                    NamespaceLister
 }
 
 type Store interface {
@@ -59,15 +78,35 @@ type Store interface {
 	GetPod(name, namespace string) (*v1.Pod, error)
 	// GetSecret returns the secret matching name and namespace
 	GetSecret(name, namespace string) (*v1.Secret, error)
+	// GetConfigMap returns the configmap matching name and namespace
+	GetConfigMap(name, namespace string) (*v1.ConfigMap, error)
 	// GetNodePublishSecretRefSecret returns the NodePublishSecretRef secret matching name and namespace
 	GetNodePublishSecretRefSecret(name, namespace string) (*v1.Secret, error)
 	// GetSecretProviderClass returns the secret provider class matching name and namespace
-	GetSecretProviderClass(name, namespace string) (*v1alpha1.SecretProviderClass, error)
+	GetSecretProviderClass(name, namespace string) (*secretsstorev1.SecretProviderClass, error)
 	// GetSecretProviderClassPodStatus returns the secret provider class pod status matching key
-	GetSecretProviderClassPodStatus(key string) (*v1alpha1.SecretProviderClassPodStatus, error)
+	GetSecretProviderClassPodStatus(key string) (*secretsstorev1.SecretProviderClassPodStatus, error)
+	// GetClusterSecretProviderClass returns the cluster secret provider class matching name
+	GetClusterSecretProviderClass(name string) (*secretsstorev1.ClusterSecretProviderClass, error)
+	// GetNamespace returns the namespace matching name
+	GetNamespace(name string) (*v1.Namespace, error)
 	// ListSecretProviderClassPodStatus returns a list of SecretProviderClassPodStatus
 	// that match the label for the node the driver is running on
-	ListSecretProviderClassPodStatus() ([]*v1alpha1.SecretProviderClassPodStatus, error)
+	ListSecretProviderClassPodStatus() ([]*secretsstorev1.SecretProviderClassPodStatus, error)
+	// ListSecretProviderClassPodStatusForSPC returns the SecretProviderClassPodStatus for the node
+	// the driver is running on that reference the secret provider class of kind matching name and
+	// namespace. The namespace is ignored for a ClusterSecretProviderClass.
+	ListSecretProviderClassPodStatusForSPC(kind, name, namespace string) ([]*secretsstorev1.SecretProviderClassPodStatus, error)
+	// ListSecretProviderClassPodStatusForNodePublishSecretRef returns the SecretProviderClassPodStatus
+	// for the node the driver is running on whose volume references the nodePublishSecretRef secret
+	// matching name and namespace
+	ListSecretProviderClassPodStatusForNodePublishSecretRef(name, namespace string) ([]*secretsstorev1.SecretProviderClassPodStatus, error)
+	// AddSecretProviderClassEventHandler adds the event handler to the secret provider class
+	// and cluster secret provider class informers
+	AddSecretProviderClassEventHandler(handler cache.ResourceEventHandler)
+	// AddNodePublishSecretRefEventHandler adds the event handler to the nodePublishSecretRef
+	// secret informer
+	AddNodePublishSecretRefEventHandler(handler cache.ResourceEventHandler)
 	// Run initializes and runs the informers
 	Run(stopCh <-chan struct{}) error
 }
@@ -88,6 +127,9 @@ func New(kubeClient kubernetes.Interface, crdClient secretsStoreClient.Interface
 	store.informers.Secret = newSecretInformer(kubeClient, resyncPeriod)
 	store.listers.Secret.Store = store.informers.Secret.GetStore()
 
+	store.informers.ConfigMap = newConfigMapInformer(kubeClient, resyncPeriod)
+	store.listers.ConfigMap.Store = store.informers.ConfigMap.GetStore()
+
 	store.informers.NodePublishSecretRefSecret = newNodePublishSecretRefSecretInformer(kubeClient, resyncPeriod, filteredWatchSecret)
 	store.listers.NodePublishSecretRefSecret.Store = store.informers.NodePublishSecretRefSecret.GetStore()
 
@@ -97,6 +139,12 @@ func New(kubeClient kubernetes.Interface, crdClient secretsStoreClient.Interface
 	store.informers.SecretProviderClassPodStatus = newSPCPodStatusInformer(crdClient, resyncPeriod, nodeName)
 	store.listers.SecretProviderClassPodStatus.Store = store.informers.SecretProviderClassPodStatus.GetStore()
 
+	store.informers.ClusterSecretProviderClass = newClusterSPCInformer(crdClient, resyncPeriod)
+	store.listers.ClusterSecretProviderClass.Store = store.informers.ClusterSecretProviderClass.GetStore()
+
+	store.informers.Namespace = newNamespaceInformer(kubeClient, resyncPeriod)
+	store.listers.Namespace.Store = store.informers.Namespace.GetStore()
+
 	return store, nil
 }
 
@@ -115,22 +163,27 @@ func (s k8sStore) GetSecret(name, namespace string) (*v1.Secret, error) {
 	return s.listers.Secret.GetWithKey(getStoreKey(name, namespace))
 }
 
+// GetConfigMap returns the configmap matching name and namespace
+func (s k8sStore) GetConfigMap(name, namespace string) (*v1.ConfigMap, error) {
+	return s.listers.ConfigMap.GetWithKey(getStoreKey(name, namespace))
+}
+
 // GetNodePublishSecretRefSecret returns the NodePublishSecretRef secret matching name and namespace
 func (s k8sStore) GetNodePublishSecretRefSecret(name, namespace string) (*v1.Secret, error) {
 	return s.listers.NodePublishSecretRefSecret.GetWithKey(getStoreKey(name, namespace))
 }
 
 // GetSecretProviderClass returns the secret provider class matching name and namespace
-func (s k8sStore) GetSecretProviderClass(name, namespace string) (*v1alpha1.SecretProviderClass, error) {
+func (s k8sStore) GetSecretProviderClass(name, namespace string) (*secretsstorev1.SecretProviderClass, error) {
 	return s.listers.SecretProviderClass.GetWithKey(getStoreKey(name, namespace))
 }
 
 // ListSecretProviderClassPodStatus returns a list of SecretProviderClassPodStatus
 // that match the label for the node the driver is running on
-func (s k8sStore) ListSecretProviderClassPodStatus() ([]*v1alpha1.SecretProviderClassPodStatus, error) {
-	var secretProviderClassPodStatuses []*v1alpha1.SecretProviderClassPodStatus
+func (s k8sStore) ListSecretProviderClassPodStatus() ([]*secretsstorev1.SecretProviderClassPodStatus, error) {
+	var secretProviderClassPodStatuses []*secretsstorev1.SecretProviderClassPodStatus
 	for _, item := range s.listers.SecretProviderClassPodStatus.List() {
-		spcps, ok := item.(*v1alpha1.SecretProviderClassPodStatus)
+		spcps, ok := item.(*secretsstorev1.SecretProviderClassPodStatus)
 		if !ok {
 			return nil, fmt.Errorf("failed to cast %T to %s", item, "secretproviderclasspodstatus")
 		}
@@ -139,19 +192,100 @@ func (s k8sStore) ListSecretProviderClassPodStatus() ([]*v1alpha1.SecretProvider
 	return secretProviderClassPodStatuses, nil
 }
 
+// ListSecretProviderClassPodStatusForSPC returns the SecretProviderClassPodStatus for the node
+// the driver is running on that reference the secret provider class of kind matching name and
+// namespace. The namespace is ignored for a ClusterSecretProviderClass.
+func (s k8sStore) ListSecretProviderClassPodStatusForSPC(kind, name, namespace string) ([]*secretsstorev1.SecretProviderClassPodStatus, error) {
+	items, err := s.informers.SecretProviderClassPodStatus.GetIndexer().ByIndex(secretProviderClassIndex, spcIndexKey(kind, name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	var secretProviderClassPodStatuses []*secretsstorev1.SecretProviderClassPodStatus
+	for _, item := range items {
+		spcps, ok := item.(*secretsstorev1.SecretProviderClassPodStatus)
+		if !ok {
+			return nil, fmt.Errorf("failed to cast %T to %s", item, "secretproviderclasspodstatus")
+		}
+		secretProviderClassPodStatuses = append(secretProviderClassPodStatuses, spcps)
+	}
+	return secretProviderClassPodStatuses, nil
+}
+
+// ListSecretProviderClassPodStatusForNodePublishSecretRef returns the SecretProviderClassPodStatus
+// for the node the driver is running on whose volume references the nodePublishSecretRef secret
+// matching name and namespace
+func (s k8sStore) ListSecretProviderClassPodStatusForNodePublishSecretRef(name, namespace string) ([]*secretsstorev1.SecretProviderClassPodStatus, error) {
+	pods, err := s.informers.Pod.GetIndexer().ByIndex(nodePublishSecretRefIndex, getStoreKey(name, namespace))
+	if err != nil {
+		return nil, err
+	}
+	var secretProviderClassPodStatuses []*secretsstorev1.SecretProviderClassPodStatus
+	for _, obj := range pods {
+		pod, ok := obj.(*v1.Pod)
+		if !ok {
+			return nil, fmt.Errorf("failed to cast %T to %s", obj, "pod")
+		}
+		items, err := s.informers.SecretProviderClassPodStatus.GetIndexer().ByIndex(podIndex, getStoreKey(pod.Name, pod.Namespace))
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			spcps, ok := item.(*secretsstorev1.SecretProviderClassPodStatus)
+			if !ok {
+				return nil, fmt.Errorf("failed to cast %T to %s", item, "secretproviderclasspodstatus")
+			}
+			// a pod can mount more than one secrets store volume and only some of them
+			// may reference the secret
+			podVol := k8sutil.SPCVolume(pod, spcps.Status.SecretProviderClassKind, spcps.Status.SecretProviderClassName)
+			if podVol == nil || podVol.CSI.NodePublishSecretRef == nil || strings.TrimSpace(podVol.CSI.NodePublishSecretRef.Name) != name {
+				continue
+			}
+			secretProviderClassPodStatuses = append(secretProviderClassPodStatuses, spcps)
+		}
+	}
+	return secretProviderClassPodStatuses, nil
+}
+
+// AddSecretProviderClassEventHandler adds the event handler to the secret provider class
+// and cluster secret provider class informers
+func (s k8sStore) AddSecretProviderClassEventHandler(handler cache.ResourceEventHandler) {
+	s.informers.SecretProviderClass.AddEventHandler(handler)
+	s.informers.ClusterSecretProviderClass.AddEventHandler(handler)
+}
+
+// AddNodePublishSecretRefEventHandler adds the event handler to the nodePublishSecretRef
+// secret informer
+func (s k8sStore) AddNodePublishSecretRefEventHandler(handler cache.ResourceEventHandler) {
+	s.informers.NodePublishSecretRefSecret.AddEventHandler(handler)
+}
+
 // GetSecretProviderClassPodStatus returns the secret provider class pod status matching key
-func (s k8sStore) GetSecretProviderClassPodStatus(key string) (*v1alpha1.SecretProviderClassPodStatus, error) {
+func (s k8sStore) GetSecretProviderClassPodStatus(key string) (*secretsstorev1.SecretProviderClassPodStatus, error) {
 	return s.listers.SecretProviderClassPodStatus.GetWithKey(key)
 }
 
+// GetClusterSecretProviderClass returns the cluster secret provider class matching name
+func (s k8sStore) GetClusterSecretProviderClass(name string) (*secretsstorev1.ClusterSecretProviderClass, error) {
+	return s.listers.ClusterSecretProviderClass.GetWithKey(name)
+}
+
+// GetNamespace returns the namespace matching name
+func (s k8sStore) GetNamespace(name string) (*v1.Namespace, error) {
+	return s.listers.Namespace.GetWithKey(name)
+}
+
 func (i *Informer) run(stopCh <-chan struct{}) error {
 	go i.Pod.Run(stopCh)
 	go i.Secret.Run(stopCh)
+	go i.ConfigMap.Run(stopCh)
 	go i.NodePublishSecretRefSecret.Run(stopCh)
 	go i.SecretProviderClass.Run(stopCh)
 	go i.SecretProviderClassPodStatus.Run(stopCh)
+	go i.ClusterSecretProviderClass.Run(stopCh)
+	go i.Namespace.Run(stopCh)
 
-	synced := []cache.InformerSynced{i.Pod.HasSynced, i.Secret.HasSynced, i.NodePublishSecretRefSecret.HasSynced, i.SecretProviderClass.HasSynced, i.SecretProviderClassPodStatus.HasSynced}
+	synced := []cache.InformerSynced{i.Pod.HasSynced, i.Secret.HasSynced, i.ConfigMap.HasSynced, i.NodePublishSecretRefSecret.HasSynced, i.SecretProviderClass.HasSynced, i.SecretProviderClassPodStatus.HasSynced,
+		i.ClusterSecretProviderClass.HasSynced, i.Namespace.HasSynced}
 	if !cache.WaitForCacheSync(stopCh, synced...) {
 		return fmt.Errorf("failed to sync informer caches")
 	}
@@ -159,13 +293,13 @@ func (i *Informer) run(stopCh <-chan struct{}) error {
 }
 
 // newPodInformer returns a pod informer configured to do filtered list watch
-// based on the spec.nodeName field
+// based on the spec.nodeName field and indexed by the nodePublishSecretRef secrets
 func newPodInformer(kubeClient kubernetes.Interface, resyncPeriod time.Duration, nodeName string) cache.SharedIndexInformer {
 	return coreInformers.NewFilteredPodInformer(
 		kubeClient,
 		v1.NamespaceAll,
 		resyncPeriod,
-		cache.Indexers{},
+		cache.Indexers{nodePublishSecretRefIndex: nodePublishSecretRefIndexFunc},
 		nodeNameFilterForPod(nodeName),
 	)
 }
@@ -181,6 +315,18 @@ func newSecretInformer(kubeClient kubernetes.Interface, resyncPeriod time.Durati
 	)
 }
 
+// newConfigMapInformer returns a configmap informer configured to do filtered list watch
+// based on the managed label
+func newConfigMapInformer(kubeClient kubernetes.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return coreInformers.NewFilteredConfigMapInformer(
+		kubeClient,
+		v1.NamespaceAll,
+		resyncPeriod,
+		cache.Indexers{},
+		managedFilterForConfigMap(),
+	)
+}
+
 // newNodePublishSecretRefSecretInformer returns a NodePublishSecretRef informer
 func newNodePublishSecretRefSecretInformer(kubeClient kubernetes.Interface, resyncPeriod time.Duration, filteredWatchSecret bool) cache.SharedIndexInformer {
 	var tweakListOptionsFunc internalinterfaces.TweakListOptionsFunc
@@ -197,13 +343,13 @@ func newNodePublishSecretRefSecretInformer(kubeClient kubernetes.Interface, resy
 }
 
 // newSPCPodStatusInformer returns a spc pod status informer configured to do filtered list watch
-// based on the node name label
+// based on the node name label and indexed by the secret provider class and the pod
 func newSPCPodStatusInformer(crdClient secretsStoreClient.Interface, resyncPeriod time.Duration, nodeName string) cache.SharedIndexInformer {
 	return secretsStoreInformers.NewFilteredSecretProviderClassPodStatusInformer(
 		crdClient,
 		v1.NamespaceAll,
 		resyncPeriod,
-		cache.Indexers{},
+		cache.Indexers{secretProviderClassIndex: spcIndexFunc, podIndex: podIndexFunc},
 		nodeNameFilterForSPCPodStatus(nodeName),
 	)
 }
@@ -218,12 +364,31 @@ func newSPCInformer(crdClient secretsStoreClient.Interface, resyncPeriod time.Du
 	)
 }
 
+// newClusterSPCInformer returns a cluster secret provider class informer
+func newClusterSPCInformer(crdClient secretsStoreClient.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return secretsStoreInformers.NewClusterSecretProviderClassInformer(
+		crdClient,
+		resyncPeriod,
+		cache.Indexers{},
+	)
+}
+
+// newNamespaceInformer returns a namespace informer used to match the namespace
+// selector of cluster secret provider classes
+func newNamespaceInformer(kubeClient kubernetes.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return coreInformers.NewNamespaceInformer(
+		kubeClient,
+		resyncPeriod,
+		cache.Indexers{},
+	)
+}
+
 // nodeNameFilterForSPCPodStatus - CRDs do not yet support field selectors. Instead of that we
 // apply labels with node name and then later use the NodeNameFilter to tweak
 // options to filter using nodename label.
 func nodeNameFilterForSPCPodStatus(nodeName string) secretsStoreInternalInterfaces.TweakListOptionsFunc {
 	return func(options *metav1.ListOptions) {
-		options.LabelSelector = fmt.Sprintf("%s=%s", v1alpha1.InternalNodeLabel, nodeName)
+		options.LabelSelector = fmt.Sprintf("%s=%s", secretsstorev1.InternalNodeLabel, nodeName)
 	}
 }
 
@@ -234,6 +399,53 @@ func nodeNameFilterForPod(nodeName string) internalinterfaces.TweakListOptionsFu
 	}
 }
 
+// spcIndexFunc indexes the spc pod status by the secret provider class it references
+func spcIndexFunc(obj interface{}) ([]string, error) {
+	spcps, ok := obj.(*secretsstorev1.SecretProviderClassPodStatus)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast %T to %s", obj, "secretproviderclasspodstatus")
+	}
+	return []string{spcIndexKey(spcps.Status.SecretProviderClassKind, spcps.Status.SecretProviderClassName, spcps.Namespace)}, nil
+}
+
+// spcIndexKey returns the <kind>/<namespace>/<name> index key for the secret provider class.
+// A ClusterSecretProviderClass isn't namespaced and its key is <kind>/<name>.
+func spcIndexKey(kind, name, namespace string) string {
+	switch kind {
+	case secretsstorev1.ClusterSecretProviderClassKind:
+		return fmt.Sprintf("%s/%s", kind, name)
+	case "":
+		kind = secretsstorev1.SecretProviderClassKind
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// podIndexFunc indexes the spc pod status by the <namespace>/<name> of the pod it belongs to
+func podIndexFunc(obj interface{}) ([]string, error) {
+	spcps, ok := obj.(*secretsstorev1.SecretProviderClassPodStatus)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast %T to %s", obj, "secretproviderclasspodstatus")
+	}
+	return []string{getStoreKey(spcps.Status.PodName, spcps.Namespace)}, nil
+}
+
+// nodePublishSecretRefIndexFunc indexes the pod by the <namespace>/<name> of the nodePublishSecretRef
+// secrets referenced in its secrets store volumes. The secret is always in the namespace of the pod.
+func nodePublishSecretRefIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast %T to %s", obj, "pod")
+	}
+	var keys []string
+	for _, vol := range pod.Spec.Volumes {
+		if vol.CSI == nil || vol.CSI.Driver != k8sutil.DriverName || vol.CSI.NodePublishSecretRef == nil {
+			continue
+		}
+		keys = append(keys, getStoreKey(strings.TrimSpace(vol.CSI.NodePublishSecretRef.Name), pod.Namespace))
+	}
+	return keys, nil
+}
+
 // getStoreKey returns key to use for GetByKey from store
 func getStoreKey(name, namespace string) string {
 	// client-go cache store uses <namespace>/<name> as key
@@ -248,6 +460,14 @@ func managedFilterForSecret() internalinterfaces.TweakListOptionsFunc {
 	}
 }
 
+// managedFilterForConfigMap returns tweak options to filter using managed label (secrets-store.csi.k8s.io/managed=true).
+// this label is configured for all configmaps created by the driver.
+func managedFilterForConfigMap() internalinterfaces.TweakListOptionsFunc {
+	return func(options *metav1.ListOptions) {
+		options.LabelSelector = fmt.Sprintf("%s=true", controllers.SecretManagedLabel)
+	}
+}
+
 // usedFilterForSecret returns tweak options to filter using used label (secrets-store.csi.k8s.io/used=true).
 // this label will need to be configured by user for NodePublishSecretRef secrets.
 func usedFilterForSecret() internalinterfaces.TweakListOptionsFunc {
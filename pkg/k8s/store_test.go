@@ -23,7 +23,8 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	"sigs.k8s.io/secrets-store-csi-driver/controllers"
 
 	"k8s.io/apimachinery/pkg/util/wait"
 
@@ -91,25 +92,25 @@ func TestListSecretProviderClassPodStatus(t *testing.T) {
 	g.Expect(err).To(HaveOccurred())
 	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
 
-	secretProviderClassPodStatusToAdd := []*v1alpha1.SecretProviderClassPodStatus{
+	secretProviderClassPodStatusToAdd := []*secretsstorev1.SecretProviderClassPodStatus{
 		{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "spcpodstatus1",
 				Namespace: "default",
-				Labels:    map[string]string{v1alpha1.InternalNodeLabel: "node1"},
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "node1"},
 			},
 		},
 		{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "spcpodstatus2",
 				Namespace: "default",
-				Labels:    map[string]string{v1alpha1.InternalNodeLabel: "node1"},
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "node1"},
 			},
 		},
 	}
 
 	for _, spcps := range secretProviderClassPodStatusToAdd {
-		_, err = crdClient.SecretsstoreV1alpha1().SecretProviderClassPodStatuses("default").Create(context.TODO(), spcps, metav1.CreateOptions{})
+		_, err = crdClient.SecretsstoreV1().SecretProviderClassPodStatuses("default").Create(context.TODO(), spcps, metav1.CreateOptions{})
 		g.Expect(err).NotTo(HaveOccurred())
 	}
 
@@ -121,6 +122,161 @@ func TestListSecretProviderClassPodStatus(t *testing.T) {
 	g.Expect(list).To(ConsistOf(secretProviderClassPodStatusToAdd))
 }
 
+func TestListSecretProviderClassPodStatusForSPC(t *testing.T) {
+	g := NewWithT(t)
+
+	kubeClient := fake.NewSimpleClientset()
+	crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+	testStore, err := New(kubeClient, crdClient, "node1", 1*time.Millisecond, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testStore.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newSPCPodStatus := func(name, namespace, kind, spcName string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "node1"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				SecretProviderClassName: spcName,
+				SecretProviderClassKind: kind,
+			},
+		}
+	}
+	secretProviderClassPodStatusToAdd := []*secretsstorev1.SecretProviderClassPodStatus{
+		newSPCPodStatus("spcpodstatus1", "default", "", "spc1"),
+		newSPCPodStatus("spcpodstatus2", "default", secretsstorev1.SecretProviderClassKind, "spc1"),
+		newSPCPodStatus("spcpodstatus3", "default", secretsstorev1.SecretProviderClassKind, "spc2"),
+		newSPCPodStatus("spcpodstatus4", "test", secretsstorev1.SecretProviderClassKind, "spc1"),
+		newSPCPodStatus("spcpodstatus5", "default", secretsstorev1.ClusterSecretProviderClassKind, "spc1"),
+		newSPCPodStatus("spcpodstatus6", "test", secretsstorev1.ClusterSecretProviderClassKind, "spc1"),
+	}
+
+	for _, spcps := range secretProviderClassPodStatusToAdd {
+		_, err = crdClient.SecretsstoreV1().SecretProviderClassPodStatuses(spcps.Namespace).Create(context.TODO(), spcps, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	waitForInformerCacheSync()
+
+	list, err := testStore.ListSecretProviderClassPodStatusForSPC(secretsstorev1.SecretProviderClassKind, "spc1", "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(list).To(ConsistOf(secretProviderClassPodStatusToAdd[0], secretProviderClassPodStatusToAdd[1]))
+
+	list, err = testStore.ListSecretProviderClassPodStatusForSPC(secretsstorev1.ClusterSecretProviderClassKind, "spc1", "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(list).To(ConsistOf(secretProviderClassPodStatusToAdd[4], secretProviderClassPodStatusToAdd[5]))
+
+	list, err = testStore.ListSecretProviderClassPodStatusForSPC(secretsstorev1.SecretProviderClassKind, "spc3", "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(list).To(BeEmpty())
+}
+
+func TestListSecretProviderClassPodStatusForNodePublishSecretRef(t *testing.T) {
+	g := NewWithT(t)
+
+	kubeClient := fake.NewSimpleClientset()
+	crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+	testStore, err := New(kubeClient, crdClient, "node1", 1*time.Millisecond, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testStore.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newVolume := func(name, spcName, secretName string) v1.Volume {
+		vol := v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				CSI: &v1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					VolumeAttributes: map[string]string{"secretProviderClass": spcName},
+				},
+			},
+		}
+		if secretName != "" {
+			vol.CSI.NodePublishSecretRef = &v1.LocalObjectReference{Name: secretName}
+		}
+		return vol
+	}
+	podsToAdd := []*v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+			Spec: v1.PodSpec{
+				NodeName: "node1",
+				Volumes:  []v1.Volume{newVolume("vol1", "spc1", "secret1"), newVolume("vol2", "spc2", "secret2")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default"},
+			Spec: v1.PodSpec{
+				NodeName: "node1",
+				Volumes:  []v1.Volume{newVolume("vol1", "spc1", "secret1")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod3", Namespace: "default"},
+			Spec: v1.PodSpec{
+				NodeName: "node1",
+				Volumes:  []v1.Volume{newVolume("vol1", "spc1", "")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "test"},
+			Spec: v1.PodSpec{
+				NodeName: "node1",
+				Volumes:  []v1.Volume{newVolume("vol1", "spc1", "secret1")},
+			},
+		},
+	}
+	for _, pod := range podsToAdd {
+		_, err = kubeClient.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	newSPCPodStatus := func(podName, namespace, spcName string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName + "-" + namespace + "-" + spcName,
+				Namespace: namespace,
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "node1"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				PodName:                 podName,
+				SecretProviderClassName: spcName,
+				SecretProviderClassKind: secretsstorev1.SecretProviderClassKind,
+			},
+		}
+	}
+	secretProviderClassPodStatusToAdd := []*secretsstorev1.SecretProviderClassPodStatus{
+		newSPCPodStatus("pod1", "default", "spc1"),
+		newSPCPodStatus("pod1", "default", "spc2"),
+		newSPCPodStatus("pod2", "default", "spc1"),
+		newSPCPodStatus("pod3", "default", "spc1"),
+		newSPCPodStatus("pod1", "test", "spc1"),
+	}
+	for _, spcps := range secretProviderClassPodStatusToAdd {
+		_, err = crdClient.SecretsstoreV1().SecretProviderClassPodStatuses(spcps.Namespace).Create(context.TODO(), spcps, metav1.CreateOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	waitForInformerCacheSync()
+
+	list, err := testStore.ListSecretProviderClassPodStatusForNodePublishSecretRef("secret1", "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(list).To(ConsistOf(secretProviderClassPodStatusToAdd[0], secretProviderClassPodStatusToAdd[2]))
+
+	list, err = testStore.ListSecretProviderClassPodStatusForNodePublishSecretRef("secret2", "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(list).To(ConsistOf(secretProviderClassPodStatusToAdd[1]))
+
+	list, err = testStore.ListSecretProviderClassPodStatusForNodePublishSecretRef("secret3", "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(list).To(BeEmpty())
+}
+
 func TestGetSecret(t *testing.T) {
 	g := NewWithT(t)
 
@@ -155,6 +311,41 @@ func TestGetSecret(t *testing.T) {
 	g.Expect(secret.Name).To(Equal("secret1"))
 }
 
+func TestGetConfigMap(t *testing.T) {
+	g := NewWithT(t)
+
+	kubeClient := fake.NewSimpleClientset()
+	crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+	testStore, err := New(kubeClient, crdClient, "node1", 1*time.Millisecond, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testStore.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Get a configmap that's not found
+	_, err = testStore.GetConfigMap("configmap1", "default")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	configMapToAdd := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "configmap1",
+			Namespace: "default",
+			Labels:    map[string]string{controllers.SecretManagedLabel: "true"},
+		},
+	}
+
+	_, err = kubeClient.CoreV1().ConfigMaps("default").Create(context.TODO(), configMapToAdd, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	waitForInformerCacheSync()
+
+	configMap, err := testStore.GetConfigMap("configmap1", "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(configMap).NotTo(BeNil())
+	g.Expect(configMap.Name).To(Equal("configmap1"))
+}
+
 func TestGetSecretProviderClass(t *testing.T) {
 	g := NewWithT(t)
 
@@ -171,14 +362,14 @@ func TestGetSecretProviderClass(t *testing.T) {
 	g.Expect(err).To(HaveOccurred())
 	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
 
-	secretProviderClassToAdd := &v1alpha1.SecretProviderClass{
+	secretProviderClassToAdd := &secretsstorev1.SecretProviderClass{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "spc1",
 			Namespace: "default",
 		},
 	}
 
-	_, err = crdClient.SecretsstoreV1alpha1().SecretProviderClasses("default").Create(context.TODO(), secretProviderClassToAdd, metav1.CreateOptions{})
+	_, err = crdClient.SecretsstoreV1().SecretProviderClasses("default").Create(context.TODO(), secretProviderClassToAdd, metav1.CreateOptions{})
 	g.Expect(err).NotTo(HaveOccurred())
 
 	waitForInformerCacheSync()
@@ -189,6 +380,73 @@ func TestGetSecretProviderClass(t *testing.T) {
 	g.Expect(spc.Name).To(Equal("spc1"))
 }
 
+func TestGetClusterSecretProviderClass(t *testing.T) {
+	g := NewWithT(t)
+
+	kubeClient := fake.NewSimpleClientset()
+	crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+	testStore, err := New(kubeClient, crdClient, "node1", 1*time.Millisecond, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testStore.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Get a cluster spc that's not found
+	_, err = testStore.GetClusterSecretProviderClass("cspc1")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	clusterSecretProviderClassToAdd := &secretsstorev1.ClusterSecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cspc1",
+		},
+	}
+
+	_, err = crdClient.SecretsstoreV1().ClusterSecretProviderClasses().Create(context.TODO(), clusterSecretProviderClassToAdd, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	waitForInformerCacheSync()
+
+	cspc, err := testStore.GetClusterSecretProviderClass("cspc1")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cspc).NotTo(BeNil())
+	g.Expect(cspc.Name).To(Equal("cspc1"))
+}
+
+func TestGetNamespace(t *testing.T) {
+	g := NewWithT(t)
+
+	kubeClient := fake.NewSimpleClientset()
+	crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+	testStore, err := New(kubeClient, crdClient, "node1", 1*time.Millisecond, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testStore.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Get a namespace that's not found
+	_, err = testStore.GetNamespace("team-a")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	namespaceToAdd := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{"team": "a"},
+		},
+	}
+
+	_, err = kubeClient.CoreV1().Namespaces().Create(context.TODO(), namespaceToAdd, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	waitForInformerCacheSync()
+
+	namespace, err := testStore.GetNamespace("team-a")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(namespace).NotTo(BeNil())
+	g.Expect(namespace.Labels).To(Equal(map[string]string{"team": "a"}))
+}
+
 // waitForInformerCacheSync waits for the test informers cache to be synced
 func waitForInformerCacheSync() {
 	time.Sleep(200 * time.Millisecond)
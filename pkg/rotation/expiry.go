@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/spcpsutil"
+)
+
+const (
+	// expiryRetryInterval is the min interval between the rotations of a spc pod status to refresh
+	// its expiring objects, so an object the provider doesn't refresh isn't rotated in a tight loop
+	expiryRetryInterval = time.Minute
+
+	objectNearExpiryReason = "ObjectNearExpiry"
+	objectExpiredReason    = "ObjectExpired"
+)
+
+// expiryState is the expiry state of a mounted object reported by metrics and events
+type expiryState string
+
+const (
+	// expiryStateNearExpiry is the state of an object that is more than halfway between its
+	// refresh time and its expiry, so the refresh at the refresh time didn't succeed
+	expiryStateNearExpiry expiryState = "NearExpiry"
+	// expiryStateExpired is the state of an object that is past its expiry
+	expiryStateExpired expiryState = "Expired"
+)
+
+// expiringObject is a mounted object near or past expiry
+type expiringObject struct {
+	object secretsstorev1.SecretProviderClassObject
+	state  expiryState
+}
+
+// objectExpiryState returns the expiry state of the object with the refresh time, or an empty
+// state if the object isn't near expiry yet.
+func objectExpiryState(obj secretsstorev1.SecretProviderClassObject, refreshAt, now time.Time) expiryState {
+	expiresAt := obj.ExpiresAt.Time
+	if !now.Before(expiresAt) {
+		return expiryStateExpired
+	}
+	if !now.Before(refreshAt.Add(expiresAt.Sub(refreshAt) / 2)) {
+		return expiryStateNearExpiry
+	}
+	return ""
+}
+
+// expiryTracker tracks the rotations of the spc pod statuses to refresh their expiring objects
+// and the reported expiry states of the objects. It's only used by the Run loop.
+type expiryTracker struct {
+	// refreshed is the last time each spc pod status was enqueued to refresh its objects,
+	// keyed by <namespace>/<name>
+	refreshed map[string]time.Time
+	// reported is the last reported expiry state of the object versions of each spc pod
+	// status, keyed by <namespace>/<name> and object id
+	reported map[string]map[string]string
+}
+
+func newExpiryTracker() *expiryTracker {
+	return &expiryTracker{
+		refreshed: make(map[string]time.Time),
+		reported:  make(map[string]map[string]string),
+	}
+}
+
+// refresh returns true and records the refresh if the spc pod status wasn't enqueued to refresh
+// its objects within the expiryRetryInterval.
+func (e *expiryTracker) refresh(key string, now time.Time) bool {
+	if last, ok := e.refreshed[key]; ok && now.Sub(last) < expiryRetryInterval {
+		return false
+	}
+	e.refreshed[key] = now
+	return true
+}
+
+// report records the expiry states of the object versions of the spc pod status, keyed by object
+// id, and returns the ids whose state wasn't reported yet.
+func (e *expiryTracker) report(key string, states map[string]string) []string {
+	var changed []string
+	for id, state := range states {
+		if e.reported[key][id] != state {
+			changed = append(changed, id)
+		}
+	}
+	if len(states) == 0 {
+		delete(e.reported, key)
+	} else {
+		e.reported[key] = states
+	}
+	return changed
+}
+
+// forget forgets the spc pod statuses that aren't in keys.
+func (e *expiryTracker) forget(keys map[string]bool) {
+	for key := range e.refreshed {
+		if !keys[key] {
+			delete(e.refreshed, key)
+		}
+	}
+	for key := range e.reported {
+		if !keys[key] {
+			delete(e.reported, key)
+		}
+	}
+}
+
+// syncExpiry enqueues the spc pod statuses with an object due for refresh, once the configured
+// fraction of the lifetime of its version elapsed. The refresh doesn't wait for the rotation interval
+// or window and isn't skipped for the spc pod statuses watched with a provider stream, so the objects
+// are refreshed before they expire. The objects near or past expiry are reported by the metrics
+// and by an event on the pod.
+func (r *Reconciler) syncExpiry(now time.Time) {
+	spcpsList, err := r.store.ListSecretProviderClassPodStatus()
+	if err != nil {
+		klog.ErrorS(err, "failed to list secret provider class pod status for node", "controller", "rotation")
+		return
+	}
+
+	nearExpiry, expired := make(map[string]int64), make(map[string]int64)
+	keys := make(map[string]bool, len(spcpsList))
+	for _, spcps := range spcpsList {
+		key, err := cache.MetaNamespaceKeyFunc(spcps)
+		if err != nil {
+			continue
+		}
+		keys[key] = true
+
+		var due, expiring bool
+		// expiringObjects are the objects near or past expiry, keyed by id
+		expiringObjects := make(map[string]expiringObject)
+		states := make(map[string]string)
+		for _, obj := range spcps.Status.Objects {
+			refreshAt, ok := spcpsutil.RefreshTime(obj, r.expiryFraction)
+			if !ok {
+				continue
+			}
+			expiring = true
+			due = due || !now.Before(refreshAt)
+			if state := objectExpiryState(obj, refreshAt, now); state != "" {
+				expiringObjects[obj.ID] = expiringObject{object: obj, state: state}
+				states[obj.ID] = fmt.Sprintf("%s/%s/%s", obj.Version, obj.ExpiresAt.UTC().Format(time.RFC3339), state)
+			}
+		}
+		if !expiring {
+			continue
+		}
+
+		var providerName string
+		if spc, err := r.getSecretProviderClass(spcps); err == nil {
+			providerName = string(spc.Spec.Provider)
+		}
+		// the gauges of the providers with expiring objects are reported even if none is near
+		// expiry, so they are reset once the objects are refreshed
+		if _, ok := nearExpiry[providerName]; !ok {
+			nearExpiry[providerName], expired[providerName] = 0, 0
+		}
+		for _, o := range expiringObjects {
+			if o.state == expiryStateExpired {
+				expired[providerName]++
+			} else {
+				nearExpiry[providerName]++
+			}
+		}
+		if changed := r.expiry.report(key, states); len(changed) > 0 {
+			r.reportObjectExpiry(spcps, expiringObjects, changed)
+		}
+
+		// the rotation of a throttled spc pod status is already retried with a backoff
+		if !due || !r.rotationPolicy(spcps).enabled || r.throttleBackoff.NumRequeues(key) > 0 {
+			continue
+		}
+		if r.expiry.refresh(key, now) {
+			klog.V(3).InfoS("object due for refresh before expiry, enqueued spc pod status for rotation", "spcps", klog.KObj(spcps), "controller", "rotation")
+			r.queue.Add(key)
+		}
+	}
+	r.expiry.forget(keys)
+	r.reporter.reportObjectExpiry(nearExpiry, expired)
+}
+
+// reportObjectExpiry generates an event on the pod for each of the objects near or past expiry
+// with the ids.
+func (r *Reconciler) reportObjectExpiry(spcps *secretsstorev1.SecretProviderClassPodStatus, objects map[string]expiringObject, ids []string) {
+	pod, err := r.store.GetPod(spcps.Status.PodName, spcps.Namespace)
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		obj, state := objects[id].object, objects[id].state
+		expiresAt := obj.ExpiresAt.UTC().Format(time.RFC3339)
+		if state == expiryStateExpired {
+			r.generateEvent(pod, v1.EventTypeWarning, objectExpiredReason, fmt.Sprintf("object %s version %s mounted using secret provider class %s expired at %s", obj.ID, obj.Version, spcps.Status.SecretProviderClassName, expiresAt))
+			continue
+		}
+		r.generateEvent(pod, v1.EventTypeWarning, objectNearExpiryReason, fmt.Sprintf("object %s version %s mounted using secret provider class %s expires at %s and wasn't refreshed yet", obj.ID, obj.Version, spcps.Status.SecretProviderClassName, expiresAt))
+	}
+}
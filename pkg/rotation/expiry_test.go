@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	secretsStoreFakeClient "sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned/fake"
+	providerfake "sigs.k8s.io/secrets-store-csi-driver/provider/fake"
+)
+
+func newExpiryTestPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", UID: types.UID("foo")},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{{
+				Name: "csi-volume",
+				VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+				}},
+			}},
+		},
+	}
+}
+
+func newExpiryTestSPC() *secretsstorev1.SecretProviderClass {
+	return &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+		Spec:       secretsstorev1.SecretProviderClassSpec{Provider: "provider1"},
+	}
+}
+
+func TestSyncExpiry(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	now := time.Now()
+	fetchedAt, expiresAt := metav1.NewTime(now.Add(-time.Hour)), metav1.NewTime(now.Add(time.Hour))
+	spcps := &secretsstorev1.SecretProviderClassPodStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1-default-spc1",
+			Namespace: "default",
+			Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+		},
+		Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+			PodName:                 "pod1",
+			SecretProviderClassName: "spc1",
+			Objects: []secretsstorev1.SecretProviderClassObject{
+				{ID: "secret/object1", Version: "v1", ExpiresAt: &expiresAt, FetchedAt: &fetchedAt},
+				{ID: "secret/object2", Version: "v1"},
+			},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(newExpiryTestPod())
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(spcps, newExpiryTestSPC())
+
+	// the rotation interval is longer than the lifetime of the object
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 24*time.Hour, getTempTestDir(t), false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+
+	popQueue := func() []string {
+		var keys []string
+		for testReconciler.queue.Len() > 0 {
+			key, _ := testReconciler.queue.Get()
+			testReconciler.queue.Done(key)
+			keys = append(keys, key.(string))
+		}
+		return keys
+	}
+	popEvents := func() []string {
+		var events []string
+		for len(fakeRecorder.Events) > 0 {
+			events = append(events, <-fakeRecorder.Events)
+		}
+		return events
+	}
+
+	// the object is refreshed at 3/4 of its lifetime, 30m from now
+	testReconciler.syncExpiry(now)
+	g.Expect(popQueue()).To(BeEmpty())
+	g.Expect(popEvents()).To(BeEmpty())
+
+	testReconciler.syncExpiry(now.Add(31 * time.Minute))
+	g.Expect(popQueue()).To(ConsistOf("default/pod1-default-spc1"))
+	g.Expect(popEvents()).To(BeEmpty())
+
+	// the refresh isn't retried right away
+	testReconciler.syncExpiry(now.Add(31*time.Minute + scheduleSyncInterval))
+	g.Expect(popQueue()).To(BeEmpty())
+
+	// the object is near expiry halfway between the refresh time and the expiry
+	testReconciler.syncExpiry(now.Add(46 * time.Minute))
+	g.Expect(popQueue()).To(ConsistOf("default/pod1-default-spc1"))
+	events := popEvents()
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(HavePrefix(v1.EventTypeWarning + " " + objectNearExpiryReason))
+
+	// the event is generated once
+	testReconciler.syncExpiry(now.Add(46*time.Minute + scheduleSyncInterval))
+	g.Expect(popEvents()).To(BeEmpty())
+
+	testReconciler.syncExpiry(now.Add(61 * time.Minute))
+	events = popEvents()
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(HavePrefix(v1.EventTypeWarning + " " + objectExpiredReason))
+	g.Expect(strings.Contains(events[0], "secret/object1")).To(BeTrue())
+	popQueue()
+
+	// the spc pod status is forgotten once deleted
+	err = crdClient.SecretsstoreV1().SecretProviderClassPodStatuses("default").Delete(context.TODO(), spcps.Name, metav1.DeleteOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(func() int {
+		testReconciler.syncExpiry(now.Add(2 * time.Hour))
+		return len(testReconciler.expiry.reported) + len(testReconciler.expiry.refreshed)
+	}, 5*time.Second, 100*time.Millisecond).Should(Equal(0))
+}
+
+func TestReconcileObjectExpiry(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	now := time.Now()
+	fetchedAt, expiresAt := metav1.NewTime(now.Add(-time.Hour)), metav1.NewTime(now.Add(time.Hour))
+	spcps := &secretsstorev1.SecretProviderClassPodStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1-default-spc1",
+			Namespace: "default",
+			Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+		},
+		Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+			PodName:                 "pod1",
+			SecretProviderClassName: "spc1",
+			TargetPath:              getTestTargetPath(t, "foo", "csi-volume"),
+			Objects: []secretsstorev1.SecretProviderClassObject{
+				{ID: "secret/object1", Version: "v1", ExpiresAt: &expiresAt, FetchedAt: &fetchedAt},
+			},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(newExpiryTestPod())
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(spcps, newExpiryTestSPC())
+
+	socketPath := getTempTestDir(t)
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, socketPath, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	serverEndpoint := fmt.Sprintf("%s/%s.sock", socketPath, "provider1")
+	defer os.Remove(serverEndpoint)
+
+	server, err := providerfake.NewMocKCSIProviderServer(serverEndpoint)
+	g.Expect(err).NotTo(HaveOccurred())
+	server.SetObjects(map[string]string{"secret/object1": "v1"})
+	server.SetObjectExpiry("secret/object1", expiresAt.Time, time.Time{})
+	server.Start()
+	defer server.Stop()
+
+	getSPCPodStatus := func() *secretsstorev1.SecretProviderClassPodStatus {
+		updated, err := crdClient.SecretsstoreV1().SecretProviderClassPodStatuses("default").Get(context.TODO(), spcps.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return updated
+	}
+
+	// the fetch time is kept while the expiry doesn't change
+	err = testReconciler.reconcile(context.TODO(), spcps)
+	g.Expect(err).NotTo(HaveOccurred())
+	updated := getSPCPodStatus()
+	g.Expect(updated.Status.Objects).To(HaveLen(1))
+	g.Expect(updated.Status.Objects[0].FetchedAt.Time.Equal(fetchedAt.Time)).To(BeTrue())
+
+	// the renewed expiry of the same version is stored without rotating the pod
+	renewedExpiresAt := now.Add(2 * time.Hour)
+	server.SetObjectExpiry("secret/object1", renewedExpiresAt, time.Time{})
+	err = testReconciler.reconcile(context.TODO(), updated)
+	g.Expect(err).NotTo(HaveOccurred())
+	updated = getSPCPodStatus()
+	g.Expect(updated.Status.Objects).To(HaveLen(1))
+	g.Expect(updated.Status.Objects[0].Version).To(Equal("v1"))
+	g.Expect(updated.Status.Objects[0].ExpiresAt.Time.Equal(renewedExpiresAt)).To(BeTrue())
+	g.Expect(updated.Status.Objects[0].FetchedAt.After(fetchedAt.Time)).To(BeTrue())
+	g.Expect(updated.Status.LastRotationTime).To(BeNil())
+	g.Expect(updated.Status.RotationHistory).To(BeEmpty())
+	for len(fakeRecorder.Events) > 0 {
+		g.Expect(<-fakeRecorder.Events).NotTo(ContainSubstring(mountRotationCompleteReason))
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import "sync"
+
+// providerLimiter caps the number of concurrent rotations per provider so a slow
+// provider can't occupy all the rotation workers and starve the other providers.
+type providerLimiter struct {
+	// maxPerProvider is the max number of concurrent rotations per provider,
+	// 0 means there is no limit other than the number of workers.
+	maxPerProvider int
+
+	mutex    sync.Mutex
+	inFlight map[string]int
+}
+
+func newProviderLimiter(maxPerProvider int) *providerLimiter {
+	return &providerLimiter{
+		maxPerProvider: maxPerProvider,
+		inFlight:       make(map[string]int),
+	}
+}
+
+// tryAcquire reserves a rotation slot for the provider. It returns false without
+// blocking if the provider already has the max number of rotations in flight.
+func (l *providerLimiter) tryAcquire(provider string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.maxPerProvider > 0 && l.inFlight[provider] >= l.maxPerProvider {
+		return false
+	}
+	l.inFlight[provider]++
+	return true
+}
+
+// release frees a rotation slot reserved with tryAcquire.
+func (l *providerLimiter) release(provider string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.inFlight[provider] <= 1 {
+		delete(l.inFlight, provider)
+		return
+	}
+	l.inFlight[provider]--
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestProviderLimiter(t *testing.T) {
+	g := NewWithT(t)
+
+	l := newProviderLimiter(2)
+	g.Expect(l.tryAcquire("provider1")).To(BeTrue())
+	g.Expect(l.tryAcquire("provider1")).To(BeTrue())
+	g.Expect(l.tryAcquire("provider1")).To(BeFalse())
+	// other providers are not affected by provider1 being at the limit
+	g.Expect(l.tryAcquire("provider2")).To(BeTrue())
+
+	l.release("provider1")
+	g.Expect(l.tryAcquire("provider1")).To(BeTrue())
+
+	l.release("provider1")
+	l.release("provider1")
+	l.release("provider2")
+	g.Expect(l.inFlight).To(BeEmpty())
+}
+
+func TestProviderLimiterNoLimit(t *testing.T) {
+	g := NewWithT(t)
+
+	l := newProviderLimiter(0)
+	for i := 0; i < 100; i++ {
+		g.Expect(l.tryAcquire("provider1")).To(BeTrue())
+	}
+	g.Expect(l.inFlight["provider1"]).To(Equal(100))
+}
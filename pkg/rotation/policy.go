@@ -0,0 +1,224 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/cronutil"
+)
+
+const (
+	// RotationEnabledAnnotation is set on a pod to true or false to override if rotation
+	// is enabled in the rotation policy of the secret provider class
+	RotationEnabledAnnotation = "secrets-store.csi.k8s.io/rotation-enabled"
+	// RotationIntervalAnnotation is set on a pod to a duration, e.g. 1h, to override the
+	// interval in the rotation policy of the secret provider class
+	RotationIntervalAnnotation = "secrets-store.csi.k8s.io/rotation-interval"
+	// RotationWindowsAnnotation is set on a pod to a JSON list of windows, e.g.
+	// [{"schedule":"0 2 * * *","duration":"2h"}], to override the windows in the rotation
+	// policy of the secret provider class
+	RotationWindowsAnnotation = "secrets-store.csi.k8s.io/rotation-windows"
+	// PostRotationActionAnnotation is set on a pod to None, Rollout or Evict to override the
+	// post rotation action in the rotation policy of the secret provider class
+	PostRotationActionAnnotation = "secrets-store.csi.k8s.io/post-rotation-action"
+
+	// RotationEnabledAttribute is the volume attribute that overrides if rotation is enabled
+	RotationEnabledAttribute = "rotationEnabled"
+	// RotationIntervalAttribute is the volume attribute that overrides the rotation interval
+	RotationIntervalAttribute = "rotationInterval"
+	// RotationWindowsAttribute is the volume attribute that overrides the rotation windows
+	RotationWindowsAttribute = "rotationWindows"
+	// PostRotationActionAttribute is the volume attribute that overrides the post rotation action
+	PostRotationActionAttribute = "postRotationAction"
+
+	// defaultPostRotationActionInterval is the default min interval between post rotation
+	// actions on the same workload
+	defaultPostRotationActionInterval = 5 * time.Minute
+)
+
+// rotationPolicy is the effective rotation policy of a pod volume
+type rotationPolicy struct {
+	enabled  bool
+	interval time.Duration
+	windows  []rotationWindow
+	// action is taken on the workload of the pod after a rotation updates its mounted contents
+	action secretsstorev1.PostRotationActionType
+	// actionInterval is the min interval between actions on the same workload
+	actionInterval time.Duration
+}
+
+// rotationWindow is a parsed rotation window
+type rotationWindow struct {
+	schedule *cronutil.Schedule
+	duration time.Duration
+}
+
+// rotationPolicyOverrides are the rotation policy fields set on a pod
+type rotationPolicyOverrides struct {
+	enabled, interval, windows, action string
+}
+
+// newRotationPolicy returns the effective rotation policy of a pod volume. The volume attributes
+// take precedence over the pod annotations, which take precedence over the rotation policy of the
+// secret provider class. By default, rotation is enabled at the poll interval at any time. An
+// interval shorter than minInterval is raised to minInterval, as the pod annotations and volume
+// attributes are set by the app teams and must not make the driver call the provider too often.
+func newRotationPolicy(spcPolicy *secretsstorev1.RotationPolicy, annotations, attributes map[string]string, pollInterval, minInterval time.Duration) (*rotationPolicy, error) {
+	policy := &rotationPolicy{
+		enabled:        true,
+		interval:       pollInterval,
+		action:         secretsstorev1.PostRotationActionNone,
+		actionInterval: defaultPostRotationActionInterval,
+	}
+	var windows []secretsstorev1.RotationWindow
+	if spcPolicy != nil {
+		if spcPolicy.Enabled != nil {
+			policy.enabled = *spcPolicy.Enabled
+		}
+		if spcPolicy.Interval != nil {
+			policy.interval = spcPolicy.Interval.Duration
+		}
+		windows = spcPolicy.Windows
+		if spcPolicy.PostRotationAction != nil {
+			policy.action = spcPolicy.PostRotationAction.Type
+			if spcPolicy.PostRotationAction.MinInterval != nil {
+				policy.actionInterval = spcPolicy.PostRotationAction.MinInterval.Duration
+			}
+		}
+	}
+
+	for _, overrides := range []rotationPolicyOverrides{
+		{enabled: annotations[RotationEnabledAnnotation], interval: annotations[RotationIntervalAnnotation], windows: annotations[RotationWindowsAnnotation], action: annotations[PostRotationActionAnnotation]},
+		{enabled: attributes[RotationEnabledAttribute], interval: attributes[RotationIntervalAttribute], windows: attributes[RotationWindowsAttribute], action: attributes[PostRotationActionAttribute]},
+	} {
+		if value := strings.TrimSpace(overrides.enabled); len(value) > 0 {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rotation enabled %q, err: %+v", value, err)
+			}
+			policy.enabled = enabled
+		}
+		if value := strings.TrimSpace(overrides.interval); len(value) > 0 {
+			interval, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rotation interval %q, err: %+v", value, err)
+			}
+			policy.interval = interval
+		}
+		if value := strings.TrimSpace(overrides.windows); len(value) > 0 {
+			windows = nil
+			if err := json.Unmarshal([]byte(value), &windows); err != nil {
+				return nil, fmt.Errorf("invalid rotation windows %q, err: %+v", value, err)
+			}
+		}
+		if value := strings.TrimSpace(overrides.action); len(value) > 0 {
+			policy.action = secretsstorev1.PostRotationActionType(value)
+		}
+	}
+
+	switch policy.action {
+	case "":
+		policy.action = secretsstorev1.PostRotationActionNone
+	case secretsstorev1.PostRotationActionNone, secretsstorev1.PostRotationActionRollout, secretsstorev1.PostRotationActionEvict:
+	default:
+		return nil, fmt.Errorf("invalid post rotation action %q, must be None, Rollout or Evict", policy.action)
+	}
+	if policy.actionInterval < 0 {
+		return nil, fmt.Errorf("invalid post rotation action min interval %s, must not be negative", policy.actionInterval)
+	}
+
+	if policy.interval <= 0 {
+		return nil, fmt.Errorf("invalid rotation interval %s, must be positive", policy.interval)
+	}
+	if policy.interval < minInterval {
+		policy.interval = minInterval
+	}
+	for _, window := range windows {
+		schedule, err := cronutil.Parse(window.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		if window.Duration.Duration <= 0 {
+			return nil, fmt.Errorf("invalid rotation window duration %s, must be positive", window.Duration.Duration)
+		}
+		policy.windows = append(policy.windows, rotationWindow{schedule: schedule, duration: window.Duration.Duration})
+	}
+	return policy, nil
+}
+
+// inWindow returns true if now is in one of the rotation windows or if there are no windows
+func (p *rotationPolicy) inWindow(now time.Time) bool {
+	if len(p.windows) == 0 {
+		return true
+	}
+	now = now.UTC()
+	for _, window := range p.windows {
+		// the window is open if it started in (now - duration, now]
+		start := window.schedule.Next(now.Add(-window.duration))
+		if !start.IsZero() && !start.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextDue returns when the next rotation is due after a rotation at last. The interval is
+// jittered by up to jitterFactor * interval and the time is moved to the next rotation window
+// if it isn't in one. If rotation is disabled, the policy is checked again after the interval.
+func (p *rotationPolicy) nextDue(last time.Time, jitterFactor float64) time.Time {
+	if !p.enabled {
+		return last.Add(p.interval)
+	}
+	return p.nextInWindow(last.Add(jitter(p.interval, jitterFactor)), jitterFactor)
+}
+
+// nextInWindow returns t if it's in a rotation window. Otherwise it returns the start of the
+// next rotation window plus up to jitterFactor * window duration, so the spc pod statuses due
+// in the same window don't all start rotating at once.
+func (p *rotationPolicy) nextInWindow(t time.Time, jitterFactor float64) time.Time {
+	if p.inWindow(t) {
+		return t
+	}
+	var next time.Time
+	var duration time.Duration
+	for _, window := range p.windows {
+		start := window.schedule.Next(t.UTC())
+		if !start.IsZero() && (next.IsZero() || start.Before(next)) {
+			next, duration = start, window.duration
+		}
+	}
+	if next.IsZero() {
+		// none of the windows open in the foreseeable future
+		return t
+	}
+	return next.Add(time.Duration(rand.Float64() * jitterFactor * float64(duration))) // #nosec
+}
+
+// jitter returns the duration plus a random duration up to jitterFactor * duration
+func jitter(duration time.Duration, jitterFactor float64) time.Duration {
+	if jitterFactor <= 0 {
+		return duration
+	}
+	return duration + time.Duration(rand.Float64()*jitterFactor*float64(duration)) // #nosec
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestNewRotationPolicy(t *testing.T) {
+	disabled := false
+	spcPolicy := &secretsstorev1.RotationPolicy{
+		Enabled:  &disabled,
+		Interval: &metav1.Duration{Duration: time.Hour},
+		Windows:  []secretsstorev1.RotationWindow{{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}}},
+		PostRotationAction: &secretsstorev1.PostRotationAction{
+			Type:        secretsstorev1.PostRotationActionRollout,
+			MinInterval: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		spcPolicy        *secretsstorev1.RotationPolicy
+		annotations      map[string]string
+		attributes       map[string]string
+		expectedErr      bool
+		expectedEnabled  bool
+		expectedInterval time.Duration
+		expectedWindows  int
+		expectedAction   secretsstorev1.PostRotationActionType
+	}{
+		{
+			name:             "default policy",
+			expectedEnabled:  true,
+			expectedInterval: 2 * time.Minute,
+			expectedAction:   secretsstorev1.PostRotationActionNone,
+		},
+		{
+			name:             "secret provider class policy",
+			spcPolicy:        spcPolicy,
+			expectedEnabled:  false,
+			expectedInterval: time.Hour,
+			expectedWindows:  1,
+			expectedAction:   secretsstorev1.PostRotationActionRollout,
+		},
+		{
+			name:      "pod annotations override the secret provider class policy",
+			spcPolicy: spcPolicy,
+			annotations: map[string]string{
+				RotationEnabledAnnotation:    "true",
+				RotationIntervalAnnotation:   "10m",
+				RotationWindowsAnnotation:    "[]",
+				PostRotationActionAnnotation: "Evict",
+			},
+			expectedEnabled:  true,
+			expectedInterval: 10 * time.Minute,
+			expectedAction:   secretsstorev1.PostRotationActionEvict,
+		},
+		{
+			name:      "volume attributes override the pod annotations",
+			spcPolicy: spcPolicy,
+			annotations: map[string]string{
+				RotationEnabledAnnotation:  "true",
+				RotationIntervalAnnotation: "10m",
+			},
+			attributes: map[string]string{
+				RotationIntervalAttribute:   "30m",
+				RotationWindowsAttribute:    `[{"schedule":"0 2 * * 1-5","duration":"1h"},{"schedule":"0 4 * * 0","duration":"4h"}]`,
+				PostRotationActionAttribute: "None",
+			},
+			expectedEnabled:  true,
+			expectedInterval: 30 * time.Minute,
+			expectedWindows:  2,
+			expectedAction:   secretsstorev1.PostRotationActionNone,
+		},
+		{
+			name:             "interval overrides are raised to the min interval",
+			spcPolicy:        spcPolicy,
+			annotations:      map[string]string{RotationIntervalAnnotation: "1s"},
+			expectedEnabled:  false,
+			expectedInterval: time.Minute,
+			expectedWindows:  1,
+			expectedAction:   secretsstorev1.PostRotationActionRollout,
+		},
+		{
+			name:        "invalid enabled",
+			annotations: map[string]string{RotationEnabledAnnotation: "yes please"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid interval",
+			attributes:  map[string]string{RotationIntervalAttribute: "-1h"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid windows",
+			annotations: map[string]string{RotationWindowsAnnotation: `[{"schedule":"0 2 * *","duration":"1h"}]`},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid post rotation action",
+			annotations: map[string]string{PostRotationActionAnnotation: "Restart"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			policy, err := newRotationPolicy(test.spcPolicy, test.annotations, test.attributes, 2*time.Minute, time.Minute)
+			if test.expectedErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(policy.enabled).To(Equal(test.expectedEnabled))
+			g.Expect(policy.interval).To(Equal(test.expectedInterval))
+			g.Expect(policy.windows).To(HaveLen(test.expectedWindows))
+			g.Expect(policy.action).To(Equal(test.expectedAction))
+		})
+	}
+}
+
+func TestRotationPolicyInWindow(t *testing.T) {
+	g := NewWithT(t)
+
+	// 2021-04-01 is a Thursday
+	now := time.Date(2021, 4, 1, 3, 0, 0, 0, time.UTC)
+
+	policy, err := newRotationPolicy(nil, nil, nil, time.Hour, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy.inWindow(now)).To(BeTrue())
+
+	// window from 2am to 4am on weekdays
+	policy, err = newRotationPolicy(&secretsstorev1.RotationPolicy{
+		Windows: []secretsstorev1.RotationWindow{{Schedule: "0 2 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}}},
+	}, nil, nil, time.Hour, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy.inWindow(now)).To(BeTrue())
+	g.Expect(policy.inWindow(now.Add(59 * time.Minute))).To(BeTrue())
+	g.Expect(policy.inWindow(now.Add(time.Hour))).To(BeFalse())
+	g.Expect(policy.inWindow(now.Add(-time.Hour))).To(BeTrue())
+	g.Expect(policy.inWindow(now.Add(-61 * time.Minute))).To(BeFalse())
+	// saturday
+	g.Expect(policy.inWindow(now.Add(48 * time.Hour))).To(BeFalse())
+}
+
+func TestRotationPolicyNextDue(t *testing.T) {
+	g := NewWithT(t)
+
+	// 2021-04-01 is a Thursday
+	now := time.Date(2021, 4, 1, 3, 0, 0, 0, time.UTC)
+
+	policy, err := newRotationPolicy(nil, nil, nil, time.Hour, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy.nextDue(now, 0)).To(Equal(now.Add(time.Hour)))
+	for i := 0; i < 100; i++ {
+		next := policy.nextDue(now, 0.5)
+		g.Expect(next).To(BeTemporally(">=", now.Add(time.Hour)))
+		g.Expect(next).To(BeTemporally("<", now.Add(90*time.Minute)))
+	}
+
+	// disabled policies are checked again after the interval
+	policy, err = newRotationPolicy(nil, map[string]string{RotationEnabledAnnotation: "false"}, nil, time.Hour, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy.nextDue(now, 0.5)).To(Equal(now.Add(time.Hour)))
+
+	// window from 2am to 4am on weekdays
+	policy, err = newRotationPolicy(&secretsstorev1.RotationPolicy{
+		Windows: []secretsstorev1.RotationWindow{{Schedule: "0 2 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}}},
+	}, nil, nil, time.Hour, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy.nextDue(now.Add(-30*time.Minute), 0)).To(Equal(now.Add(30 * time.Minute)))
+	// the window closes before the interval elapses, so the next rotation is at the start of the next window
+	friday := time.Date(2021, 4, 2, 2, 0, 0, 0, time.UTC)
+	g.Expect(policy.nextDue(now, 0)).To(Equal(friday))
+	for i := 0; i < 100; i++ {
+		next := policy.nextDue(now, 0.5)
+		g.Expect(next).To(BeTemporally(">=", friday))
+		g.Expect(next).To(BeTemporally("<", friday.Add(time.Hour)))
+	}
+	// no windows on the weekend
+	g.Expect(policy.nextDue(friday.Add(90*time.Minute), 0)).To(Equal(time.Date(2021, 4, 5, 2, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
+)
+
+const (
+	// RotationHashAnnotationPrefix is the prefix of the pod template annotation set to the hash of
+	// the rotated object versions to roll out a workload. The suffix is a hash of the secret
+	// provider class kind and name, so each secret provider class used by the workload has its own
+	// annotation.
+	RotationHashAnnotationPrefix = "secrets-store.csi.k8s.io/rotation-hash-"
+	// LastPostRotationActionAnnotation is set on a workload to the last time a post rotation action
+	// was taken on it, to rate limit the actions across all the nodes
+	LastPostRotationActionAnnotation = "secrets-store.csi.k8s.io/last-post-rotation-action"
+
+	postRotationRolloutReason      = "PostRotationRollout"
+	postRotationEvictionReason     = "PostRotationEviction"
+	postRotationActionFailedReason = "PostRotationActionFailed"
+)
+
+// errRateLimited is returned when the post rotation action was taken on the workload less than
+// the min interval ago
+var errRateLimited = errors.New("post rotation action rate limited")
+
+// workload is the controller of a pod that the post rotation actions are taken on
+type workload struct {
+	kind     string
+	obj      metav1.Object
+	template *v1.PodTemplateSpec
+}
+
+func (w *workload) String() string {
+	return fmt.Sprintf("%s %s/%s", w.kind, w.obj.GetNamespace(), w.obj.GetName())
+}
+
+// runPostRotationAction takes the post rotation action in the rotation policy of the pod after
+// a rotation. The action is taken if the rotation updated the object versions, or if the action
+// failed after a previous rotation, e.g. because it was rate limited. The returned change records
+// the result in the PostRotationActionSucceeded condition of the spc pod status, and is nil if no
+// action was taken.
+func (r *Reconciler) runPostRotationAction(ctx context.Context, pod *v1.Pod, spcps *secretsstorev1.SecretProviderClassPodStatus, rotated bool) statusChange {
+	policy := r.rotationPolicy(spcps)
+	if policy.action == secretsstorev1.PostRotationActionNone {
+		return nil
+	}
+	if !rotated && !meta.IsStatusConditionFalse(spcps.Status.Conditions, secretsstorev1.ConditionTypePostRotationActionSucceeded) {
+		return nil
+	}
+
+	var reason string
+	var err error
+	switch policy.action {
+	case secretsstorev1.PostRotationActionRollout:
+		reason, err = r.rolloutWorkload(ctx, pod, spcps, policy.actionInterval)
+	case secretsstorev1.PostRotationActionEvict:
+		reason, err = r.evictPod(ctx, pod, policy.actionInterval)
+	}
+	if err != nil {
+		if reason != internalerrors.PostRotationActionRateLimited {
+			r.generateEvent(pod, v1.EventTypeWarning, postRotationActionFailedReason, err.Error())
+		}
+		klog.InfoS("post rotation action not taken", "action", policy.action, "reason", reason, "err", err.Error(), "pod", klog.KObj(pod), "controller", "rotation")
+		return conditionChange(secretsstorev1.ConditionTypePostRotationActionSucceeded, metav1.ConditionFalse, reason, err.Error())
+	}
+	return conditionChange(secretsstorev1.ConditionTypePostRotationActionSucceeded, metav1.ConditionTrue, reason, "")
+}
+
+// rolloutWorkload rolls out the workload of the pod by setting the rotation hash annotation of the
+// secret provider class in its pod template. The workload isn't updated again if another pod of
+// the workload already set the same hash.
+func (r *Reconciler) rolloutWorkload(ctx context.Context, pod *v1.Pod, spcps *secretsstorev1.SecretProviderClassPodStatus, minInterval time.Duration) (string, error) {
+	hashKey := rotationHashAnnotation(spcps)
+	hash := objectVersionsHash(spcps.Status.Objects)
+
+	var w *workload
+	var rolledOut bool
+	updateFn := func() (bool, error) {
+		var err error
+		if w, err = r.getWorkload(ctx, pod); err != nil {
+			return false, err
+		}
+		// a replicaset that isn't controlled by a deployment doesn't replace its pods when its
+		// pod template changes, so there is nothing to roll out
+		if w != nil && w.kind == "ReplicaSet" {
+			w = nil
+		}
+		if w == nil {
+			return true, nil
+		}
+		if w.template.Annotations[hashKey] == hash {
+			return true, nil
+		}
+		if err = r.updateWorkload(ctx, w, minInterval, map[string]string{hashKey: hash}); err != nil {
+			if apierrors.IsConflict(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		rolledOut = true
+		return true, nil
+	}
+	err := wait.ExponentialBackoff(wait.Backoff{
+		Steps:    5,
+		Duration: 10 * time.Millisecond,
+		Factor:   2.0,
+		Jitter:   0.1,
+	}, updateFn)
+	switch {
+	case errors.Is(err, errRateLimited):
+		return internalerrors.PostRotationActionRateLimited, fmt.Errorf("%s was rolled out less than %s ago, err: %w", w, minInterval, err)
+	case err != nil:
+		return internalerrors.FailedToRolloutWorkload, fmt.Errorf("failed to roll out workload of pod %s/%s, err: %+v", pod.Namespace, pod.Name, err)
+	case w == nil:
+		return internalerrors.WorkloadNotFound, fmt.Errorf("pod %s/%s has no deployment, statefulset or daemonset to roll out", pod.Namespace, pod.Name)
+	}
+	if rolledOut {
+		r.generateEvent(pod, v1.EventTypeNormal, postRotationRolloutReason, fmt.Sprintf("rolled out %s after rotating spc %s/%s", w, spcps.Namespace, spcps.Status.SecretProviderClassName))
+	}
+	return secretsstorev1.RolloutTriggeredReason, nil
+}
+
+// evictPod evicts the pod with the eviction API, which refuses evictions that violate a pod
+// disruption budget. The evictions of the pods of a workload are rate limited. A pod without a
+// workload isn't evicted, as it wouldn't be recreated.
+func (r *Reconciler) evictPod(ctx context.Context, pod *v1.Pod, minInterval time.Duration) (string, error) {
+	var w *workload
+	updateFn := func() (bool, error) {
+		var err error
+		if w, err = r.getWorkload(ctx, pod); err != nil {
+			return false, err
+		}
+		if w == nil {
+			return true, nil
+		}
+		if err = r.updateWorkload(ctx, w, minInterval, nil); err != nil {
+			if apierrors.IsConflict(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	err := wait.ExponentialBackoff(wait.Backoff{
+		Steps:    5,
+		Duration: 10 * time.Millisecond,
+		Factor:   2.0,
+		Jitter:   0.1,
+	}, updateFn)
+	switch {
+	case errors.Is(err, errRateLimited):
+		return internalerrors.PostRotationActionRateLimited, fmt.Errorf("a pod of %s was evicted less than %s ago, err: %w", w, minInterval, err)
+	case err != nil:
+		return internalerrors.FailedToEvictPod, fmt.Errorf("failed to rate limit eviction of pod %s/%s, err: %+v", pod.Namespace, pod.Name, err)
+	case w == nil:
+		return internalerrors.WorkloadNotFound, fmt.Errorf("pod %s/%s has no deployment, statefulset, daemonset or replicaset to recreate it after eviction", pod.Namespace, pod.Name)
+	}
+
+	err = r.kubeClient.CoreV1().Pods(pod.Namespace).Evict(ctx, &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	})
+	if err != nil {
+		if apierrors.IsTooManyRequests(err) {
+			return internalerrors.EvictionBlocked, fmt.Errorf("eviction of pod %s/%s is blocked by a pod disruption budget, err: %+v", pod.Namespace, pod.Name, err)
+		}
+		return internalerrors.FailedToEvictPod, fmt.Errorf("failed to evict pod %s/%s, err: %+v", pod.Namespace, pod.Name, err)
+	}
+	r.generateEvent(pod, v1.EventTypeNormal, postRotationEvictionReason, fmt.Sprintf("evicted pod %s/%s after rotation", pod.Namespace, pod.Name))
+	return secretsstorev1.PodEvictedReason, nil
+}
+
+// getWorkload returns the deployment, statefulset, daemonset or replicaset that controls the pod,
+// or nil if the pod isn't controlled by one of them.
+func (r *Reconciler) getWorkload(ctx context.Context, pod *v1.Pod) (*workload, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil, nil
+	}
+	apps := r.kubeClient.AppsV1()
+	switch ref.Kind {
+	case "ReplicaSet":
+		rs, err := apps.ReplicaSets(pod.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		// roll out the deployment of the replicaset, a new replicaset is created for the rollout
+		if rsRef := metav1.GetControllerOf(rs); rsRef != nil && rsRef.Kind == "Deployment" {
+			var deployment *appsv1.Deployment
+			if deployment, err = apps.Deployments(pod.Namespace).Get(ctx, rsRef.Name, metav1.GetOptions{}); err != nil {
+				return nil, err
+			}
+			return &workload{kind: "Deployment", obj: deployment, template: &deployment.Spec.Template}, nil
+		}
+		return &workload{kind: "ReplicaSet", obj: rs, template: &rs.Spec.Template}, nil
+	case "StatefulSet":
+		sts, err := apps.StatefulSets(pod.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &workload{kind: "StatefulSet", obj: sts, template: &sts.Spec.Template}, nil
+	case "DaemonSet":
+		ds, err := apps.DaemonSets(pod.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &workload{kind: "DaemonSet", obj: ds, template: &ds.Spec.Template}, nil
+	}
+	return nil, nil
+}
+
+// updateWorkload sets the last post rotation action annotation of the workload to now and adds
+// the template annotations to its pod template. It returns errRateLimited if the last action was
+// less than minInterval ago. The patch is conditional on the resource version of the workload,
+// so only one node can take the action when several pods of the workload are rotated at once.
+func (r *Reconciler) updateWorkload(ctx context.Context, w *workload, minInterval time.Duration, templateAnnotations map[string]string) error {
+	now := time.Now()
+	if value, ok := w.obj.GetAnnotations()[LastPostRotationActionAnnotation]; ok {
+		if last, err := time.Parse(time.RFC3339, value); err == nil && now.Before(last.Add(minInterval)) {
+			return errRateLimited
+		}
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": w.obj.GetResourceVersion(),
+			"annotations":     map[string]string{LastPostRotationActionAnnotation: now.UTC().Format(time.RFC3339)},
+		},
+	}
+	if len(templateAnnotations) > 0 {
+		patch["spec"] = map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"annotations": templateAnnotations},
+			},
+		}
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	apps := r.kubeClient.AppsV1()
+	namespace, name := w.obj.GetNamespace(), w.obj.GetName()
+	switch w.kind {
+	case "Deployment":
+		_, err = apps.Deployments(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = apps.StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	case "DaemonSet":
+		_, err = apps.DaemonSets(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	case "ReplicaSet":
+		_, err = apps.ReplicaSets(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	default:
+		err = fmt.Errorf("unsupported workload kind %s", w.kind)
+	}
+	return err
+}
+
+// rotationHashAnnotation returns the pod template annotation for the rotation hash of the secret
+// provider class referenced by the spc pod status
+func rotationHashAnnotation(spcps *secretsstorev1.SecretProviderClassPodStatus) string {
+	kind := spcps.Status.SecretProviderClassKind
+	if len(kind) == 0 {
+		kind = secretsstorev1.SecretProviderClassKind
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(kind + "/" + spcps.Status.SecretProviderClassName))
+	return fmt.Sprintf("%s%08x", RotationHashAnnotationPrefix, h.Sum32())
+}
+
+// objectVersionsHash returns a hash of the object versions. Only the ids and versions are hashed,
+// so the hash doesn't reveal the contents.
+func objectVersionsHash(objects []secretsstorev1.SecretProviderClassObject) string {
+	versions := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		versions = append(versions, obj.ID+"="+obj.Version)
+	}
+	sort.Strings(versions)
+	h := sha256.New()
+	for _, version := range versions {
+		_, _ = h.Write([]byte(version + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
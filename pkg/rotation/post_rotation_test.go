@@ -0,0 +1,260 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	secretsStoreFakeClient "sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned/fake"
+	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
+)
+
+func newTestSPCPodStatus(podName string, objects ...secretsstorev1.SecretProviderClassObject) *secretsstorev1.SecretProviderClassPodStatus {
+	return &secretsstorev1.SecretProviderClassPodStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName + "-default-spc1",
+			Namespace: "default",
+			Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+		},
+		Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+			PodName:                 podName,
+			SecretProviderClassName: "spc1",
+			Objects:                 objects,
+		},
+	}
+}
+
+func newTestOwnedPod(name string, annotations map[string]string, owner *metav1.OwnerReference) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{{
+				Name: "secrets-store-inline",
+				VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+				}},
+			}},
+		},
+	}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func controllerRef(kind, name string) *metav1.OwnerReference {
+	controller := true
+	return &metav1.OwnerReference{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &controller}
+}
+
+func TestRunPostRotationActionRollout(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deployment1", Namespace: "default"}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "deployment1-abc",
+		Namespace:       "default",
+		OwnerReferences: []metav1.OwnerReference{*controllerRef("Deployment", "deployment1")},
+	}}
+	pod := newTestOwnedPod("pod1", map[string]string{PostRotationActionAnnotation: "Rollout"}, controllerRef("ReplicaSet", "deployment1-abc"))
+	spcps := newTestSPCPodStatus("pod1", secretsstorev1.SecretProviderClassObject{ID: "secret/object1", Version: "v2"})
+
+	kubeClient := fake.NewSimpleClientset(deployment, rs, pod)
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(spcps, &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+		Spec:       secretsstorev1.SecretProviderClassSpec{Provider: "provider1"},
+	})
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	getCondition := func() *metav1.Condition {
+		latest, err := crdClient.SecretsstoreV1().SecretProviderClassPodStatuses("default").Get(context.TODO(), spcps.Name, metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return meta.FindStatusCondition(latest.Status.Conditions, secretsstorev1.ConditionTypePostRotationActionSucceeded)
+	}
+
+	// no action if the rotation didn't update the object versions
+	testReconciler.updateStatus(context.TODO(), spcps, testReconciler.runPostRotationAction(context.TODO(), pod, spcps, false))
+	g.Expect(getCondition()).To(BeNil())
+
+	testReconciler.updateStatus(context.TODO(), spcps, testReconciler.runPostRotationAction(context.TODO(), pod, spcps, true))
+	condition := getCondition()
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition.Reason).To(Equal(secretsstorev1.RolloutTriggeredReason))
+
+	updated, err := kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "deployment1", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Spec.Template.Annotations).To(HaveKeyWithValue(rotationHashAnnotation(spcps), objectVersionsHash(spcps.Status.Objects)))
+	g.Expect(updated.Annotations).To(HaveKey(LastPostRotationActionAnnotation))
+
+	// the next rotation within the min interval is rate limited
+	spcps.Status.Objects[0].Version = "v3"
+	testReconciler.updateStatus(context.TODO(), spcps, testReconciler.runPostRotationAction(context.TODO(), pod, spcps, true))
+	condition = getCondition()
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(internalerrors.PostRotationActionRateLimited))
+
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+}
+
+func TestRolloutWorkload(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	spcps := newTestSPCPodStatus("pod1", secretsstorev1.SecretProviderClassObject{ID: "secret/object1", Version: "v1"})
+	hashKey, hash := rotationHashAnnotation(spcps), objectVersionsHash(spcps.Status.Objects)
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "sts1", Namespace: "default"}}
+	// another pod of the daemonset already rolled it out
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ds1", Namespace: "default", ResourceVersion: "1"},
+		Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{hashKey: hash}},
+		}},
+	}
+
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "rs1", Namespace: "default"}}
+
+	kubeClient := fake.NewSimpleClientset(sts, ds, rs)
+	testReconciler, err := newTestReconciler(scheme, kubeClient, secretsStoreFakeClient.NewSimpleClientset(), 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	reason, err := testReconciler.rolloutWorkload(context.TODO(), newTestOwnedPod("pod1", nil, controllerRef("StatefulSet", "sts1")), spcps, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reason).To(Equal(secretsstorev1.RolloutTriggeredReason))
+	updatedSTS, err := kubeClient.AppsV1().StatefulSets("default").Get(context.TODO(), "sts1", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updatedSTS.Spec.Template.Annotations).To(HaveKeyWithValue(hashKey, hash))
+
+	reason, err = testReconciler.rolloutWorkload(context.TODO(), newTestOwnedPod("pod2", nil, controllerRef("DaemonSet", "ds1")), spcps, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reason).To(Equal(secretsstorev1.RolloutTriggeredReason))
+	updatedDS, err := kubeClient.AppsV1().DaemonSets("default").Get(context.TODO(), "ds1", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updatedDS.Annotations).NotTo(HaveKey(LastPostRotationActionAnnotation))
+
+	reason, err = testReconciler.rolloutWorkload(context.TODO(), newTestOwnedPod("pod3", nil, nil), spcps, time.Minute)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reason).To(Equal(internalerrors.WorkloadNotFound))
+
+	// a replicaset that isn't controlled by a deployment isn't rolled out
+	reason, err = testReconciler.rolloutWorkload(context.TODO(), newTestOwnedPod("pod4", nil, controllerRef("ReplicaSet", "rs1")), spcps, time.Minute)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reason).To(Equal(internalerrors.WorkloadNotFound))
+	updatedRS, err := kubeClient.AppsV1().ReplicaSets("default").Get(context.TODO(), "rs1", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updatedRS.Spec.Template.Annotations).NotTo(HaveKey(hashKey))
+
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+}
+
+func TestEvictPod(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "sts1", Namespace: "default"}}
+	kubeClient := fake.NewSimpleClientset(sts)
+	blocked := true
+	kubeClient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if blocked {
+			return true, nil, apierrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 0)
+		}
+		return true, nil, nil
+	})
+	testReconciler, err := newTestReconciler(scheme, kubeClient, secretsStoreFakeClient.NewSimpleClientset(), 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	pod := newTestOwnedPod("pod1", nil, controllerRef("StatefulSet", "sts1"))
+	reason, err := testReconciler.evictPod(context.TODO(), pod, 0)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reason).To(Equal(internalerrors.EvictionBlocked))
+
+	blocked = false
+	reason, err = testReconciler.evictPod(context.TODO(), pod, 0)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reason).To(Equal(secretsstorev1.PodEvictedReason))
+
+	// the evictions of the pods of the statefulset are rate limited
+	reason, err = testReconciler.evictPod(context.TODO(), newTestOwnedPod("pod2", nil, controllerRef("StatefulSet", "sts1")), time.Hour)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reason).To(Equal(internalerrors.PostRotationActionRateLimited))
+
+	// a pod without a workload isn't evicted as it wouldn't be recreated
+	reason, err = testReconciler.evictPod(context.TODO(), newTestOwnedPod("pod3", nil, nil), 0)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(reason).To(Equal(internalerrors.WorkloadNotFound))
+
+	var evictions int
+	for _, action := range kubeClient.Actions() {
+		if action.Matches("create", "pods") && action.GetSubresource() == "eviction" {
+			evictions++
+			g.Expect(action.GetResource()).To(Equal(schema.GroupVersionResource{Version: "v1", Resource: "pods"}))
+		}
+	}
+	g.Expect(evictions).To(Equal(2))
+
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+}
+
+func TestObjectVersionsHash(t *testing.T) {
+	g := NewWithT(t)
+
+	hash := objectVersionsHash([]secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1"}, {ID: "secret/object2", Version: "v1"}})
+	g.Expect(hash).To(HaveLen(16))
+	g.Expect(objectVersionsHash([]secretsstorev1.SecretProviderClassObject{{ID: "secret/object2", Version: "v1"}, {ID: "secret/object1", Version: "v1"}})).To(Equal(hash))
+	g.Expect(objectVersionsHash([]secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v2"}, {ID: "secret/object2", Version: "v1"}})).NotTo(Equal(hash))
+
+	spcps := newTestSPCPodStatus("pod1")
+	g.Expect(rotationHashAnnotation(spcps)).To(HavePrefix(RotationHashAnnotationPrefix))
+	spcps.Status.SecretProviderClassKind = secretsstorev1.ClusterSecretProviderClassKind
+	g.Expect(rotationHashAnnotation(spcps)).NotTo(Equal(rotationHashAnnotation(newTestSPCPodStatus("pod1"))))
+}
@@ -19,11 +19,16 @@ package rotation
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -42,7 +47,8 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	"sigs.k8s.io/secrets-store-csi-driver/controllers"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned"
 	secretsStoreClient "sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned"
 	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
@@ -51,24 +57,48 @@ import (
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/fileutil"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/secretutil"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/spcpsutil"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/version"
 )
 
 const (
 	permission       os.FileMode = 0644
 	maxNumOfRequeues int         = 5
+	// scheduleSyncInterval is how often the spc pod statuses on the node are listed to
+	// schedule new ones and forget deleted ones
+	scheduleSyncInterval = 10 * time.Second
+	// dispatchInterval is how often the spc pod statuses that are due are enqueued
+	dispatchInterval = time.Second
+	// throttleBaseDelay and throttleMaxDelay bound the per spc pod status backoff when
+	// the provider throttles the rotation
+	throttleBaseDelay = 10 * time.Second
+	throttleMaxDelay  = 10 * time.Minute
+	// providerBusyBaseDelay and providerBusyMaxDelay bound the per spc pod status backoff
+	// when its provider already has the max number of rotations in flight
+	providerBusyBaseDelay = 500 * time.Millisecond
+	providerBusyMaxDelay  = 30 * time.Second
+	// rotationHistoryLimit is the max number of entries in the rotation history of the spc pod status
+	rotationHistoryLimit = 10
 
 	mountRotationFailedReason       = "MountRotationFailed"
 	mountRotationCompleteReason     = "MountRotationComplete"
 	k8sSecretRotationFailedReason   = "SecretRotationFailed"
 	k8sSecretRotationCompleteReason = "SecretRotationComplete"
 
+	k8sConfigMapRotationFailedReason   = "ConfigMapRotationFailed"
+	k8sConfigMapRotationCompleteReason = "ConfigMapRotationComplete"
+
+	nodePublishSecretRefChangedReason = "NodePublishSecretRefChanged"
+
 	csipodname      = "csi.storage.k8s.io/pod.name"
 	csipodnamespace = "csi.storage.k8s.io/pod.namespace"
 	csipoduid       = "csi.storage.k8s.io/pod.uid"
 	csipodsa        = "csi.storage.k8s.io/serviceAccount.name"
 )
 
+// errProviderThrottled is returned by reconcile when the provider throttled the rotation
+var errProviderThrottled = errors.New("provider throttled the rotation")
+
 // Reconciler reconciles and rotates contents in the pod
 // and Kubernetes secrets periodically
 type Reconciler struct {
@@ -82,10 +112,44 @@ type Reconciler struct {
 	eventRecorder        record.EventRecorder
 	kubeClient           kubernetes.Interface
 	crdClient            versioned.Interface
+	// workers is the number of spc pod statuses rotated concurrently
+	workers         int
+	providerLimiter *providerLimiter
+
+	// scheduler tracks when each spc pod status is next due for rotation, keyed by <namespace>/<name>
+	scheduler *deadlineQueue
+	// jitterFactor is the max fraction of the rotation interval added to each next due time
+	jitterFactor float64
+	// throttleBackoff is the per spc pod status backoff when the provider throttles the rotation
+	throttleBackoff workqueue.RateLimiter
+	// providerBusyBackoff is the per spc pod status backoff when the provider already has the
+	// max number of rotations in flight
+	providerBusyBackoff workqueue.RateLimiter
+	// watcher tracks the Watch streams of the providers that push object changes
+	watcher *providerWatcher
+	// minInterval is the shortest rotation interval of a rotation policy
+	minInterval time.Duration
+	// expiryFraction is the fraction of the lifetime of an expiring object version after
+	// which the object is refreshed
+	expiryFraction float64
+	// expiry tracks the refreshes of the expiring objects and their reported expiry
+	expiry *expiryTracker
 }
 
 // NewReconciler returns a new reconciler for rotation
-func NewReconciler(s *runtime.Scheme, providerVolumePath, nodeName string, rotationPollInterval time.Duration, providerClients *secretsstore.PluginClientBuilder, filteredWatchSecret bool) (*Reconciler, error) {
+func NewReconciler(s *runtime.Scheme, providerVolumePath, nodeName string, rotationPollInterval, minInterval time.Duration, providerClients *secretsstore.PluginClientBuilder, filteredWatchSecret bool, workers, maxConcurrentPerProvider int, jitterFactor, expiryFraction float64) (*Reconciler, error) {
+	if workers < 1 {
+		return nil, fmt.Errorf("invalid number of rotation workers %d, must be at least 1", workers)
+	}
+	if minInterval <= 0 {
+		return nil, fmt.Errorf("invalid rotation min interval %s, must be positive", minInterval)
+	}
+	if jitterFactor < 0 || jitterFactor > 1 {
+		return nil, fmt.Errorf("invalid rotation jitter factor %v, must be between 0 and 1", jitterFactor)
+	}
+	if expiryFraction <= 0 || expiryFraction > 1 {
+		return nil, fmt.Errorf("invalid rotation expiry refresh fraction %v, must be greater than 0 and at most 1", expiryFraction)
+	}
 	config, err := buildConfig()
 	if err != nil {
 		return nil, err
@@ -101,68 +165,279 @@ func NewReconciler(s *runtime.Scheme, providerVolumePath, nodeName string, rotat
 	eventBroadcaster.StartRecordingToSink(&clientcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(s, v1.EventSource{Component: "csi-secrets-store-rotation"})
 
-	return &Reconciler{
+	r := &Reconciler{
 		store:                store,
 		scheme:               s,
 		providerVolumePath:   providerVolumePath,
 		rotationPollInterval: rotationPollInterval,
+		minInterval:          minInterval,
 		providerClients:      providerClients,
 		reporter:             newStatsReporter(),
 		queue:                workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		eventRecorder:        recorder,
 		kubeClient:           kubeClient,
 		crdClient:            crdClient,
-	}, nil
+		workers:              workers,
+		providerLimiter:      newProviderLimiter(maxConcurrentPerProvider),
+		scheduler:            newDeadlineQueue(),
+		jitterFactor:         jitterFactor,
+		throttleBackoff:      workqueue.NewItemExponentialFailureRateLimiter(throttleBaseDelay, throttleMaxDelay),
+		providerBusyBackoff:  workqueue.NewItemExponentialFailureRateLimiter(providerBusyBaseDelay, providerBusyMaxDelay),
+		watcher:              newProviderWatcher(),
+		expiryFraction:       expiryFraction,
+		expiry:               newExpiryTracker(),
+	}
+	// rotate the pods using a secret provider class as soon as it changes instead of
+	// waiting for the next poll
+	store.AddSecretProviderClassEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: r.handleSecretProviderClassUpdate,
+	})
+	// rotate the pods authenticating with a nodePublishSecretRef secret as soon as the
+	// credentials in it change, so the provider isn't called with stale credentials
+	store.AddNodePublishSecretRefEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: r.handleNodePublishSecretRefUpdate,
+	})
+	return r, nil
 }
 
 // Run starts the rotation reconciler
 func (r *Reconciler) Run(stopCh <-chan struct{}) {
 	defer r.queue.ShutDown()
-	klog.Infof("starting rotation reconciler with poll interval: %s", r.rotationPollInterval)
+	klog.Infof("starting rotation reconciler with poll interval: %s, workers: %d", r.rotationPollInterval, r.workers)
 
-	ticker := time.NewTicker(r.rotationPollInterval)
-	defer ticker.Stop()
+	syncTicker := time.NewTicker(scheduleSyncInterval)
+	defer syncTicker.Stop()
+	dispatchTicker := time.NewTicker(dispatchInterval)
+	defer dispatchTicker.Stop()
 
 	if err := r.store.Run(stopCh); err != nil {
 		klog.Fatalf("failed to run informers for rotation reconciler, err: %+v", err)
 	}
 
-	// TODO (aramase) consider adding more workers to process reconcile concurrently
-	for i := 0; i < 1; i++ {
+	// the provider watch streams are closed when the reconciler stops
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the workqueue never hands out a key that is already being processed, so the same
+	// spc pod status is never rotated by more than one worker at a time
+	for i := 0; i < r.workers; i++ {
 		go wait.Until(r.runWorker, time.Second, stopCh)
 	}
 
+	r.syncSchedule(time.Now())
+	r.syncWatches(ctx, time.Now())
+	r.syncExpiry(time.Now())
 	for {
 		select {
 		case <-stopCh:
 			return
-		case <-ticker.C:
-			// The spc pod status informer is configured to do a filtered list watch of spc pod statuses
-			// labeled for the same node as the driver. LIST will only return the filtered results.
-			spcpsList, err := r.store.ListSecretProviderClassPodStatus()
-			if err != nil {
-				klog.ErrorS(err, "failed to list secret provider class pod status for node", "controller", "rotation")
-				continue
-			}
-			for _, spcps := range spcpsList {
-				key, err := cache.MetaNamespaceKeyFunc(spcps)
-				if err == nil {
-					r.queue.Add(key)
-				}
+		case <-syncTicker.C:
+			r.syncSchedule(time.Now())
+			r.syncWatches(ctx, time.Now())
+			r.syncExpiry(time.Now())
+		case <-dispatchTicker.C:
+			r.enqueueDue(time.Now())
+			r.reporter.reportRotationQueueDepth(r.queue.Len())
+		}
+	}
+}
+
+// syncSchedule schedules the spc pod statuses on the node that aren't scheduled yet and forgets
+// the deleted ones. The new spc pod statuses are spread evenly across their rotation interval, so
+// the pods mounted at the same time, or all the pods when the driver restarts, aren't rotated at once.
+func (r *Reconciler) syncSchedule(now time.Time) {
+	// The spc pod status informer is configured to do a filtered list watch of spc pod statuses
+	// labeled for the same node as the driver. LIST will only return the filtered results.
+	spcpsList, err := r.store.ListSecretProviderClassPodStatus()
+	if err != nil {
+		klog.ErrorS(err, "failed to list secret provider class pod status for node", "controller", "rotation")
+		return
+	}
+
+	keys := make(map[string]bool, len(spcpsList))
+	for _, spcps := range spcpsList {
+		key, err := cache.MetaNamespaceKeyFunc(spcps)
+		if err != nil {
+			continue
+		}
+		keys[key] = true
+		if _, ok := r.scheduler.due(key); ok {
+			continue
+		}
+		policy := r.rotationPolicy(spcps)
+		var offset time.Duration
+		if policy.interval > 0 {
+			offset = time.Duration(rand.Int63n(int64(policy.interval))) // #nosec
+		}
+		r.scheduler.schedule(key, policy.nextInWindow(now.Add(offset), r.jitterFactor))
+	}
+	for _, key := range r.scheduler.list() {
+		if !keys[key] {
+			r.scheduler.remove(key)
+			r.throttleBackoff.Forget(key)
+			r.providerBusyBackoff.Forget(key)
+		}
+	}
+}
+
+// enqueueDue enqueues the spc pod statuses that are due for rotation. The rotation policy is
+// evaluated again when the spc pod status is due, so if rotation has been disabled or the
+// rotation windows changed since it was scheduled, it's rescheduled instead. The periodic
+// rotation of the spc pod statuses watched with an open provider stream is skipped.
+func (r *Reconciler) enqueueDue(now time.Time) {
+	for _, key := range r.scheduler.popDue(now) {
+		spcps, err := r.store.GetSecretProviderClassPodStatus(key)
+		if err != nil {
+			// the spc pod status was deleted
+			continue
+		}
+		policy := r.rotationPolicy(spcps)
+		if !policy.enabled {
+			r.scheduler.schedule(key, policy.nextDue(now, r.jitterFactor))
+			continue
+		}
+		if !policy.inWindow(now) {
+			r.scheduler.schedule(key, policy.nextInWindow(now, r.jitterFactor))
+			continue
+		}
+		// the provider pushes the object changes of the watched spc pod statuses
+		if r.watcher.skipPoll(key) {
+			r.scheduler.schedule(key, policy.nextDue(now, r.jitterFactor))
+			continue
+		}
+		r.queue.Add(key)
+	}
+}
+
+// rotationPolicy returns the effective rotation policy of the spc pod status. If the pod or the
+// secret provider class can't be found, the default policy is returned, so the error is reported
+// by the reconcile. If the policy is invalid, the error is logged and the default policy is returned.
+func (r *Reconciler) rotationPolicy(spcps *secretsstorev1.SecretProviderClassPodStatus) *rotationPolicy {
+	defaultPolicy := &rotationPolicy{enabled: true, interval: r.rotationPollInterval}
+	pod, err := r.store.GetPod(spcps.Status.PodName, spcps.Namespace)
+	if err != nil {
+		return defaultPolicy
+	}
+	spc, err := r.getSecretProviderClass(spcps)
+	if err != nil {
+		return defaultPolicy
+	}
+	var attributes map[string]string
+	if podVol := k8sutil.SPCVolume(pod, spcps.Status.SecretProviderClassKind, spc.Name); podVol != nil {
+		attributes = podVol.CSI.VolumeAttributes
+	}
+	policy, err := newRotationPolicy(spc.Spec.RotationPolicy, pod.Annotations, attributes, r.rotationPollInterval, r.minInterval)
+	if err != nil {
+		klog.ErrorS(err, "invalid rotation policy, using the default policy", "spcps", klog.KObj(spcps), "pod", klog.KObj(pod), "controller", "rotation")
+		return defaultPolicy
+	}
+	return policy
+}
+
+// handleSecretProviderClassUpdate enqueues the spc pod statuses on the node that reference the
+// updated secret provider class or cluster secret provider class, so they're rotated and then
+// rescheduled with the new rotation policy. The spc pod statuses with rotation disabled aren't
+// rotated, and are rescheduled if the new rotation interval is due before their scheduled time.
+// Updates that don't change the spec, like the periodic resync, are ignored.
+func (r *Reconciler) handleSecretProviderClassUpdate(oldObj, newObj interface{}) {
+	var kind, name, namespace string
+	switch spc := newObj.(type) {
+	case *secretsstorev1.SecretProviderClass:
+		if old, ok := oldObj.(*secretsstorev1.SecretProviderClass); ok && reflect.DeepEqual(old.Spec, spc.Spec) {
+			return
+		}
+		kind, name, namespace = secretsstorev1.SecretProviderClassKind, spc.Name, spc.Namespace
+	case *secretsstorev1.ClusterSecretProviderClass:
+		if old, ok := oldObj.(*secretsstorev1.ClusterSecretProviderClass); ok && reflect.DeepEqual(old.Spec, spc.Spec) {
+			return
+		}
+		kind, name = secretsstorev1.ClusterSecretProviderClassKind, spc.Name
+	default:
+		return
+	}
+
+	spcpsList, err := r.store.ListSecretProviderClassPodStatusForSPC(kind, name, namespace)
+	if err != nil {
+		klog.ErrorS(err, "failed to list secret provider class pod status for secret provider class", "kind", kind, "spc", klog.ObjectRef{Namespace: namespace, Name: name}, "controller", "rotation")
+		return
+	}
+	now := time.Now()
+	for _, spcps := range spcpsList {
+		key, err := cache.MetaNamespaceKeyFunc(spcps)
+		if err != nil {
+			continue
+		}
+		policy := r.rotationPolicy(spcps)
+		if !policy.enabled {
+			if due, ok := r.scheduler.due(key); !ok || due.After(now.Add(policy.interval)) {
+				r.scheduler.schedule(key, policy.nextDue(now, r.jitterFactor))
 			}
+			continue
+		}
+		r.queue.Add(key)
+	}
+	klog.V(3).InfoS("secret provider class updated, enqueued spc pod statuses for rotation", "kind", kind, "spc", klog.ObjectRef{Namespace: namespace, Name: name}, "count", len(spcpsList), "controller", "rotation")
+}
+
+// handleNodePublishSecretRefUpdate enqueues the spc pod statuses on the node whose volume references
+// the updated nodePublishSecretRef secret and generates an event on their pods. Updates that don't
+// change the secret data, like the periodic resync, are ignored.
+func (r *Reconciler) handleNodePublishSecretRefUpdate(oldObj, newObj interface{}) {
+	secret, ok := newObj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	if old, ok := oldObj.(*v1.Secret); ok && reflect.DeepEqual(old.Data, secret.Data) {
+		return
+	}
+
+	spcpsList, err := r.store.ListSecretProviderClassPodStatusForNodePublishSecretRef(secret.Name, secret.Namespace)
+	if err != nil {
+		klog.ErrorS(err, "failed to list secret provider class pod status for node publish secret", "secret", klog.KObj(secret), "controller", "rotation")
+		return
+	}
+	for _, spcps := range spcpsList {
+		if !r.rotationPolicy(spcps).enabled {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(spcps)
+		if err != nil {
+			continue
+		}
+		r.queue.Add(key)
+		if pod, err := r.store.GetPod(spcps.Status.PodName, spcps.Namespace); err == nil {
+			r.generateEvent(pod, v1.EventTypeNormal, nodePublishSecretRefChangedReason, fmt.Sprintf("node publish secret %s/%s changed, rotating the contents mounted using secret provider class %s", secret.Namespace, secret.Name, spcps.Status.SecretProviderClassName))
 		}
 	}
+	klog.V(3).InfoS("node publish secret updated, enqueued spc pod statuses for rotation", "secret", klog.KObj(secret), "count", len(spcpsList), "controller", "rotation")
 }
 
-func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProviderClassPodStatus) (err error) {
+func (r *Reconciler) reconcile(ctx context.Context, spcps *secretsstorev1.SecretProviderClassPodStatus) (err error) {
 	begin := time.Now()
 	errorReason := internalerrors.FailedToRotate
 	// requiresUpdate is set to true when the new object versions differ from the current object versions
 	// after the provider mount request is complete
 	var requiresUpdate bool
 	var providerName string
+	// skipped is set to true when the pod is being terminated and rotation isn't attempted
+	var skipped bool
+	var pod *v1.Pod
+	// changedObjects are the objects whose version was changed by the rotation
+	var changedObjects []secretsstorev1.RotationHistoryObject
+
+	// the spc pod status is from the informer cache, so work on a copy
+	spcps = spcps.DeepCopy()
 
 	defer func() {
+		if !skipped {
+			// the conditions and the rotation history are written with a single update
+			var changes []statusChange
+			if err == nil {
+				changes = append(changes, r.runPostRotationAction(ctx, pod, spcps, requiresUpdate))
+			}
+			changes = append(changes, rotationConditionChange(errorReason, err), rotationHistoryChange(changedObjects, errorReason, err))
+			r.updateStatus(ctx, spcps, changes...)
+		}
 		if err != nil {
 			r.reporter.reportRotationErrorCtMetric(providerName, errorReason, requiresUpdate)
 			return
@@ -173,7 +448,7 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 
 	// get pod from informer cache
 	podName, podNamespace := spcps.Status.PodName, spcps.Namespace
-	pod, err := r.store.GetPod(podName, podNamespace)
+	pod, err = r.store.GetPod(podName, podNamespace)
 	if err != nil {
 		errorReason = internalerrors.PodNotFound
 		return fmt.Errorf("failed to get pod %s/%s, err: %+v", podNamespace, podName, err)
@@ -184,20 +459,24 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 	// the spcps will be gc when the pod is deleted and will not show up in the next rotation cycle
 	if !pod.GetDeletionTimestamp().IsZero() || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
 		klog.V(5).InfoS("pod is being terminated, skipping rotation", "pod", klog.KObj(pod))
+		skipped = true
 		return nil
 	}
 
 	spcName, spcNamespace := spcps.Status.SecretProviderClassName, spcps.Namespace
 
 	// get the secret provider class the pod status is referencing from informer cache
-	spc, err := r.store.GetSecretProviderClass(spcName, spcNamespace)
+	spc, err := r.getSecretProviderClass(spcps)
 	if err != nil {
 		errorReason = internalerrors.SecretProviderClassNotFound
+		if errors.Is(err, k8sutil.ErrNamespaceNotAllowed) {
+			errorReason = internalerrors.NamespaceNotAllowed
+		}
 		return fmt.Errorf("failed to get secret provider class %s/%s, err: %+v", spcNamespace, spcName, err)
 	}
 
 	// determine which pod volume this is associated with
-	podVol := k8sutil.SPCVolume(pod, spc.Name)
+	podVol := k8sutil.SPCVolume(pod, spcps.Status.SecretProviderClassKind, spc.Name)
 	if podVol == nil {
 		errorReason = internalerrors.PodVolumeNotFound
 		return fmt.Errorf("could not find secret provider class pod status volume for pod %s/%s", podNamespace, podName)
@@ -213,9 +492,11 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 		return fmt.Errorf("secret provider class pod status volume name did not match pod Volume for pod %s/%s", podNamespace, podName)
 	}
 
-	parameters := make(map[string]string)
-	if spc.Spec.Parameters != nil {
-		parameters = spc.Spec.Parameters
+	// the spc is shared with the other workers through the informer cache, so the pod
+	// attributes are added to a copy of its parameters
+	parameters := make(map[string]string, len(spc.Spec.Parameters)+4)
+	for k, v := range spc.Spec.Parameters {
+		parameters[k] = v
 	}
 	// Set these parameters to mimic the exact same attributes we get as part of NodePublishVolumeRequest
 	parameters[csipodname] = podName
@@ -279,14 +560,19 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 		r.generateEvent(pod, v1.EventTypeWarning, mountRotationFailedReason, fmt.Sprintf("failed to lookup provider client: %q", providerName))
 		return fmt.Errorf("failed to lookup provider client: %q", providerName)
 	}
-	newObjectVersions, errorReason, err := secretsstore.MountContent(ctx, providerClient, string(paramsJSON), string(secretsJSON), spcps.Status.TargetPath, string(permissionJSON), oldObjectVersions)
+	newObjects, errorReason, err := secretsstore.MountContent(ctx, providerClient, string(paramsJSON), string(secretsJSON), spcps.Status.TargetPath, string(permissionJSON), oldObjectVersions)
 	if err != nil {
 		r.generateEvent(pod, v1.EventTypeWarning, mountRotationFailedReason, fmt.Sprintf("provider mount err: %+v", err))
+		if status.Code(err) == codes.ResourceExhausted {
+			errorReason = internalerrors.ProviderThrottled
+			return fmt.Errorf("%w: failed to rotate objects for pod %s/%s, err: %+v", errProviderThrottled, spcps.Namespace, spcps.Status.PodName, err)
+		}
 		return fmt.Errorf("failed to rotate objects for pod %s/%s, err: %+v", spcps.Namespace, spcps.Status.PodName, err)
 	}
 
 	// compare the old object versions and new object versions to check if any of the objects
 	// have been updated by the provider
+	newObjectVersions := spcpsutil.ObjectVersions(newObjects)
 	for k, v := range newObjectVersions {
 		version, ok := oldObjectVersions[strings.TrimSpace(k)]
 		if ok && strings.TrimSpace(version) == strings.TrimSpace(v) {
@@ -301,21 +587,31 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 		requiresUpdate = true
 	}
 
+	ov := make([]secretsstorev1.SecretProviderClassObject, 0, len(newObjects))
+	for _, obj := range newObjects {
+		obj.ID, obj.Version = strings.TrimSpace(obj.ID), strings.TrimSpace(obj.Version)
+		ov = append(ov, obj)
+	}
+	// the expiry of an object can change without a new version, for example when the provider
+	// renewed the lease of a dynamic credential. The new expiry is stored in the spc pod status,
+	// so the object isn't refreshed again, but it isn't a rotation.
+	expiryChanged := spcpsutil.MergeObjectExpiry(spcps.Status.Objects, ov)
+
 	var errs []error
 	// this loop is executed if there is a difference in the current versions cached in
 	// the secret provider class pod status and the new versions returned by the provider.
 	// the diff in versions is populated in the secret provider class pod status and if the
 	// secret provider class contains secret objects, then the corresponding kubernetes secrets
 	// data is updated with the latest versions
-	if requiresUpdate {
-		// generate an event for successful mount update
-		r.generateEvent(pod, v1.EventTypeNormal, mountRotationCompleteReason, fmt.Sprintf("successfully rotated mounted contents for spc %s/%s", spcNamespace, spcName))
-		klog.InfoS("updating versions in spc pod status", "spcps", klog.KObj(spcps), "controller", "rotation")
-
-		var ov []v1alpha1.SecretProviderClassObject
-		for k, v := range newObjectVersions {
-			ov = append(ov, v1alpha1.SecretProviderClassObject{ID: strings.TrimSpace(k), Version: strings.TrimSpace(v)})
+	if requiresUpdate || expiryChanged {
+		if requiresUpdate {
+			// generate an event for successful mount update
+			r.generateEvent(pod, v1.EventTypeNormal, mountRotationCompleteReason, fmt.Sprintf("successfully rotated mounted contents for spc %s/%s", spcNamespace, spcName))
+			changedObjects = spcpsutil.ObjectVersionChanges(oldObjectVersions, spcpsutil.ObjectVersions(ov))
+			now := metav1.Now()
+			spcps.Status.LastRotationTime = &now
 		}
+		klog.InfoS("updating versions in spc pod status", "spcps", klog.KObj(spcps), "controller", "rotation")
 		spcps.Status.Objects = ov
 
 		updateFn := func() (bool, error) {
@@ -333,17 +629,19 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 			Factor:   1.0,
 			Jitter:   0.1,
 		}, updateFn); err != nil {
+			errorReason = internalerrors.FailedToUpdateSecretProviderClassPodStatus
 			r.generateEvent(pod, v1.EventTypeWarning, mountRotationFailedReason, fmt.Sprintf("failed to update versions in spc pod status %s, err: %+v", spcName, err))
 			return fmt.Errorf("failed to update spc pod status, err: %+v", err)
 		}
 	}
 
-	if len(spc.Spec.SecretObjects) == 0 {
-		klog.InfoS("spc doesn't contain secret objects", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "controller", "rotation")
+	if len(spc.Spec.SecretObjects) == 0 && len(spc.Spec.ConfigMapObjects) == 0 {
+		klog.InfoS("spc doesn't contain secret or configmap objects", "spc", klog.KObj(spc), "pod", klog.KObj(pod), "controller", "rotation")
 		return nil
 	}
 	files, err := fileutil.GetMountedFiles(spcps.Status.TargetPath)
 	if err != nil {
+		errorReason = internalerrors.FailedToGetMountedFiles
 		r.generateEvent(pod, v1.EventTypeWarning, k8sSecretRotationFailedReason, fmt.Sprintf("failed to get mounted files, err: %+v", err))
 		return fmt.Errorf("failed to get mounted files, err: %+v", err)
 	}
@@ -353,22 +651,40 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 		if err = secretutil.ValidateSecretObject(*secretObj); err != nil {
 			r.generateEvent(pod, v1.EventTypeWarning, k8sSecretRotationFailedReason, fmt.Sprintf("failed validation for secret object in spc %s/%s, err: %+v", spcNamespace, spcName, err))
 			klog.ErrorS(err, "failed validation for secret object in spc", "spc", klog.KObj(spc), "controller", "rotation")
+			errorReason = internalerrors.InvalidSecretObject
 			errs = append(errs, err)
 			continue
 		}
 
 		secretType := secretutil.GetSecretType(strings.TrimSpace(secretObj.Type))
 		var datamap map[string][]byte
-		if datamap, err = secretutil.GetSecretData(secretObj.Data, secretType, files); err != nil {
+		if datamap, err = secretutil.BuildSecretData(*secretObj, secretType, files); err != nil {
 			r.generateEvent(pod, v1.EventTypeWarning, k8sSecretRotationFailedReason, fmt.Sprintf("failed to get data in spc %s/%s for secret %s, err: %+v", spcNamespace, spcName, secretName, err))
 			klog.ErrorS(err, "failed to get data in spc for secret", "spc", klog.KObj(spc), "secret", klog.ObjectRef{Namespace: spcNamespace, Name: secretName}, "controller", "rotation")
+			errorReason = internalerrors.FailedToGetSecretData
+			errs = append(errs, err)
+			continue
+		}
+		if err = secretutil.ValidateSecretData(secretType, datamap); err != nil {
+			r.generateEvent(pod, v1.EventTypeWarning, k8sSecretRotationFailedReason, fmt.Sprintf("invalid data in spc %s/%s for secret %s, err: %+v", spcNamespace, spcName, secretName, err))
+			klog.ErrorS(err, "invalid data in spc for secret", "spc", klog.KObj(spc), "secret", klog.ObjectRef{Namespace: spcNamespace, Name: secretName}, "controller", "rotation")
+			errorReason = internalerrors.InvalidSecretData
 			errs = append(errs, err)
 			continue
 		}
 
+		var annotations map[string]string
+		if annotations, err = controllers.SecretAnnotations(secretObj, spcps, time.Now()); err != nil {
+			klog.ErrorS(err, "failed to build annotations for secret", "spc", klog.KObj(spc), "secret", klog.ObjectRef{Namespace: spcNamespace, Name: secretName}, "controller", "rotation")
+			errorReason = internalerrors.FailedToPatchSecret
+			errs = append(errs, err)
+			continue
+		}
+		immutable := secretObj.Immutable
+
 		patchFn := func() (bool, error) {
 			// patch secret data with the new contents
-			if err := r.patchSecret(ctx, secretObj.SecretName, spcps.Namespace, datamap); err != nil {
+			if err := r.patchSecret(ctx, secretName, spcps.Namespace, datamap, annotations, immutable); err != nil {
 				klog.ErrorS(err, "failed to patch secret data", "secret", klog.ObjectRef{Namespace: spcNamespace, Name: secretName}, "spc", klog.KObj(spc), "controller", "rotation")
 				return false, nil
 			}
@@ -382,6 +698,8 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 			Jitter:   0.1,
 		}, patchFn); err != nil {
 			r.generateEvent(pod, v1.EventTypeWarning, k8sSecretRotationFailedReason, fmt.Sprintf("failed to patch secret %s with new data, err: %+v", secretName, err))
+			errorReason = internalerrors.FailedToPatchSecret
+			errs = append(errs, fmt.Errorf("failed to patch secret %s with new data, err: %+v", secretName, err))
 			// continue to ensure error in a single secret doesn't block the updates
 			// for all other secret objects defined in SPC
 			continue
@@ -389,26 +707,183 @@ func (r *Reconciler) reconcile(ctx context.Context, spcps *v1alpha1.SecretProvid
 		r.generateEvent(pod, v1.EventTypeNormal, k8sSecretRotationCompleteReason, fmt.Sprintf("successfully rotated K8s secret %s", secretName))
 	}
 
+	for _, configMapObj := range spc.Spec.ConfigMapObjects {
+		configMapName := strings.TrimSpace(configMapObj.ConfigMapName)
+
+		if err = secretutil.ValidateConfigMapObject(*configMapObj); err != nil {
+			r.generateEvent(pod, v1.EventTypeWarning, k8sConfigMapRotationFailedReason, fmt.Sprintf("failed validation for configmap object in spc %s/%s, err: %+v", spcNamespace, spcName, err))
+			klog.ErrorS(err, "failed validation for configmap object in spc", "spc", klog.KObj(spc), "controller", "rotation")
+			errorReason = internalerrors.InvalidConfigMapObject
+			errs = append(errs, err)
+			continue
+		}
+
+		var data map[string]string
+		var binaryData map[string][]byte
+		if data, binaryData, err = secretutil.GetConfigMapData(configMapObj.Data, files); err != nil {
+			r.generateEvent(pod, v1.EventTypeWarning, k8sConfigMapRotationFailedReason, fmt.Sprintf("failed to get data in spc %s/%s for configmap %s, err: %+v", spcNamespace, spcName, configMapName, err))
+			klog.ErrorS(err, "failed to get data in spc for configmap", "spc", klog.KObj(spc), "configmap", klog.ObjectRef{Namespace: spcNamespace, Name: configMapName}, "controller", "rotation")
+			errorReason = internalerrors.FailedToGetConfigMapData
+			errs = append(errs, err)
+			continue
+		}
+
+		var annotations map[string]string
+		if annotations, err = controllers.ConfigMapAnnotations(spcps, time.Now()); err != nil {
+			klog.ErrorS(err, "failed to build annotations for configmap", "spc", klog.KObj(spc), "configmap", klog.ObjectRef{Namespace: spcNamespace, Name: configMapName}, "controller", "rotation")
+			errorReason = internalerrors.FailedToPatchConfigMap
+			errs = append(errs, err)
+			continue
+		}
+
+		patchFn := func() (bool, error) {
+			// patch configmap data with the new contents
+			if err := r.patchConfigMap(ctx, configMapName, spcps.Namespace, data, binaryData, annotations); err != nil {
+				klog.ErrorS(err, "failed to patch configmap data", "configmap", klog.ObjectRef{Namespace: spcNamespace, Name: configMapName}, "spc", klog.KObj(spc), "controller", "rotation")
+				return false, nil
+			}
+			return true, nil
+		}
+
+		if err := wait.ExponentialBackoff(wait.Backoff{
+			Steps:    5,
+			Duration: 1 * time.Millisecond,
+			Factor:   1.0,
+			Jitter:   0.1,
+		}, patchFn); err != nil {
+			r.generateEvent(pod, v1.EventTypeWarning, k8sConfigMapRotationFailedReason, fmt.Sprintf("failed to patch configmap %s with new data, err: %+v", configMapName, err))
+			errorReason = internalerrors.FailedToPatchConfigMap
+			errs = append(errs, fmt.Errorf("failed to patch configmap %s with new data, err: %+v", configMapName, err))
+			continue
+		}
+		r.generateEvent(pod, v1.EventTypeNormal, k8sConfigMapRotationCompleteReason, fmt.Sprintf("successfully rotated K8s configmap %s", configMapName))
+	}
+
 	// for errors with individual secret objects in spc, we continue to the next secret object
 	// to prevent error with one secret from affecting rotation of all other k8s secret
 	// this consolidation of errors within the loop determines if the spc pod status still needs
 	// to be retried at the end of this rotation reconcile loop
 	if len(errs) > 0 {
-		return fmt.Errorf("failed to rotate one or more k8s secrets, err: %+v", errs)
+		return fmt.Errorf("failed to rotate one or more k8s secrets or configmaps, err: %+v", errs)
 	}
 
 	return nil
 }
 
+// getSecretProviderClass returns the secret provider class referenced by the spc pod status
+// from the informer cache. For a cluster secret provider class, the spc pod status namespace
+// must be selected by the namespace selector.
+func (r *Reconciler) getSecretProviderClass(spcps *secretsstorev1.SecretProviderClassPodStatus) (*secretsstorev1.SecretProviderClass, error) {
+	if spcps.Status.SecretProviderClassKind != secretsstorev1.ClusterSecretProviderClassKind {
+		return r.store.GetSecretProviderClass(spcps.Status.SecretProviderClassName, spcps.Namespace)
+	}
+	cspc, err := r.store.GetClusterSecretProviderClass(spcps.Status.SecretProviderClassName)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := r.store.GetNamespace(spcps.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return k8sutil.SecretProviderClassForNamespace(cspc, namespace)
+}
+
 // updateSecretProviderClassPodStatus updates secret provider class pod status
-func (r *Reconciler) updateSecretProviderClassPodStatus(ctx context.Context, spcPodStatus *v1alpha1.SecretProviderClassPodStatus) error {
+func (r *Reconciler) updateSecretProviderClassPodStatus(ctx context.Context, spcPodStatus *secretsstorev1.SecretProviderClassPodStatus) error {
 	// update the secret provider class pod status
-	_, err := r.crdClient.SecretsstoreV1alpha1().SecretProviderClassPodStatuses(spcPodStatus.Namespace).Update(ctx, spcPodStatus, metav1.UpdateOptions{})
+	_, err := r.crdClient.SecretsstoreV1().SecretProviderClassPodStatuses(spcPodStatus.Namespace).Update(ctx, spcPodStatus, metav1.UpdateOptions{})
 	return err
 }
 
-// patchSecret patches secret with the new data and returns error if any
-func (r *Reconciler) patchSecret(ctx context.Context, name, namespace string, data map[string][]byte) error {
+// statusChange changes the status of the spc pod status and returns true if it changed
+type statusChange func(spcps *secretsstorev1.SecretProviderClassPodStatus) bool
+
+// conditionChange returns the change that sets the condition in the spc pod status
+func conditionChange(conditionType string, status metav1.ConditionStatus, reason, message string) statusChange {
+	return func(spcps *secretsstorev1.SecretProviderClassPodStatus) bool {
+		return spcpsutil.SetCondition(spcps, conditionType, status, reason, message)
+	}
+}
+
+// rotationConditionChange returns the change that sets the RotationSucceeded condition in the
+// spc pod status based on the result of the rotation
+func rotationConditionChange(errorReason string, rotationErr error) statusChange {
+	if rotationErr != nil {
+		return conditionChange(secretsstorev1.ConditionTypeRotationSucceeded, metav1.ConditionFalse, errorReason, rotationErr.Error())
+	}
+	return conditionChange(secretsstorev1.ConditionTypeRotationSucceeded, metav1.ConditionTrue, secretsstorev1.RotationSucceededReason, "")
+}
+
+// rotationHistoryChange returns the change that adds the result of the rotation to the rotation
+// history in the spc pod status
+func rotationHistoryChange(changedObjects []secretsstorev1.RotationHistoryObject, errorReason string, rotationErr error) statusChange {
+	entry := secretsstorev1.RotationHistoryEntry{
+		Time:    metav1.Now(),
+		Result:  secretsstorev1.RotationResultSucceeded,
+		Reason:  secretsstorev1.RotationSucceededReason,
+		Objects: changedObjects,
+	}
+	if rotationErr != nil {
+		entry.Result, entry.Reason = secretsstorev1.RotationResultFailed, errorReason
+	}
+	return func(spcps *secretsstorev1.SecretProviderClassPodStatus) bool {
+		return spcpsutil.AddRotationHistory(spcps, entry, rotationHistoryLimit)
+	}
+}
+
+// applyStatusChanges applies all the changes to the spc pod status and returns true if any
+// of them changed it. Nil changes are ignored.
+func applyStatusChanges(spcps *secretsstorev1.SecretProviderClassPodStatus, changes []statusChange) bool {
+	changed := false
+	for _, change := range changes {
+		if change != nil && change(spcps) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// updateStatus applies the changes to the spc pod status and writes them with a single update.
+// The update is skipped if the changes don't change the current spc pod status. The latest spc
+// pod status is fetched before updating as the secret-sync controller can update the spc pod
+// status concurrently.
+func (r *Reconciler) updateStatus(ctx context.Context, spcps *secretsstorev1.SecretProviderClassPodStatus, changes ...statusChange) {
+	if !applyStatusChanges(spcps, changes) {
+		return
+	}
+
+	updateFn := func() (bool, error) {
+		latest, err := r.crdClient.SecretsstoreV1().SecretProviderClassPodStatuses(spcps.Namespace).Get(ctx, spcps.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			klog.ErrorS(err, "failed to get spc pod status", "spcps", klog.KObj(spcps), "controller", "rotation")
+			return false, nil
+		}
+		if !applyStatusChanges(latest, changes) {
+			return true, nil
+		}
+		if err = r.updateSecretProviderClassPodStatus(ctx, latest); err != nil {
+			klog.ErrorS(err, "failed to update conditions and rotation history in spc pod status", "spcps", klog.KObj(spcps), "controller", "rotation")
+			return false, nil
+		}
+		return true, nil
+	}
+
+	if err := wait.ExponentialBackoff(wait.Backoff{
+		Steps:    5,
+		Duration: 1 * time.Millisecond,
+		Factor:   1.0,
+		Jitter:   0.1,
+	}, updateFn); err != nil {
+		klog.ErrorS(err, "failed to update conditions and rotation history in spc pod status", "spcps", klog.KObj(spcps), "controller", "rotation")
+	}
+}
+
+// patchSecret patches secret with the new data and annotations and returns error if any.
+// If the secret is immutable or immutable is set, a change in the data replaces the secret.
+func (r *Reconciler) patchSecret(ctx context.Context, name, namespace string, data map[string][]byte, annotations map[string]string, immutable bool) error {
 	secret := &v1.Secret{}
 	secret, err := r.store.GetSecret(name, namespace)
 	// if there is an error getting the secret -
@@ -420,6 +895,10 @@ func (r *Reconciler) patchSecret(ctx context.Context, name, namespace string, da
 	if err != nil {
 		return err
 	}
+	// the secret is only updated by the secret provider class that owns it
+	if conflict := controllers.SecretConflict(secret, annotations[controllers.SecretOwnerAnnotation]); len(conflict) > 0 {
+		return errors.New(conflict)
+	}
 
 	currentDataSHA, err := secretutil.GetSHAFromSecret(secret.Data)
 	if err != nil {
@@ -429,19 +908,33 @@ func (r *Reconciler) patchSecret(ctx context.Context, name, namespace string, da
 	if err != nil {
 		return fmt.Errorf("failed to compute SHA for %s/%s new data, err: %+v", namespace, name, err)
 	}
-	// if the SHA for the current data and new data match then skip
-	// the redundant API call to patch the same data
-	if currentDataSHA == newDataSHA {
+	dataChanged := currentDataSHA != newDataSHA
+	// if the SHA for the current data and new data match and the annotations are up to date
+	// then skip the redundant API call to patch the same data
+	if !dataChanged && !controllers.SecretAnnotationsChanged(secret.Annotations, annotations) {
 		return nil
 	}
+	if dataChanged && (immutable || (secret.Immutable != nil && *secret.Immutable)) {
+		return r.replaceSecret(ctx, secret, data, annotations, immutable)
+	}
 
 	newSecret := *secret
+	newSecret.Annotations = make(map[string]string, len(secret.Annotations)+len(annotations))
+	for k, v := range secret.Annotations {
+		newSecret.Annotations[k] = v
+	}
+	for k, v := range annotations {
+		newSecret.Annotations[k] = v
+	}
+	// the last sync time is only updated when the data changes
+	if t, ok := secret.Annotations[controllers.LastSyncTimeAnnotation]; ok && !dataChanged {
+		newSecret.Annotations[controllers.LastSyncTimeAnnotation] = t
+	}
 	newSecret.Data = data
 	oldData, err := json.Marshal(secret)
 	if err != nil {
 		return fmt.Errorf("failed to marshal old secret, err: %+v", err)
 	}
-	secret.Data = data
 	newData, err := json.Marshal(&newSecret)
 	if err != nil {
 		return fmt.Errorf("failed to marshal new secret, err: %+v", err)
@@ -456,6 +949,98 @@ func (r *Reconciler) patchSecret(ctx context.Context, name, namespace string, da
 	return err
 }
 
+// replaceSecret deletes the secret and creates a new secret with the same name, labels,
+// owner references and type with the new data and annotations. The data of an immutable
+// secret can't be updated, so it's replaced instead.
+func (r *Reconciler) replaceSecret(ctx context.Context, secret *v1.Secret, data map[string][]byte, annotations map[string]string, immutable bool) error {
+	newSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secret.Name,
+			Namespace:       secret.Namespace,
+			Labels:          secret.Labels,
+			Annotations:     annotations,
+			OwnerReferences: secret.OwnerReferences,
+		},
+		Type: secret.Type,
+		Data: data,
+	}
+	if immutable {
+		newSecret.Immutable = &immutable
+	}
+
+	// the preconditions ensure the secret isn't deleted if it has been modified since it was read
+	uid, resourceVersion := secret.UID, secret.ResourceVersion
+	err := r.kubeClient.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid, ResourceVersion: &resourceVersion},
+	})
+	// the secret could have been deleted in a previous attempt that failed to create the new secret
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s/%s, err: %+v", secret.Namespace, secret.Name, err)
+	}
+	if _, err = r.kubeClient.CoreV1().Secrets(secret.Namespace).Create(ctx, newSecret, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create replacement secret %s/%s, err: %+v", secret.Namespace, secret.Name, err)
+	}
+	klog.InfoS("successfully replaced Kubernetes secret", "secret", klog.KObj(secret), "controller", "rotation")
+	return nil
+}
+
+// patchConfigMap patches configmap with the new data and annotations and returns error if any
+func (r *Reconciler) patchConfigMap(ctx context.Context, name, namespace string, data map[string]string, binaryData map[string][]byte, annotations map[string]string) error {
+	// if the configmap doesn't exist in the informer cache, the secretproviderclasspodstatus
+	// controller will recreate it as part of the reconcile operation
+	configMap, err := r.store.GetConfigMap(name, namespace)
+	if err != nil {
+		return err
+	}
+	// the configmap is only updated by the secret provider class that owns it
+	if conflict := controllers.ConfigMapConflict(configMap, annotations[controllers.SecretOwnerAnnotation]); len(conflict) > 0 {
+		return errors.New(conflict)
+	}
+
+	currentDataSHA, err := secretutil.GetSHAFromConfigMap(configMap.Data, configMap.BinaryData)
+	if err != nil {
+		return fmt.Errorf("failed to compute SHA for %s/%s old data, err: %+v", namespace, name, err)
+	}
+	newDataSHA, err := secretutil.GetSHAFromConfigMap(data, binaryData)
+	if err != nil {
+		return fmt.Errorf("failed to compute SHA for %s/%s new data, err: %+v", namespace, name, err)
+	}
+	dataChanged := currentDataSHA != newDataSHA
+	// if the SHA for the current data and new data match and the annotations are up to date
+	// then skip the redundant API call to patch the same data
+	if !dataChanged && !controllers.SecretAnnotationsChanged(configMap.Annotations, annotations) {
+		return nil
+	}
+
+	newConfigMap := configMap.DeepCopy()
+	if newConfigMap.Annotations == nil {
+		newConfigMap.Annotations = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		newConfigMap.Annotations[k] = v
+	}
+	// the last sync time is only updated when the data changes
+	if t, ok := configMap.Annotations[controllers.LastSyncTimeAnnotation]; ok && !dataChanged {
+		newConfigMap.Annotations[controllers.LastSyncTimeAnnotation] = t
+	}
+	newConfigMap.Data = data
+	newConfigMap.BinaryData = binaryData
+	oldData, err := json.Marshal(configMap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal old configmap, err: %+v", err)
+	}
+	newData, err := json.Marshal(newConfigMap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal new configmap, err: %+v", err)
+	}
+	patch, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, configMap)
+	if err != nil {
+		return fmt.Errorf("failed to create patch, err: %+v", err)
+	}
+	_, err = r.kubeClient.CoreV1().ConfigMaps(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
 // runWorker runs a thread that process the queue
 func (r *Reconciler) runWorker() {
 	for r.processNextItem() {
@@ -470,6 +1055,7 @@ func (r *Reconciler) processNextItem() bool {
 		return false
 	}
 	defer r.queue.Done(key)
+	r.reporter.reportRotationQueueDepth(r.queue.Len())
 	spcps, err := r.store.GetSecretProviderClassPodStatus(key.(string))
 	if err != nil {
 		// set the log level to 5 so we don't spam the logs with spc pod status not found
@@ -491,6 +1077,27 @@ func (r *Reconciler) processNextItem() bool {
 		r.handleError(err, key, rateLimited)
 		return true
 	}
+	// the provider is only used for the concurrency limit here, reconcile reports the
+	// error if the secret provider class can't be found
+	var providerName string
+	if spc, spcErr := r.getSecretProviderClass(spcps); spcErr == nil {
+		providerName = string(spc.Spec.Provider)
+	}
+	if !r.providerLimiter.tryAcquire(providerName) {
+		// back off the spc pod status, so the rotations waiting for a busy provider
+		// aren't retried in a tight loop
+		delay := r.providerBusyBackoff.When(key)
+		klog.V(5).InfoS("max concurrent rotations reached for provider, requeueing", "spcps", klog.KObj(spcps), "provider", providerName, "delay", delay, "controller", "rotation")
+		r.queue.AddAfter(key, delay)
+		return true
+	}
+	r.providerBusyBackoff.Forget(key)
+	r.reporter.reportRotationInFlight(providerName, 1)
+	defer func() {
+		r.providerLimiter.release(providerName)
+		r.reporter.reportRotationInFlight(providerName, -1)
+	}()
+
 	klog.V(3).InfoS("reconciler started", "spcps", klog.KObj(spcps), "controller", "rotation")
 	if err = r.reconcile(context.Background(), spcps); err != nil {
 		klog.ErrorS(err, "failed to reconcile spc for pod", "spc",
@@ -498,6 +1105,17 @@ func (r *Reconciler) processNextItem() bool {
 	}
 
 	klog.V(3).InfoS("reconciler completed", "spcps", klog.KObj(spcps), "controller", "rotation")
+	if errors.Is(err, errProviderThrottled) {
+		// back off the spc pod status instead of retrying it after the usual delay, so the
+		// throttled rotations don't keep the provider throttling
+		delay := jitter(r.throttleBackoff.When(key), r.jitterFactor)
+		klog.InfoS("provider throttled rotation, backing off", "spcps", klog.KObj(spcps), "provider", providerName, "delay", delay, "controller", "rotation")
+		r.scheduler.schedule(key.(string), time.Now().Add(delay))
+		r.queue.Forget(key)
+		return true
+	}
+	r.throttleBackoff.Forget(key)
+	r.scheduler.schedule(key.(string), r.rotationPolicy(spcps).nextDue(time.Now(), r.jitterFactor))
 	r.handleError(err, key, false)
 	return true
 }
@@ -22,14 +22,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/client-go/tools/record"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/workqueue"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,9 +47,10 @@ import (
 
 	"k8s.io/client-go/kubernetes/fake"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	"sigs.k8s.io/secrets-store-csi-driver/controllers"
 	secretsStoreFakeClient "sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned/fake"
+	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/k8s"
 	secretsstore "sigs.k8s.io/secrets-store-csi-driver/pkg/secrets-store"
 	providerfake "sigs.k8s.io/secrets-store-csi-driver/provider/fake"
@@ -57,7 +62,7 @@ var (
 
 func setupScheme() (*runtime.Scheme, error) {
 	scheme := runtime.NewScheme()
-	if err := v1alpha1.AddToScheme(scheme); err != nil {
+	if err := secretsstorev1.AddToScheme(scheme); err != nil {
 		return nil, err
 	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
@@ -77,12 +82,21 @@ func newTestReconciler(s *runtime.Scheme, kubeClient kubernetes.Interface, crdCl
 		scheme:               s,
 		providerVolumePath:   socketPath,
 		rotationPollInterval: rotationPollInterval,
+		minInterval:          time.Second,
 		providerClients:      secretsstore.NewPluginClientBuilder(socketPath),
 		queue:                workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		reporter:             newStatsReporter(),
 		eventRecorder:        fakeRecorder,
 		kubeClient:           kubeClient,
 		crdClient:            crdClient,
+		workers:              1,
+		providerLimiter:      newProviderLimiter(0),
+		scheduler:            newDeadlineQueue(),
+		throttleBackoff:      workqueue.NewItemExponentialFailureRateLimiter(throttleBaseDelay, throttleMaxDelay),
+		providerBusyBackoff:  workqueue.NewItemExponentialFailureRateLimiter(providerBusyBaseDelay, providerBusyMaxDelay),
+		watcher:              newProviderWatcher(),
+		expiryFraction:       0.75,
+		expiry:               newExpiryTracker(),
 	}, nil
 }
 
@@ -92,8 +106,8 @@ func TestReconcileError(t *testing.T) {
 	tests := []struct {
 		name                                  string
 		rotationPollInterval                  time.Duration
-		secretProviderClassPodStatusToProcess *v1alpha1.SecretProviderClassPodStatus
-		secretProviderClassToAdd              *v1alpha1.SecretProviderClass
+		secretProviderClassPodStatusToProcess *secretsstorev1.SecretProviderClassPodStatus
+		secretProviderClassToAdd              *secretsstorev1.SecretProviderClass
 		podToAdd                              *v1.Pod
 		socketPath                            string
 		secretToAdd                           *v1.Secret
@@ -104,18 +118,39 @@ func TestReconcileError(t *testing.T) {
 		{
 			name:                 "secret provider class not found",
 			rotationPollInterval: 60 * time.Second,
-			secretProviderClassPodStatusToProcess: &v1alpha1.SecretProviderClassPodStatus{
+			secretProviderClassPodStatusToProcess: &secretsstorev1.SecretProviderClassPodStatus{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "pod1-default-spc1",
 					Namespace: "default",
-					Labels:    map[string]string{v1alpha1.InternalNodeLabel: "nodeName"},
+					Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
 				},
-				Status: v1alpha1.SecretProviderClassPodStatusStatus{
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 					SecretProviderClassName: "spc1",
 					PodName:                 "pod1",
 				},
 			},
-			secretProviderClassToAdd: &v1alpha1.SecretProviderClass{},
+			secretProviderClassToAdd: &secretsstorev1.SecretProviderClass{},
+			podToAdd:                 &v1.Pod{},
+			socketPath:               getTempTestDir(t),
+			secretToAdd:              &v1.Secret{},
+			expectedErr:              true,
+		},
+		{
+			name:                 "cluster secret provider class not found",
+			rotationPollInterval: 60 * time.Second,
+			secretProviderClassPodStatusToProcess: &secretsstorev1.SecretProviderClassPodStatus{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1-default-cluster-cspc1",
+					Namespace: "default",
+					Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+				},
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+					SecretProviderClassName: "cspc1",
+					SecretProviderClassKind: secretsstorev1.ClusterSecretProviderClassKind,
+					PodName:                 "pod1",
+				},
+			},
+			secretProviderClassToAdd: &secretsstorev1.SecretProviderClass{},
 			podToAdd:                 &v1.Pod{},
 			socketPath:               getTempTestDir(t),
 			secretToAdd:              &v1.Secret{},
@@ -124,26 +159,26 @@ func TestReconcileError(t *testing.T) {
 		{
 			name:                 "failed to get pod",
 			rotationPollInterval: 60 * time.Second,
-			secretProviderClassPodStatusToProcess: &v1alpha1.SecretProviderClassPodStatus{
+			secretProviderClassPodStatusToProcess: &secretsstorev1.SecretProviderClassPodStatus{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "pod1-default-spc1",
 					Namespace: "default",
-					Labels:    map[string]string{v1alpha1.InternalNodeLabel: "nodeName"},
+					Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
 				},
-				Status: v1alpha1.SecretProviderClassPodStatusStatus{
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 					SecretProviderClassName: "spc1",
 					PodName:                 "pod1",
 				},
 			},
-			secretProviderClassToAdd: &v1alpha1.SecretProviderClass{
+			secretProviderClassToAdd: &secretsstorev1.SecretProviderClass{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "spc1",
 					Namespace: "default",
 				},
-				Spec: v1alpha1.SecretProviderClassSpec{
-					SecretObjects: []*v1alpha1.SecretObject{
+				Spec: secretsstorev1.SecretProviderClassSpec{
+					SecretObjects: []*secretsstorev1.SecretObject{
 						{
-							Data: []*v1alpha1.SecretObjectData{
+							Data: []*secretsstorev1.SecretObjectData{
 								{
 									ObjectName: "object1",
 									Key:        "foo",
@@ -161,27 +196,27 @@ func TestReconcileError(t *testing.T) {
 		{
 			name:                 "failed to get NodePublishSecretRef secret",
 			rotationPollInterval: 60 * time.Second,
-			secretProviderClassPodStatusToProcess: &v1alpha1.SecretProviderClassPodStatus{
+			secretProviderClassPodStatusToProcess: &secretsstorev1.SecretProviderClassPodStatus{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "pod1-default-spc1",
 					Namespace: "default",
-					Labels:    map[string]string{v1alpha1.InternalNodeLabel: "nodeName"},
+					Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
 				},
-				Status: v1alpha1.SecretProviderClassPodStatusStatus{
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 					SecretProviderClassName: "spc1",
 					PodName:                 "pod1",
 					TargetPath:              getTestTargetPath(t, "foo", "csi-volume"),
 				},
 			},
-			secretProviderClassToAdd: &v1alpha1.SecretProviderClass{
+			secretProviderClassToAdd: &secretsstorev1.SecretProviderClass{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "spc1",
 					Namespace: "default",
 				},
-				Spec: v1alpha1.SecretProviderClassSpec{
-					SecretObjects: []*v1alpha1.SecretObject{
+				Spec: secretsstorev1.SecretProviderClassSpec{
+					SecretObjects: []*secretsstorev1.SecretObject{
 						{
-							Data: []*v1alpha1.SecretObjectData{
+							Data: []*secretsstorev1.SecretObjectData{
 								{
 									ObjectName: "object1",
 									Key:        "foo",
@@ -223,17 +258,17 @@ func TestReconcileError(t *testing.T) {
 		{
 			name:                 "failed to validate targetpath UID",
 			rotationPollInterval: 60 * time.Second,
-			secretProviderClassPodStatusToProcess: &v1alpha1.SecretProviderClassPodStatus{
+			secretProviderClassPodStatusToProcess: &secretsstorev1.SecretProviderClassPodStatus{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "pod1-default-spc1",
 					Namespace: "default",
-					Labels:    map[string]string{v1alpha1.InternalNodeLabel: "nodeName"},
+					Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
 				},
-				Status: v1alpha1.SecretProviderClassPodStatusStatus{
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 					SecretProviderClassName: "spc1",
 					PodName:                 "pod1",
 					TargetPath:              getTestTargetPath(t, "bad-uid", "csi-volume"),
-					Objects: []v1alpha1.SecretProviderClassObject{
+					Objects: []secretsstorev1.SecretProviderClassObject{
 						{
 							ID:      "secret/object1",
 							Version: "v1",
@@ -241,15 +276,15 @@ func TestReconcileError(t *testing.T) {
 					},
 				},
 			},
-			secretProviderClassToAdd: &v1alpha1.SecretProviderClass{
+			secretProviderClassToAdd: &secretsstorev1.SecretProviderClass{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "spc1",
 					Namespace: "default",
 				},
-				Spec: v1alpha1.SecretProviderClassSpec{
-					SecretObjects: []*v1alpha1.SecretObject{
+				Spec: secretsstorev1.SecretProviderClassSpec{
+					SecretObjects: []*secretsstorev1.SecretObject{
 						{
-							Data: []*v1alpha1.SecretObjectData{
+							Data: []*secretsstorev1.SecretObjectData{
 								{
 									ObjectName: "object1",
 									Key:        "foo",
@@ -296,17 +331,17 @@ func TestReconcileError(t *testing.T) {
 		{
 			name:                 "failed to validate targetpath volume name",
 			rotationPollInterval: 60 * time.Second,
-			secretProviderClassPodStatusToProcess: &v1alpha1.SecretProviderClassPodStatus{
+			secretProviderClassPodStatusToProcess: &secretsstorev1.SecretProviderClassPodStatus{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "pod1-default-spc1",
 					Namespace: "default",
-					Labels:    map[string]string{v1alpha1.InternalNodeLabel: "nodeName"},
+					Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
 				},
-				Status: v1alpha1.SecretProviderClassPodStatusStatus{
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 					SecretProviderClassName: "spc1",
 					PodName:                 "pod1",
 					TargetPath:              getTestTargetPath(t, "foo", "bad-volume-name"),
-					Objects: []v1alpha1.SecretProviderClassObject{
+					Objects: []secretsstorev1.SecretProviderClassObject{
 						{
 							ID:      "secret/object1",
 							Version: "v1",
@@ -314,15 +349,15 @@ func TestReconcileError(t *testing.T) {
 					},
 				},
 			},
-			secretProviderClassToAdd: &v1alpha1.SecretProviderClass{
+			secretProviderClassToAdd: &secretsstorev1.SecretProviderClass{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "spc1",
 					Namespace: "default",
 				},
-				Spec: v1alpha1.SecretProviderClassSpec{
-					SecretObjects: []*v1alpha1.SecretObject{
+				Spec: secretsstorev1.SecretProviderClassSpec{
+					SecretObjects: []*secretsstorev1.SecretObject{
 						{
-							Data: []*v1alpha1.SecretObjectData{
+							Data: []*secretsstorev1.SecretObjectData{
 								{
 									ObjectName: "object1",
 									Key:        "foo",
@@ -369,27 +404,27 @@ func TestReconcileError(t *testing.T) {
 		{
 			name:                 "failed to lookup provider client",
 			rotationPollInterval: 60 * time.Second,
-			secretProviderClassPodStatusToProcess: &v1alpha1.SecretProviderClassPodStatus{
+			secretProviderClassPodStatusToProcess: &secretsstorev1.SecretProviderClassPodStatus{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "pod1-default-spc1",
 					Namespace: "default",
-					Labels:    map[string]string{v1alpha1.InternalNodeLabel: "nodeName"},
+					Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
 				},
-				Status: v1alpha1.SecretProviderClassPodStatusStatus{
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 					SecretProviderClassName: "spc1",
 					PodName:                 "pod1",
 					TargetPath:              getTestTargetPath(t, "foo", "csi-volume"),
 				},
 			},
-			secretProviderClassToAdd: &v1alpha1.SecretProviderClass{
+			secretProviderClassToAdd: &secretsstorev1.SecretProviderClass{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "spc1",
 					Namespace: "default",
 				},
-				Spec: v1alpha1.SecretProviderClassSpec{
-					SecretObjects: []*v1alpha1.SecretObject{
+				Spec: secretsstorev1.SecretProviderClassSpec{
+					SecretObjects: []*secretsstorev1.SecretObject{
 						{
-							Data: []*v1alpha1.SecretObjectData{
+							Data: []*secretsstorev1.SecretObjectData{
 								{
 									ObjectName: "object1",
 									Key:        "foo",
@@ -459,6 +494,14 @@ func TestReconcileError(t *testing.T) {
 
 			err = testReconciler.reconcile(context.TODO(), test.secretProviderClassPodStatusToProcess)
 			g.Expect(err).To(HaveOccurred())
+
+			// validate the failure is reflected in the spc pod status conditions
+			spcps := test.secretProviderClassPodStatusToProcess
+			updatedSPCPodStatus, err := crdClient.SecretsstoreV1().SecretProviderClassPodStatuses(spcps.Namespace).Get(context.TODO(), spcps.Name, metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(meta.IsStatusConditionFalse(updatedSPCPodStatus.Status.Conditions, secretsstorev1.ConditionTypeRotationSucceeded)).To(BeTrue())
+			g.Expect(updatedSPCPodStatus.Status.RotationHistory).To(HaveLen(1))
+			g.Expect(updatedSPCPodStatus.Status.RotationHistory[0].Result).To(Equal(secretsstorev1.RotationResultFailed))
 			if test.expectedErrorEvents {
 				g.Expect(len(fakeRecorder.Events)).ToNot(BeNumerically("==", 0))
 				for len(fakeRecorder.Events) > 0 {
@@ -469,6 +512,596 @@ func TestReconcileError(t *testing.T) {
 	}
 }
 
+func TestPatchSecretAnnotationsAndImmutable(t *testing.T) {
+	g := NewWithT(t)
+	tr := true
+
+	tests := []struct {
+		name                string
+		secretToAdd         *v1.Secret
+		data                map[string][]byte
+		annotations         map[string]string
+		immutable           bool
+		expectedAnnotations map[string]string
+		expectedImmutable   bool
+	}{
+		{
+			name: "data unchanged, provenance annotations added and last sync time kept",
+			secretToAdd: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "secret1",
+					Namespace:   "default",
+					Labels:      map[string]string{controllers.SecretManagedLabel: "true"},
+					Annotations: map[string]string{controllers.LastSyncTimeAnnotation: "2021-04-01T10:00:00Z"},
+				},
+				Data: map[string][]byte{"key1": []byte("value1")},
+			},
+			data: map[string][]byte{"key1": []byte("value1")},
+			annotations: map[string]string{
+				controllers.ObjectVersionsAnnotation: `{"secret/object1":"v1"}`,
+				controllers.LastSyncTimeAnnotation:   "2021-04-02T10:00:00Z",
+			},
+			expectedAnnotations: map[string]string{
+				controllers.ObjectVersionsAnnotation: `{"secret/object1":"v1"}`,
+				controllers.LastSyncTimeAnnotation:   "2021-04-01T10:00:00Z",
+			},
+		},
+		{
+			name: "data changed for immutable secret object, secret is replaced",
+			secretToAdd: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret1",
+					Namespace: "default",
+					Labels:    map[string]string{controllers.SecretManagedLabel: "true"},
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs1", UID: "f39da13d-7246-4ef5-aed4-a6905f82cbcd"},
+					},
+				},
+				Data:      map[string][]byte{"key1": []byte("value1")},
+				Immutable: &tr,
+			},
+			data:                map[string][]byte{"key1": []byte("value2")},
+			annotations:         map[string]string{controllers.LastSyncTimeAnnotation: "2021-04-02T10:00:00Z"},
+			immutable:           true,
+			expectedAnnotations: map[string]string{controllers.LastSyncTimeAnnotation: "2021-04-02T10:00:00Z"},
+			expectedImmutable:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme, err := setupScheme()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			kubeClient := fake.NewSimpleClientset(test.secretToAdd)
+			crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+			testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, "", false)
+			g.Expect(err).NotTo(HaveOccurred())
+			err = testReconciler.store.Run(wait.NeverStop)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			err = testReconciler.patchSecret(context.TODO(), "secret1", v1.NamespaceDefault, test.data, test.annotations, test.immutable)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			secret, err := kubeClient.CoreV1().Secrets(v1.NamespaceDefault).Get(context.TODO(), "secret1", metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(secret.Data).To(Equal(test.data))
+			g.Expect(secret.Annotations).To(Equal(test.expectedAnnotations))
+			g.Expect(secret.Labels).To(Equal(test.secretToAdd.Labels))
+			g.Expect(secret.OwnerReferences).To(Equal(test.secretToAdd.OwnerReferences))
+			g.Expect(secret.Immutable != nil && *secret.Immutable).To(Equal(test.expectedImmutable))
+		})
+	}
+}
+
+func TestPatchSecretOwnedByAnotherClass(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	secretToAdd := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "secret1",
+			Namespace:   "default",
+			Labels:      map[string]string{controllers.SecretManagedLabel: "true"},
+			Annotations: map[string]string{controllers.SecretOwnerAnnotation: "SecretProviderClass/spc2"},
+		},
+		Data: map[string][]byte{"key1": []byte("value1")},
+	}
+	kubeClient := fake.NewSimpleClientset(secretToAdd)
+	crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	err = testReconciler.patchSecret(context.TODO(), "secret1", v1.NamespaceDefault, map[string][]byte{"key1": []byte("value2")},
+		map[string]string{controllers.SecretOwnerAnnotation: "SecretProviderClass/spc1"}, false)
+	g.Expect(err).To(HaveOccurred())
+
+	// the secret data is unchanged
+	secret, err := kubeClient.CoreV1().Secrets(v1.NamespaceDefault).Get(context.TODO(), "secret1", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secret.Data).To(Equal(secretToAdd.Data))
+}
+
+func TestHandleSecretProviderClassUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newSPCPodStatus := func(name, kind, spcName string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				SecretProviderClassName: spcName,
+				SecretProviderClassKind: kind,
+			},
+		}
+	}
+	kubeClient := fake.NewSimpleClientset()
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(
+		newSPCPodStatus("pod1-default-spc1", secretsstorev1.SecretProviderClassKind, "spc1"),
+		newSPCPodStatus("pod2-default-spc1", secretsstorev1.SecretProviderClassKind, "spc1"),
+		newSPCPodStatus("pod3-default-spc2", secretsstorev1.SecretProviderClassKind, "spc2"),
+		newSPCPodStatus("pod4-default-cspc1", secretsstorev1.ClusterSecretProviderClassKind, "spc1"),
+	)
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	oldSPC := &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default", ResourceVersion: "1"},
+		Spec: secretsstorev1.SecretProviderClassSpec{
+			Provider:   "provider1",
+			Parameters: map[string]string{"objects": "object1"},
+		},
+	}
+	// resync without spec change
+	newSPC := oldSPC.DeepCopy()
+	testReconciler.handleSecretProviderClassUpdate(oldSPC, newSPC)
+	g.Expect(testReconciler.queue.Len()).To(Equal(0))
+
+	newSPC.ResourceVersion = "2"
+	newSPC.Spec.Parameters["objects"] = "object1,object2"
+	testReconciler.handleSecretProviderClassUpdate(oldSPC, newSPC)
+	g.Expect(testReconciler.queue.Len()).To(Equal(2))
+	for i := 0; i < 2; i++ {
+		key, _ := testReconciler.queue.Get()
+		g.Expect(key).To(BeElementOf("default/pod1-default-spc1", "default/pod2-default-spc1"))
+		testReconciler.queue.Done(key)
+	}
+
+	oldCSPC := &secretsstorev1.ClusterSecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", ResourceVersion: "1"},
+	}
+	newCSPC := oldCSPC.DeepCopy()
+	newCSPC.Spec.Provider = "provider1"
+	testReconciler.handleSecretProviderClassUpdate(oldCSPC, newCSPC)
+	g.Expect(testReconciler.queue.Len()).To(Equal(1))
+	key, _ := testReconciler.queue.Get()
+	g.Expect(key).To(Equal("default/pod4-default-cspc1"))
+}
+
+func TestHandleNodePublishSecretRefUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newPod := func(name, secretName string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PodSpec{
+				NodeName: "nodeName",
+				Volumes: []v1.Volume{
+					{
+						Name: "secrets-store-inline",
+						VolumeSource: v1.VolumeSource{
+							CSI: &v1.CSIVolumeSource{
+								Driver:               "secrets-store.csi.k8s.io",
+								VolumeAttributes:     map[string]string{"secretProviderClass": "spc1"},
+								NodePublishSecretRef: &v1.LocalObjectReference{Name: secretName},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	newSPCPodStatus := func(podName string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName + "-default-spc1",
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				PodName:                 podName,
+				SecretProviderClassName: "spc1",
+				SecretProviderClassKind: secretsstorev1.SecretProviderClassKind,
+			},
+		}
+	}
+	kubeClient := fake.NewSimpleClientset(newPod("pod1", "secret1"), newPod("pod2", "secret1"), newPod("pod3", "secret2"))
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(
+		newSPCPodStatus("pod1"),
+		newSPCPodStatus("pod2"),
+		newSPCPodStatus("pod3"),
+	)
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	oldSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret1", Namespace: "default", ResourceVersion: "1"},
+		Data:       map[string][]byte{"clientsecret": []byte("old")},
+	}
+	// resync without data change
+	newSecret := oldSecret.DeepCopy()
+	testReconciler.handleNodePublishSecretRefUpdate(oldSecret, newSecret)
+	g.Expect(testReconciler.queue.Len()).To(Equal(0))
+	g.Expect(fakeRecorder.Events).To(BeEmpty())
+
+	newSecret.ResourceVersion = "2"
+	newSecret.Data["clientsecret"] = []byte("new")
+	testReconciler.handleNodePublishSecretRefUpdate(oldSecret, newSecret)
+	g.Expect(testReconciler.queue.Len()).To(Equal(2))
+	for i := 0; i < 2; i++ {
+		key, _ := testReconciler.queue.Get()
+		g.Expect(key).To(BeElementOf("default/pod1-default-spc1", "default/pod2-default-spc1"))
+		testReconciler.queue.Done(key)
+	}
+	g.Expect(fakeRecorder.Events).To(HaveLen(2))
+	for len(fakeRecorder.Events) > 0 {
+		g.Expect(<-fakeRecorder.Events).To(HavePrefix("Normal NodePublishSecretRefChanged node publish secret default/secret1 changed"))
+	}
+}
+
+func TestEnqueueDue(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	disabled := false
+	newSPC := func(name string, policy *secretsstorev1.RotationPolicy) *secretsstorev1.SecretProviderClass {
+		return &secretsstorev1.SecretProviderClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       secretsstorev1.SecretProviderClassSpec{Provider: "provider1", RotationPolicy: policy},
+		}
+	}
+	newPod := func(name, spcName string, annotations map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec: v1.PodSpec{
+				NodeName: "nodeName",
+				Volumes: []v1.Volume{{
+					Name: "secrets-store-inline",
+					VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+						Driver:           "secrets-store.csi.k8s.io",
+						VolumeAttributes: map[string]string{"secretProviderClass": spcName},
+					}},
+				}},
+			},
+		}
+	}
+	newSPCPodStatus := func(podName, spcName string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName + "-default-" + spcName,
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				PodName:                 podName,
+				SecretProviderClassName: spcName,
+			},
+		}
+	}
+
+	kubeClient := fake.NewSimpleClientset(
+		newPod("pod1", "spc1", nil),
+		newPod("pod2", "spc2", nil),
+		newPod("pod3", "spc1", map[string]string{RotationIntervalAnnotation: "5m"}),
+	)
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(
+		newSPC("spc1", &secretsstorev1.RotationPolicy{Interval: &metav1.Duration{Duration: time.Hour}}),
+		newSPC("spc2", &secretsstorev1.RotationPolicy{Enabled: &disabled}),
+		newSPCPodStatus("pod1", "spc1"),
+		newSPCPodStatus("pod2", "spc2"),
+		newSPCPodStatus("pod3", "spc1"),
+	)
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 2*time.Minute, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	dequeue := func() []string {
+		var keys []string
+		for testReconciler.queue.Len() > 0 {
+			key, _ := testReconciler.queue.Get()
+			testReconciler.queue.Done(key)
+			keys = append(keys, key.(string))
+		}
+		return keys
+	}
+
+	now := time.Now()
+	// the deleted spc pod statuses are forgotten
+	testReconciler.scheduler.schedule("default/deleted", now)
+	// the new spc pod statuses are spread across their interval
+	testReconciler.syncSchedule(now)
+	g.Expect(testReconciler.scheduler.list()).To(ConsistOf("default/pod1-default-spc1", "default/pod2-default-spc2", "default/pod3-default-spc1"))
+	due, _ := testReconciler.scheduler.due("default/pod1-default-spc1")
+	g.Expect(due).To(BeTemporally(">=", now))
+	g.Expect(due).To(BeTemporally("<", now.Add(time.Hour)))
+	due, _ = testReconciler.scheduler.due("default/pod3-default-spc1")
+	g.Expect(due).To(BeTemporally("<", now.Add(5*time.Minute)))
+
+	testReconciler.enqueueDue(now.Add(-time.Second))
+	g.Expect(dequeue()).To(BeEmpty())
+
+	testReconciler.enqueueDue(now.Add(time.Hour))
+	g.Expect(dequeue()).To(ConsistOf("default/pod1-default-spc1", "default/pod3-default-spc1"))
+	// rotation is disabled for pod2, so it's checked again after the interval
+	due, ok := testReconciler.scheduler.due("default/pod2-default-spc2")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(due).To(Equal(now.Add(time.Hour + 2*time.Minute)))
+
+	// a secret provider class update reschedules the spc pod statuses with rotation disabled
+	// if they're due later than the interval of the new policy, without rotating them
+	testReconciler.scheduler.schedule("default/pod2-default-spc2", now.Add(10*time.Hour))
+	oldSPC := newSPC("spc2", &secretsstorev1.RotationPolicy{Enabled: &disabled, Interval: &metav1.Duration{Duration: 10 * time.Hour}})
+	testReconciler.handleSecretProviderClassUpdate(oldSPC, newSPC("spc2", &secretsstorev1.RotationPolicy{Enabled: &disabled}))
+	g.Expect(dequeue()).To(BeEmpty())
+	due, _ = testReconciler.scheduler.due("default/pod2-default-spc2")
+	g.Expect(due).To(BeTemporally("<=", time.Now().Add(2*time.Minute)))
+}
+
+func TestProcessNextItemProviderBusy(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	kubeClient := fake.NewSimpleClientset()
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(
+		&secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1-default-spc1",
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				PodName:                 "pod1",
+				SecretProviderClassName: "spc1",
+			},
+		},
+		&secretsstorev1.SecretProviderClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+			Spec:       secretsstorev1.SecretProviderClassSpec{Provider: "provider1"},
+		},
+	)
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	testReconciler.providerLimiter = newProviderLimiter(1)
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// provider1 already has the max number of rotations in flight
+	g.Expect(testReconciler.providerLimiter.tryAcquire("provider1")).To(BeTrue())
+
+	key := "default/pod1-default-spc1"
+	testReconciler.queue.Add(key)
+	g.Expect(testReconciler.processNextItem()).To(BeTrue())
+	g.Expect(testReconciler.scheduler.list()).To(BeEmpty())
+	// the spc pod status is requeued with a backoff to be retried once the provider has capacity
+	g.Expect(testReconciler.providerBusyBackoff.NumRequeues(key)).To(Equal(1))
+	g.Eventually(testReconciler.queue.Len, 5*time.Second, 100*time.Millisecond).Should(Equal(1))
+
+	testReconciler.providerLimiter.release("provider1")
+	g.Expect(testReconciler.processNextItem()).To(BeTrue())
+	// the spc pod status is scheduled for the next rotation
+	g.Expect(testReconciler.scheduler.list()).To(ConsistOf(key))
+	g.Expect(testReconciler.providerLimiter.inFlight).To(BeEmpty())
+	g.Expect(testReconciler.providerBusyBackoff.NumRequeues(key)).To(Equal(0))
+}
+
+func TestProcessNextItemProviderThrottled(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	socketPath := getTempTestDir(t)
+	kubeClient := fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", UID: types.UID("foo")},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{{
+				Name: "csi-volume",
+				VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+				}},
+			}},
+		},
+	})
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(
+		&secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1-default-spc1",
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				SecretProviderClassName: "spc1",
+				PodName:                 "pod1",
+				TargetPath:              getTestTargetPath(t, "foo", "csi-volume"),
+			},
+		},
+		&secretsstorev1.SecretProviderClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+			Spec:       secretsstorev1.SecretProviderClassSpec{Provider: "provider1"},
+		},
+	)
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, socketPath, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	serverEndpoint := fmt.Sprintf("%s/%s.sock", socketPath, "provider1")
+	defer os.Remove(serverEndpoint)
+
+	server, err := providerfake.NewMocKCSIProviderServer(serverEndpoint)
+	g.Expect(err).NotTo(HaveOccurred())
+	server.SetReturnError(status.Error(codes.ResourceExhausted, "rate limit exceeded"))
+	server.Start()
+	defer server.Stop()
+
+	key := "default/pod1-default-spc1"
+	// the backoff doubles each time the provider throttles the rotation
+	for _, delay := range []time.Duration{throttleBaseDelay, 2 * throttleBaseDelay} {
+		testReconciler.queue.Add(key)
+		begin := time.Now()
+		g.Expect(testReconciler.processNextItem()).To(BeTrue())
+		due, ok := testReconciler.scheduler.due(key)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(due).To(BeTemporally(">=", begin.Add(delay)))
+		g.Expect(due).To(BeTemporally("<=", time.Now().Add(delay)))
+		// the throttled spc pod status isn't retried with the usual delay
+		g.Expect(testReconciler.queue.NumRequeues(key)).To(Equal(0))
+		g.Expect(testReconciler.queue.Len()).To(Equal(0))
+	}
+
+	// the backoff is reset once the provider no longer throttles the rotation
+	server.SetReturnError(nil)
+	testReconciler.queue.Add(key)
+	g.Expect(testReconciler.processNextItem()).To(BeTrue())
+	g.Expect(testReconciler.throttleBackoff.NumRequeues(key)).To(Equal(0))
+
+	// 3 warning events for the failed mount requests
+	g.Expect(len(fakeRecorder.Events)).To(BeNumerically("==", 3))
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+}
+
+func TestPatchConfigMap(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name                     string
+		configMapToAdd           *v1.ConfigMap
+		expectedConfigMapData    map[string]string
+		expectedConfigMapBinData map[string][]byte
+		expectedErr              bool
+	}{
+		{
+			name:           "configmap is not found",
+			configMapToAdd: &v1.ConfigMap{},
+			expectedErr:    true,
+		},
+		{
+			name: "configmap is found and data already matches",
+			configMapToAdd: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "configmap1",
+					Namespace:       "default",
+					ResourceVersion: "16172",
+					Labels: map[string]string{
+						controllers.SecretManagedLabel: "true",
+					},
+				},
+				Data: map[string]string{"ca.crt": "cert1"},
+			},
+			expectedConfigMapData: map[string]string{"ca.crt": "cert1"},
+			expectedErr:           false,
+		},
+		{
+			name: "configmap is found and data is updated to latest",
+			configMapToAdd: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "configmap1",
+					Namespace:       "default",
+					ResourceVersion: "16172",
+					Labels: map[string]string{
+						controllers.SecretManagedLabel: "true",
+					},
+				},
+				Data: map[string]string{"ca.crt": "cert1"},
+			},
+			expectedConfigMapData:    map[string]string{"ca.crt": "cert2"},
+			expectedConfigMapBinData: map[string][]byte{"file.bin": {0xff}},
+			expectedErr:              false,
+		},
+		{
+			name: "configmap is owned by another secret provider class",
+			configMapToAdd: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "configmap1",
+					Namespace:       "default",
+					ResourceVersion: "16172",
+					Labels: map[string]string{
+						controllers.SecretManagedLabel: "true",
+					},
+					Annotations: map[string]string{
+						controllers.SecretOwnerAnnotation: "SecretProviderClass/spc2",
+					},
+				},
+				Data: map[string]string{"ca.crt": "cert1"},
+			},
+			expectedConfigMapData: map[string]string{"ca.crt": "cert2"},
+			expectedErr:           true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme, err := setupScheme()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			kubeClient := fake.NewSimpleClientset(test.configMapToAdd)
+			crdClient := secretsStoreFakeClient.NewSimpleClientset()
+
+			testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, "", false)
+			g.Expect(err).NotTo(HaveOccurred())
+			err = testReconciler.store.Run(wait.NeverStop)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			annotations := map[string]string{controllers.SecretOwnerAnnotation: "SecretProviderClass/spc1"}
+			err = testReconciler.patchConfigMap(context.TODO(), "configmap1", v1.NamespaceDefault, test.expectedConfigMapData, test.expectedConfigMapBinData, annotations)
+			if test.expectedErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// check the configmap data is what we expect it to
+			configMap, err := kubeClient.CoreV1().ConfigMaps(v1.NamespaceDefault).Get(context.TODO(), "configmap1", metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(configMap.Data).To(Equal(test.expectedConfigMapData))
+			g.Expect(configMap.BinaryData).To(Equal(test.expectedConfigMapBinData))
+			g.Expect(configMap.Annotations).To(HaveKeyWithValue(controllers.SecretOwnerAnnotation, "SecretProviderClass/spc1"))
+		})
+	}
+}
+
 func TestReconcileNoError(t *testing.T) {
 	g := NewWithT(t)
 
@@ -505,17 +1138,17 @@ func TestReconcileNoError(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		secretProviderClassPodStatusToProcess := &v1alpha1.SecretProviderClassPodStatus{
+		secretProviderClassPodStatusToProcess := &secretsstorev1.SecretProviderClassPodStatus{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "pod1-default-spc1",
 				Namespace: "default",
-				Labels:    map[string]string{v1alpha1.InternalNodeLabel: "nodeName"},
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
 			},
-			Status: v1alpha1.SecretProviderClassPodStatusStatus{
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 				SecretProviderClassName: "spc1",
 				PodName:                 "pod1",
 				TargetPath:              getTestTargetPath(t, "foo", "csi-volume"),
-				Objects: []v1alpha1.SecretProviderClassObject{
+				Objects: []secretsstorev1.SecretProviderClassObject{
 					{
 						ID:      "secret/object1",
 						Version: "v1",
@@ -523,15 +1156,15 @@ func TestReconcileNoError(t *testing.T) {
 				},
 			},
 		}
-		secretProviderClassToAdd := &v1alpha1.SecretProviderClass{
+		secretProviderClassToAdd := &secretsstorev1.SecretProviderClass{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "spc1",
 				Namespace: "default",
 			},
-			Spec: v1alpha1.SecretProviderClassSpec{
-				SecretObjects: []*v1alpha1.SecretObject{
+			Spec: secretsstorev1.SecretProviderClassSpec{
+				SecretObjects: []*secretsstorev1.SecretObject{
 					{
-						Data: []*v1alpha1.SecretObjectData{
+						Data: []*secretsstorev1.SecretObjectData{
 							{
 								ObjectName: "object1",
 								Key:        "foo",
@@ -607,10 +1240,15 @@ func TestReconcileNoError(t *testing.T) {
 		g.Expect(err).NotTo(HaveOccurred())
 
 		// validate the secret provider class pod status versions have been updated
-		updatedSPCPodStatus := &v1alpha1.SecretProviderClassPodStatus{}
-		updatedSPCPodStatus, err = crdClient.SecretsstoreV1alpha1().SecretProviderClassPodStatuses(v1.NamespaceDefault).Get(context.TODO(), "pod1-default-spc1", metav1.GetOptions{})
+		updatedSPCPodStatus := &secretsstorev1.SecretProviderClassPodStatus{}
+		updatedSPCPodStatus, err = crdClient.SecretsstoreV1().SecretProviderClassPodStatuses(v1.NamespaceDefault).Get(context.TODO(), "pod1-default-spc1", metav1.GetOptions{})
 		g.Expect(err).NotTo(HaveOccurred())
-		g.Expect(updatedSPCPodStatus.Status.Objects).To(Equal([]v1alpha1.SecretProviderClassObject{{ID: "secret/object1", Version: "v2"}}))
+		g.Expect(updatedSPCPodStatus.Status.Objects).To(Equal([]secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v2"}}))
+		g.Expect(updatedSPCPodStatus.Status.LastRotationTime).NotTo(BeNil())
+		g.Expect(meta.IsStatusConditionTrue(updatedSPCPodStatus.Status.Conditions, secretsstorev1.ConditionTypeRotationSucceeded)).To(BeTrue())
+		g.Expect(updatedSPCPodStatus.Status.RotationHistory).To(HaveLen(1))
+		g.Expect(updatedSPCPodStatus.Status.RotationHistory[0].Result).To(Equal(secretsstorev1.RotationResultSucceeded))
+		g.Expect(updatedSPCPodStatus.Status.RotationHistory[0].Objects).To(Equal([]secretsstorev1.RotationHistoryObject{{ID: "secret/object1", OldVersion: "v1", NewVersion: "v2"}}))
 
 		// validate the secret data has been updated to the latest value
 		updatedSecret := &v1.Secret{}
@@ -650,6 +1288,88 @@ func TestReconcileNoError(t *testing.T) {
 	}
 }
 
+func TestReconcileConcurrentPodsOfSameSPC(t *testing.T) {
+	g := NewWithT(t)
+
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+			Spec: v1.PodSpec{
+				ServiceAccountName: "sa1",
+				Volumes: []v1.Volume{{
+					Name: "csi-volume",
+					VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+						Driver:           "secrets-store.csi.k8s.io",
+						VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+					}},
+				}},
+			},
+		}
+	}
+	newSPCPodStatus := func(podName string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName + "-default-spc1",
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				SecretProviderClassName: "spc1",
+				PodName:                 podName,
+				TargetPath:              getTestTargetPath(t, podName+"-uid", "csi-volume"),
+			},
+		}
+	}
+	spcps1, spcps2 := newSPCPodStatus("pod1"), newSPCPodStatus("pod2")
+
+	socketPath := getTempTestDir(t)
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+	kubeClient := fake.NewSimpleClientset(newPod("pod1"), newPod("pod2"))
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(spcps1, spcps2, &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+		Spec: secretsstorev1.SecretProviderClassSpec{
+			Provider:   "provider1",
+			Parameters: map[string]string{"objects": "object1"},
+		},
+	})
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, socketPath, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	serverEndpoint := fmt.Sprintf("%s/%s.sock", socketPath, "provider1")
+	defer os.Remove(serverEndpoint)
+	server, err := providerfake.NewMocKCSIProviderServer(serverEndpoint)
+	g.Expect(err).NotTo(HaveOccurred())
+	server.SetObjects(map[string]string{"secret/object1": "v1"})
+	server.Start()
+	defer server.Stop()
+
+	// the pods are rotated by different workers at the same time
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, spcps := range []*secretsstorev1.SecretProviderClassPodStatus{spcps1, spcps2} {
+		wg.Add(1)
+		go func(i int, spcps *secretsstorev1.SecretProviderClassPodStatus) {
+			defer wg.Done()
+			errs[i] = testReconciler.reconcile(context.TODO(), spcps)
+		}(i, spcps)
+	}
+	wg.Wait()
+	g.Expect(errs).To(Equal([]error{nil, nil}))
+
+	// the pod attributes aren't added to the parameters of the cached spc
+	spc, err := testReconciler.store.GetSecretProviderClass("spc1", "default")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(spc.Spec.Parameters).To(Equal(map[string]string{"objects": "object1"}))
+
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+}
+
 func TestPatchSecret(t *testing.T) {
 	g := NewWithT(t)
 
@@ -732,7 +1452,7 @@ func TestPatchSecret(t *testing.T) {
 			err = testReconciler.store.Run(wait.NeverStop)
 			g.Expect(err).NotTo(HaveOccurred())
 
-			err = testReconciler.patchSecret(context.TODO(), test.secretName, v1.NamespaceDefault, test.expectedSecretData)
+			err = testReconciler.patchSecret(context.TODO(), test.secretName, v1.NamespaceDefault, test.expectedSecretData, nil, false)
 			if test.expectedErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -750,6 +1470,47 @@ func TestPatchSecret(t *testing.T) {
 	}
 }
 
+func TestUpdateStatus(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	spcps := newTestSPCPodStatus("pod1")
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(spcps)
+	testReconciler, err := newTestReconciler(scheme, fake.NewSimpleClientset(), crdClient, 60*time.Second, "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	countUpdates := func() int {
+		updates := 0
+		for _, action := range crdClient.Actions() {
+			if action.GetVerb() == "update" {
+				updates++
+			}
+		}
+		return updates
+	}
+
+	// the conditions and the rotation history are written with a single update
+	changed := []secretsstorev1.RotationHistoryObject{{ID: "secret/object1", NewVersion: "v1"}}
+	testReconciler.updateStatus(context.TODO(), spcps.DeepCopy(),
+		conditionChange(secretsstorev1.ConditionTypePostRotationActionSucceeded, metav1.ConditionTrue, secretsstorev1.RolloutTriggeredReason, ""),
+		rotationConditionChange(internalerrors.FailedToRotate, nil),
+		rotationHistoryChange(changed, internalerrors.FailedToRotate, nil),
+		nil,
+	)
+	g.Expect(countUpdates()).To(Equal(1))
+	latest, err := crdClient.SecretsstoreV1().SecretProviderClassPodStatuses("default").Get(context.TODO(), spcps.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(latest.Status.Conditions).To(HaveLen(2))
+	g.Expect(latest.Status.RotationHistory).To(HaveLen(1))
+	g.Expect(latest.Status.RotationHistory[0].Objects).To(Equal(changed))
+
+	// unchanged conditions aren't written again
+	testReconciler.updateStatus(context.TODO(), latest, rotationConditionChange(internalerrors.FailedToRotate, nil))
+	g.Expect(countUpdates()).To(Equal(1))
+}
+
 func TestHandleError(t *testing.T) {
 	g := NewWithT(t)
 
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// deadlineQueue is a priority queue of spc pod status keys ordered by the time they
+// are next due for rotation
+type deadlineQueue struct {
+	mutex sync.Mutex
+	items deadlineHeap
+	// keys indexes the items in the heap by key
+	keys map[string]*deadlineItem
+}
+
+type deadlineItem struct {
+	key string
+	due time.Time
+	// index is the position of the item in the heap
+	index int
+}
+
+func newDeadlineQueue() *deadlineQueue {
+	return &deadlineQueue{keys: make(map[string]*deadlineItem)}
+}
+
+// schedule sets the time the key is next due, adding the key if it isn't in the queue
+func (q *deadlineQueue) schedule(key string, due time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if item, ok := q.keys[key]; ok {
+		item.due = due
+		heap.Fix(&q.items, item.index)
+		return
+	}
+	item := &deadlineItem{key: key, due: due}
+	heap.Push(&q.items, item)
+	q.keys[key] = item
+}
+
+// remove removes the key from the queue
+func (q *deadlineQueue) remove(key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	item, ok := q.keys[key]
+	if !ok {
+		return
+	}
+	heap.Remove(&q.items, item.index)
+	delete(q.keys, key)
+}
+
+// popDue removes and returns the keys that are due at now, earliest first
+func (q *deadlineQueue) popDue(now time.Time) []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var keys []string
+	for len(q.items) > 0 && !q.items[0].due.After(now) {
+		item := heap.Pop(&q.items).(*deadlineItem)
+		delete(q.keys, item.key)
+		keys = append(keys, item.key)
+	}
+	return keys
+}
+
+// due returns the time the key is next due and if the key is in the queue
+func (q *deadlineQueue) due(key string) (time.Time, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	item, ok := q.keys[key]
+	if !ok {
+		return time.Time{}, false
+	}
+	return item.due, true
+}
+
+// list returns the keys in the queue in no particular order
+func (q *deadlineQueue) list() []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	keys := make([]string, 0, len(q.keys))
+	for key := range q.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// deadlineHeap implements heap.Interface ordered by due time
+type deadlineHeap []*deadlineItem
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].due.Before(h[j].due) }
+func (h deadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *deadlineHeap) Push(x interface{}) {
+	item := x.(*deadlineItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *deadlineHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
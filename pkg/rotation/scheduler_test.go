@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDeadlineQueue(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	q := newDeadlineQueue()
+	q.schedule("key1", now.Add(3*time.Minute))
+	q.schedule("key2", now.Add(time.Minute))
+	q.schedule("key3", now.Add(2*time.Minute))
+	q.schedule("key4", now.Add(4*time.Minute))
+	g.Expect(q.list()).To(ConsistOf("key1", "key2", "key3", "key4"))
+
+	g.Expect(q.popDue(now)).To(BeEmpty())
+	g.Expect(q.popDue(now.Add(2 * time.Minute))).To(Equal([]string{"key2", "key3"}))
+
+	// rescheduling a key moves it in the queue
+	q.schedule("key4", now.Add(time.Minute))
+	due, ok := q.due("key4")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(due).To(Equal(now.Add(time.Minute)))
+
+	q.remove("key1")
+	q.remove("key5")
+	_, ok = q.due("key1")
+	g.Expect(ok).To(BeFalse())
+
+	g.Expect(q.popDue(now.Add(time.Hour))).To(Equal([]string{"key4"}))
+	g.Expect(q.list()).To(BeEmpty())
+}
@@ -16,6 +16,8 @@ package rotation
 import (
 	"context"
 	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"go.opentelemetry.io/otel/api/global"
 	"go.opentelemetry.io/otel/api/metric"
@@ -30,17 +32,31 @@ var (
 	rotationReconcileTotal      metric.Int64Counter
 	rotationReconcileErrorTotal metric.Int64Counter
 	rotationReconcileDuration   metric.Float64ValueRecorder
+	rotationInFlight            metric.Int64UpDownCounter
 	runtimeOS                   = runtime.GOOS
 )
 
 type reporter struct {
 	meter metric.Meter
+	// queueDepth is the last reported rotation queue depth, observed by the
+	// rotation_queue_depth gauge
+	queueDepth int64
+
+	expiryMu sync.Mutex
+	// objectsNearExpiry and objectsExpired are the last reported number of mounted objects
+	// near or past expiry by provider, observed by the rotation_objects_near_expiry and
+	// rotation_objects_expired gauges
+	objectsNearExpiry map[string]int64
+	objectsExpired    map[string]int64
 }
 
 type StatsReporter interface {
 	reportRotationCtMetric(provider string, wasRotated bool)
 	reportRotationErrorCtMetric(provider, errType string, wasRotated bool)
 	reportRotationDuration(duration float64)
+	reportRotationQueueDepth(depth int)
+	reportRotationInFlight(provider string, delta int64)
+	reportObjectExpiry(nearExpiry, expired map[string]int64)
 }
 
 func newStatsReporter() StatsReporter {
@@ -48,7 +64,18 @@ func newStatsReporter() StatsReporter {
 	rotationReconcileTotal = metric.Must(meter).NewInt64Counter("total_rotation_reconcile", metric.WithDescription("Total number of rotation reconciles"))
 	rotationReconcileErrorTotal = metric.Must(meter).NewInt64Counter("total_rotation_reconcile_error", metric.WithDescription("Total number of rotation reconciles with error"))
 	rotationReconcileDuration = metric.Must(meter).NewFloat64ValueRecorder("rotation_reconcile_duration_sec", metric.WithDescription("Distribution of how long it took to rotate secrets-store content for pods"))
-	return &reporter{meter: meter}
+	rotationInFlight = metric.Must(meter).NewInt64UpDownCounter("rotation_inflight", metric.WithDescription("Number of rotation reconciles in progress"))
+	r := &reporter{meter: meter}
+	metric.Must(meter).NewInt64ValueObserver("rotation_queue_depth", func(_ context.Context, result metric.Int64ObserverResult) {
+		result.Observe(atomic.LoadInt64(&r.queueDepth), label.String(osTypeKey, runtimeOS))
+	}, metric.WithDescription("Number of spc pod statuses waiting in the rotation queue"))
+	metric.Must(meter).NewInt64ValueObserver("rotation_objects_near_expiry", func(_ context.Context, result metric.Int64ObserverResult) {
+		r.observeObjectExpiry(result, func() map[string]int64 { return r.objectsNearExpiry })
+	}, metric.WithDescription("Number of mounted objects near expiry that weren't refreshed yet"))
+	metric.Must(meter).NewInt64ValueObserver("rotation_objects_expired", func(_ context.Context, result metric.Int64ObserverResult) {
+		r.observeObjectExpiry(result, func() map[string]int64 { return r.objectsExpired })
+	}, metric.WithDescription("Number of mounted objects past expiry"))
+	return r
 }
 
 func (r *reporter) reportRotationCtMetric(provider string, wasRotated bool) {
@@ -64,3 +91,26 @@ func (r *reporter) reportRotationErrorCtMetric(provider, errType string, wasRota
 func (r *reporter) reportRotationDuration(duration float64) {
 	r.meter.RecordBatch(context.Background(), []label.KeyValue{label.String(osTypeKey, runtimeOS)}, rotationReconcileDuration.Measurement(duration))
 }
+
+func (r *reporter) reportRotationQueueDepth(depth int) {
+	atomic.StoreInt64(&r.queueDepth, int64(depth))
+}
+
+func (r *reporter) reportRotationInFlight(provider string, delta int64) {
+	labels := []label.KeyValue{label.String(providerKey, provider), label.String(osTypeKey, runtimeOS)}
+	rotationInFlight.Add(context.Background(), delta, labels...)
+}
+
+func (r *reporter) reportObjectExpiry(nearExpiry, expired map[string]int64) {
+	r.expiryMu.Lock()
+	defer r.expiryMu.Unlock()
+	r.objectsNearExpiry, r.objectsExpired = nearExpiry, expired
+}
+
+func (r *reporter) observeObjectExpiry(result metric.Int64ObserverResult, counts func() map[string]int64) {
+	r.expiryMu.Lock()
+	defer r.expiryMu.Unlock()
+	for provider, count := range counts() {
+		result.Observe(count, label.String(providerKey, provider), label.String(osTypeKey, runtimeOS))
+	}
+}
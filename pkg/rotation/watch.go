@@ -0,0 +1,418 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	secretsstore "sigs.k8s.io/secrets-store-csi-driver/pkg/secrets-store"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
+	"sigs.k8s.io/secrets-store-csi-driver/provider/v1alpha1"
+)
+
+const (
+	// capabilityCheckInterval is how often the capabilities of a provider are checked
+	capabilityCheckInterval = 5 * time.Minute
+	// capabilityCheckTimeout is the timeout of the Version RPC used to check the capabilities
+	capabilityCheckTimeout = 5 * time.Second
+	// watchRetryBaseDelay and watchRetryMaxDelay bound the backoff before a closed Watch
+	// stream is opened again
+	watchRetryBaseDelay = time.Second
+	watchRetryMaxDelay  = 5 * time.Minute
+)
+
+// providerWatcher tracks the Watch streams opened with the providers that support them.
+// There's one stream per provider, secret provider class and attributes, shared by the
+// spc pod statuses on the node that use them. While the stream of an spc pod status is
+// open, it's only rotated when the provider reports an object change, and the periodic
+// rotation is skipped.
+type providerWatcher struct {
+	mutex sync.Mutex
+	// streams are the Watch streams keyed by provider, secret provider class and attributes
+	streams map[string]*watchStream
+	// capabilities caches whether each provider supports the Watch RPC
+	capabilities map[string]capabilityCheck
+	// changed are the spc pod statuses with an object change that wasn't rotated yet
+	changed map[string]bool
+}
+
+type capabilityCheck struct {
+	watch   bool
+	checked time.Time
+}
+
+type watchStream struct {
+	provider       string
+	attributes     string
+	secrets        string
+	objectVersions map[string]string
+	// keys are the spc pod statuses sharing the stream
+	keys map[string]bool
+	// established is true while the stream is open
+	established bool
+	cancel      context.CancelFunc
+}
+
+func newProviderWatcher() *providerWatcher {
+	return &providerWatcher{
+		streams:      make(map[string]*watchStream),
+		capabilities: make(map[string]capabilityCheck),
+		changed:      make(map[string]bool),
+	}
+}
+
+// skipPoll returns true if the periodic rotation of the spc pod status can be skipped as
+// its stream is open and no object change was reported since the last rotation
+func (w *providerWatcher) skipPoll(key string) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.changed[key] {
+		delete(w.changed, key)
+		return false
+	}
+	for _, s := range w.streams {
+		if s.established && s.keys[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// markChanged records an object change for the spc pod status
+func (w *providerWatcher) markChanged(key string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.changed[key] = true
+}
+
+// keys returns the spc pod statuses sharing the stream
+func (w *providerWatcher) keys(streamKey string) []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	s, ok := w.streams[streamKey]
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// setEstablished sets if the stream is open
+func (w *providerWatcher) setEstablished(streamKey string, established bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if s, ok := w.streams[streamKey]; ok {
+		s.established = established
+	}
+}
+
+// capability returns the cached watch capability of the provider and if it's still valid at now
+func (w *providerWatcher) capability(provider string, now time.Time) (bool, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	c, ok := w.capabilities[provider]
+	if !ok || now.Sub(c.checked) > capabilityCheckInterval {
+		return false, false
+	}
+	return c.watch, true
+}
+
+// setCapability caches the watch capability of the provider
+func (w *providerWatcher) setCapability(provider string, watch bool, now time.Time) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.capabilities[provider] = capabilityCheck{watch: watch, checked: now}
+}
+
+// sync closes the streams that are no longer needed, updates the spc pod statuses sharing
+// the existing streams and returns the contexts of the new streams by key, which must be
+// started. The contexts are cancelled when the stream is no longer needed or ctx is done.
+func (w *providerWatcher) sync(ctx context.Context, desired map[string]*watchStream) map[string]context.Context {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for streamKey, s := range w.streams {
+		d, ok := desired[streamKey]
+		if !ok {
+			s.cancel()
+			delete(w.streams, streamKey)
+			continue
+		}
+		s.keys = d.keys
+	}
+	added := make(map[string]context.Context)
+	for streamKey, d := range desired {
+		if _, ok := w.streams[streamKey]; ok {
+			continue
+		}
+		added[streamKey], d.cancel = context.WithCancel(ctx)
+		w.streams[streamKey] = d
+	}
+	return added
+}
+
+// stream returns the stream matching key
+func (w *providerWatcher) stream(streamKey string) (*watchStream, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	s, ok := w.streams[streamKey]
+	return s, ok
+}
+
+// syncWatches opens a Watch stream for the spc pod statuses on the node whose provider supports
+// it and closes the streams that are no longer used. The streams are closed when ctx is done.
+func (r *Reconciler) syncWatches(ctx context.Context, now time.Time) {
+	spcpsList, err := r.store.ListSecretProviderClassPodStatus()
+	if err != nil {
+		klog.ErrorS(err, "failed to list secret provider class pod status for node", "controller", "rotation")
+		return
+	}
+
+	desired := make(map[string]*watchStream)
+	for _, spcps := range spcpsList {
+		key, err := cache.MetaNamespaceKeyFunc(spcps)
+		if err != nil {
+			continue
+		}
+		if !r.rotationPolicy(spcps).enabled {
+			continue
+		}
+		// errors are reported by the reconcile, the spc pod status is polled in the meantime
+		streamKey, s, err := r.watchStreamFor(spcps)
+		if err != nil {
+			continue
+		}
+		if !r.providerSupportsWatch(ctx, s.provider, now) {
+			continue
+		}
+		if d, ok := desired[streamKey]; ok {
+			d.keys[key] = true
+			continue
+		}
+		s.keys = map[string]bool{key: true}
+		desired[streamKey] = s
+	}
+
+	for streamKey, streamCtx := range r.watcher.sync(ctx, desired) {
+		go r.runWatchStream(streamCtx, streamKey, desired[streamKey].provider)
+	}
+}
+
+// watchStreamFor returns the key and the stream the spc pod status is watched with. The stream
+// attributes are the secret provider class parameters along with the pod namespace and service
+// account name, so the pods with the same identity share the stream.
+func (r *Reconciler) watchStreamFor(spcps *secretsstorev1.SecretProviderClassPodStatus) (string, *watchStream, error) {
+	pod, err := r.store.GetPod(spcps.Status.PodName, spcps.Namespace)
+	if err != nil {
+		return "", nil, err
+	}
+	spc, err := r.getSecretProviderClass(spcps)
+	if err != nil {
+		return "", nil, err
+	}
+	podVol := k8sutil.SPCVolume(pod, spcps.Status.SecretProviderClassKind, spc.Name)
+	if podVol == nil {
+		return "", nil, fmt.Errorf("could not find secret provider class pod status volume for pod %s/%s", pod.Namespace, pod.Name)
+	}
+
+	parameters := make(map[string]string, len(spc.Spec.Parameters)+2)
+	for k, v := range spc.Spec.Parameters {
+		parameters[k] = v
+	}
+	// the stream is shared by the pods with the same identity, so the attributes that
+	// identify a single pod are never sent to the provider or part of the stream key
+	delete(parameters, csipodname)
+	delete(parameters, csipoduid)
+	parameters[csipodnamespace] = pod.Namespace
+	parameters[csipodsa] = pod.Spec.ServiceAccountName
+	attributes, err := json.Marshal(parameters)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal parameters, err: %+v", err)
+	}
+
+	nodePublishSecretData := make(map[string]string)
+	if nodePublishSecretRef := podVol.CSI.NodePublishSecretRef; nodePublishSecretRef != nil {
+		secret, err := r.store.GetNodePublishSecretRefSecret(strings.TrimSpace(nodePublishSecretRef.Name), spcps.Namespace)
+		if err != nil {
+			return "", nil, err
+		}
+		for k, v := range secret.Data {
+			nodePublishSecretData[k] = string(v)
+		}
+	}
+	secrets, err := json.Marshal(nodePublishSecretData)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal node publish secret data, err: %+v", err)
+	}
+
+	objectVersions := make(map[string]string, len(spcps.Status.Objects))
+	for _, obj := range spcps.Status.Objects {
+		objectVersions[obj.ID] = obj.Version
+	}
+
+	provider := string(spc.Spec.Provider)
+	kind := spcps.Status.SecretProviderClassKind
+	if kind == "" {
+		kind = secretsstorev1.SecretProviderClassKind
+	}
+	// the secrets are hashed so the key can be logged
+	streamKey := fmt.Sprintf("%s/%s/%s/%s/%x", provider, kind, spcps.Namespace, spc.Name, sha256.Sum256(append(append(attributes, 0), secrets...)))
+	return streamKey, &watchStream{
+		provider:       provider,
+		attributes:     string(attributes),
+		secrets:        string(secrets),
+		objectVersions: objectVersions,
+	}, nil
+}
+
+// providerSupportsWatch returns true if the provider returns the watch capability. The
+// capability is cached and checked again after capabilityCheckInterval.
+func (r *Reconciler) providerSupportsWatch(ctx context.Context, provider string, now time.Time) bool {
+	if watch, ok := r.watcher.capability(provider, now); ok {
+		return watch
+	}
+	var watch bool
+	client, err := r.providerClients.Get(ctx, provider)
+	if err == nil {
+		c, cancel := context.WithTimeout(ctx, capabilityCheckTimeout)
+		defer cancel()
+		var capabilities []v1alpha1.Capability
+		if capabilities, err = secretsstore.Capabilities(c, client); err == nil {
+			for _, capability := range capabilities {
+				watch = watch || capability == v1alpha1.Capability_CAPABILITY_WATCH
+			}
+		}
+	}
+	if err != nil {
+		klog.V(5).ErrorS(err, "failed to check provider capabilities", "provider", provider, "controller", "rotation")
+	}
+	r.watcher.setCapability(provider, watch, now)
+	return watch
+}
+
+// runWatchStream keeps the stream open until ctx is done. When the stream is closed, the spc
+// pod statuses are polled until it's opened again with backoff.
+func (r *Reconciler) runWatchStream(ctx context.Context, streamKey, provider string) {
+	delay := watchRetryBaseDelay
+	var reopen bool
+	for {
+		established, err := r.watch(ctx, streamKey, reopen)
+		if ctx.Err() != nil {
+			return
+		}
+		r.watcher.setEstablished(streamKey, false)
+		if established {
+			delay, reopen = watchRetryBaseDelay, true
+		}
+		if status.Code(err) == codes.Unimplemented {
+			// the stream is closed on the next sync as the provider doesn't support it anymore
+			r.watcher.setCapability(provider, false, time.Now())
+			klog.InfoS("provider doesn't implement watch, falling back to polling", "stream", streamKey, "controller", "rotation")
+			return
+		}
+		klog.ErrorS(err, "provider watch stream closed, falling back to polling", "stream", streamKey, "retryAfter", delay, "controller", "rotation")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jitter(delay, r.jitterFactor)):
+		}
+		if delay *= 2; delay > watchRetryMaxDelay {
+			delay = watchRetryMaxDelay
+		}
+	}
+}
+
+// watch opens the stream and handles the object changes until the stream is closed. If reopen
+// is set, the spc pod statuses are rotated once the stream is open as changes may have been
+// missed while it was closed. Returns true if the stream was opened.
+func (r *Reconciler) watch(ctx context.Context, streamKey string, reopen bool) (bool, error) {
+	s, ok := r.watcher.stream(streamKey)
+	if !ok {
+		return false, fmt.Errorf("watch stream %s not found", streamKey)
+	}
+	client, err := r.providerClients.Get(ctx, s.provider)
+	if err != nil {
+		return false, err
+	}
+	stream, err := secretsstore.Watch(ctx, client, s.attributes, s.secrets, s.objectVersions)
+	if err != nil {
+		return false, err
+	}
+	r.watcher.setEstablished(streamKey, true)
+	klog.V(3).InfoS("provider watch stream opened", "stream", streamKey, "controller", "rotation")
+	if reopen {
+		for _, key := range r.watcher.keys(streamKey) {
+			r.rotateOnChange(key)
+		}
+	}
+
+	for {
+		change, err := stream.Recv()
+		if err != nil {
+			return true, err
+		}
+		r.handleObjectChange(streamKey, change.GetObjectVersion())
+	}
+}
+
+// handleObjectChange rotates the spc pod statuses sharing the stream that don't have the new
+// version of the object yet
+func (r *Reconciler) handleObjectChange(streamKey string, objectVersion *v1alpha1.ObjectVersion) {
+	id, version := strings.TrimSpace(objectVersion.GetId()), strings.TrimSpace(objectVersion.GetVersion())
+	var count int
+	for _, key := range r.watcher.keys(streamKey) {
+		spcps, err := r.store.GetSecretProviderClassPodStatus(key)
+		if err != nil {
+			continue
+		}
+		var current bool
+		for _, obj := range spcps.Status.Objects {
+			if obj.ID == id && obj.Version == version {
+				current = true
+				break
+			}
+		}
+		if current {
+			continue
+		}
+		r.rotateOnChange(key)
+		count++
+	}
+	klog.V(3).InfoS("provider reported object change", "stream", streamKey, "object", id, "version", version, "count", count, "controller", "rotation")
+}
+
+// rotateOnChange schedules the spc pod status for rotation now. It's rotated once the rotation
+// policy allows it.
+func (r *Reconciler) rotateOnChange(key string) {
+	r.watcher.markChanged(key)
+	r.scheduler.schedule(key, time.Now())
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	secretsStoreFakeClient "sigs.k8s.io/secrets-store-csi-driver/pkg/client/clientset/versioned/fake"
+	providerfake "sigs.k8s.io/secrets-store-csi-driver/provider/fake"
+	"sigs.k8s.io/secrets-store-csi-driver/provider/v1alpha1"
+)
+
+func TestProviderWatcherSkipPoll(t *testing.T) {
+	g := NewWithT(t)
+
+	w := newProviderWatcher()
+	added := w.sync(context.Background(), map[string]*watchStream{
+		"stream1": {provider: "provider1", keys: map[string]bool{"default/spcps1": true}},
+	})
+	g.Expect(added).To(HaveKey("stream1"))
+	streamCtx := added["stream1"]
+	// the spc pod status is polled until the stream is open
+	g.Expect(w.skipPoll("default/spcps1")).To(BeFalse())
+
+	w.setEstablished("stream1", true)
+	g.Expect(w.skipPoll("default/spcps1")).To(BeTrue())
+	g.Expect(w.skipPoll("default/spcps2")).To(BeFalse())
+
+	// an object change is rotated once
+	w.markChanged("default/spcps1")
+	g.Expect(w.skipPoll("default/spcps1")).To(BeFalse())
+	g.Expect(w.skipPoll("default/spcps1")).To(BeTrue())
+
+	// the existing stream is kept and its spc pod statuses are updated
+	added = w.sync(context.Background(), map[string]*watchStream{
+		"stream1": {provider: "provider1", keys: map[string]bool{"default/spcps2": true}},
+	})
+	g.Expect(added).To(BeEmpty())
+	g.Expect(w.keys("stream1")).To(ConsistOf("default/spcps2"))
+
+	// the stream that's no longer needed is closed
+	g.Expect(streamCtx.Err()).NotTo(HaveOccurred())
+	added = w.sync(context.Background(), map[string]*watchStream{})
+	g.Expect(added).To(BeEmpty())
+	g.Expect(w.streams).To(BeEmpty())
+	g.Expect(streamCtx.Err()).To(Equal(context.Canceled))
+	g.Expect(w.skipPoll("default/spcps2")).To(BeFalse())
+}
+
+func TestSyncWatches(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	socketPath := getTempTestDir(t)
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PodSpec{
+				ServiceAccountName: "sa1",
+				Volumes: []v1.Volume{{
+					Name: "csi-volume",
+					VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+						Driver:           "secrets-store.csi.k8s.io",
+						VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+					}},
+				}},
+			},
+		}
+	}
+	newSPCPodStatus := func(podName, version string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName + "-default-spc1",
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				PodName:                 podName,
+				SecretProviderClassName: "spc1",
+				Objects:                 []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: version}},
+			},
+		}
+	}
+	kubeClient := fake.NewSimpleClientset(newPod("pod1"), newPod("pod2"))
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(
+		newSPCPodStatus("pod1", "v1"),
+		newSPCPodStatus("pod2", "v2"),
+		&secretsstorev1.SecretProviderClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+			Spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider1",
+				Parameters: map[string]string{"objects": "object1"},
+			},
+		},
+	)
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, socketPath, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	serverEndpoint := fmt.Sprintf("%s/%s.sock", socketPath, "provider1")
+	defer os.Remove(serverEndpoint)
+
+	server, err := providerfake.NewMocKCSIProviderServer(serverEndpoint)
+	g.Expect(err).NotTo(HaveOccurred())
+	server.Start()
+	defer server.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the provider doesn't support watch
+	testReconciler.syncWatches(ctx, time.Now())
+	g.Expect(testReconciler.watcher.streams).To(BeEmpty())
+
+	// the pods share the stream once the provider supports watch
+	server.SetCapabilities(v1alpha1.Capability_CAPABILITY_WATCH)
+	testReconciler.syncWatches(ctx, time.Now().Add(capabilityCheckInterval+time.Second))
+	g.Expect(testReconciler.watcher.streams).To(HaveLen(1))
+	g.Eventually(server.WatchRequests, 5*time.Second, 100*time.Millisecond).Should(HaveLen(1))
+	attributes := make(map[string]string)
+	g.Expect(json.Unmarshal([]byte(server.WatchRequests()[0].GetAttributes()), &attributes)).To(Succeed())
+	g.Expect(attributes).To(Equal(map[string]string{"objects": "object1", csipodnamespace: "default", csipodsa: "sa1"}))
+
+	// the periodic rotation is skipped while the stream is open
+	g.Eventually(func() bool { return testReconciler.watcher.skipPoll("default/pod1-default-spc1") }, 5*time.Second, 100*time.Millisecond).Should(BeTrue())
+	testReconciler.scheduler.schedule("default/pod1-default-spc1", time.Now())
+	testReconciler.enqueueDue(time.Now())
+	g.Expect(testReconciler.queue.Len()).To(Equal(0))
+
+	// only the pod without the new version is rotated on change
+	server.SendObjectChange("secret/object1", "v2")
+	g.Eventually(func() bool {
+		due, ok := testReconciler.scheduler.due("default/pod1-default-spc1")
+		return ok && !due.After(time.Now())
+	}, 5*time.Second, 100*time.Millisecond).Should(BeTrue())
+	testReconciler.enqueueDue(time.Now())
+	g.Expect(testReconciler.queue.Len()).To(Equal(1))
+	key, _ := testReconciler.queue.Get()
+	g.Expect(key).To(Equal("default/pod1-default-spc1"))
+	testReconciler.queue.Done(key)
+	_, ok := testReconciler.scheduler.due("default/pod2-default-spc1")
+	g.Expect(ok).To(BeFalse())
+
+	// the stream is closed once the spc pod statuses are deleted
+	for _, name := range []string{"pod1-default-spc1", "pod2-default-spc1"} {
+		err = crdClient.SecretsstoreV1().SecretProviderClassPodStatuses("default").Delete(context.TODO(), name, metav1.DeleteOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Eventually(func() int {
+		testReconciler.syncWatches(ctx, time.Now())
+		return len(testReconciler.watcher.streams)
+	}, 5*time.Second, 100*time.Millisecond).Should(Equal(0))
+}
+
+func TestWatchStreamForSharedAcrossPods(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme, err := setupScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	socketPath := getTempTestDir(t)
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+			Spec: v1.PodSpec{
+				ServiceAccountName: "sa1",
+				Volumes: []v1.Volume{{
+					Name: "csi-volume",
+					VolumeSource: v1.VolumeSource{CSI: &v1.CSIVolumeSource{
+						Driver:           "secrets-store.csi.k8s.io",
+						VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+					}},
+				}},
+			},
+		}
+	}
+	newSPCPodStatus := func(podName string) *secretsstorev1.SecretProviderClassPodStatus {
+		return &secretsstorev1.SecretProviderClassPodStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName + "-default-spc1",
+				Namespace: "default",
+				Labels:    map[string]string{secretsstorev1.InternalNodeLabel: "nodeName"},
+			},
+			Status: secretsstorev1.SecretProviderClassPodStatusStatus{
+				PodName:                 podName,
+				SecretProviderClassName: "spc1",
+				TargetPath:              getTestTargetPath(t, podName+"-uid", "csi-volume"),
+				Objects:                 []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1"}},
+			},
+		}
+	}
+	spcps1, spcps2 := newSPCPodStatus("pod1"), newSPCPodStatus("pod2")
+	kubeClient := fake.NewSimpleClientset(newPod("pod1"), newPod("pod2"))
+	crdClient := secretsStoreFakeClient.NewSimpleClientset(spcps1, spcps2, &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+		Spec: secretsstorev1.SecretProviderClassSpec{
+			Provider:   "provider1",
+			Parameters: map[string]string{"objects": "object1"},
+		},
+	})
+
+	testReconciler, err := newTestReconciler(scheme, kubeClient, crdClient, 60*time.Second, socketPath, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = testReconciler.store.Run(wait.NeverStop)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	serverEndpoint := fmt.Sprintf("%s/%s.sock", socketPath, "provider1")
+	defer os.Remove(serverEndpoint)
+	server, err := providerfake.NewMocKCSIProviderServer(serverEndpoint)
+	g.Expect(err).NotTo(HaveOccurred())
+	server.SetObjects(map[string]string{"secret/object1": "v1"})
+	server.Start()
+	defer server.Stop()
+
+	key1, stream1, err := testReconciler.watchStreamFor(spcps1)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// rotating another pod of the spc doesn't change the stream of the first pod
+	g.Expect(testReconciler.reconcile(context.TODO(), spcps2)).To(Succeed())
+	key, stream, err := testReconciler.watchStreamFor(spcps1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(key).To(Equal(key1))
+	g.Expect(stream.attributes).To(Equal(stream1.attributes))
+
+	// the pods with the same identity share the stream
+	key2, _, err := testReconciler.watchStreamFor(spcps2)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(key2).To(Equal(key1))
+
+	attributes := make(map[string]string)
+	g.Expect(json.Unmarshal([]byte(stream.attributes), &attributes)).To(Succeed())
+	g.Expect(attributes).NotTo(HaveKey(csipodname))
+	g.Expect(attributes).NotTo(HaveKey(csipoduid))
+
+	for len(fakeRecorder.Events) > 0 {
+		<-fakeRecorder.Events
+	}
+}
@@ -24,13 +24,16 @@ import (
 	"path/filepath"
 	"runtime"
 
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	csicommon "sigs.k8s.io/secrets-store-csi-driver/pkg/csi-common"
 	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	mount "k8s.io/mount-utils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -49,17 +52,19 @@ type nodeServer struct {
 const (
 	permission os.FileMode = 0644
 
-	csipodname               = "csi.storage.k8s.io/pod.name"
-	csipodnamespace          = "csi.storage.k8s.io/pod.namespace"
-	csipoduid                = "csi.storage.k8s.io/pod.uid"
-	csipodsa                 = "csi.storage.k8s.io/serviceAccount.name"
-	secretProviderClassField = "secretProviderClass"
+	csipodname                      = "csi.storage.k8s.io/pod.name"
+	csipodnamespace                 = "csi.storage.k8s.io/pod.namespace"
+	csipoduid                       = "csi.storage.k8s.io/pod.uid"
+	csipodsa                        = "csi.storage.k8s.io/serviceAccount.name"
+	secretProviderClassField        = k8sutil.SecretProviderClassVolumeAttribute
+	clusterSecretProviderClassField = k8sutil.ClusterSecretProviderClassVolumeAttribute
 )
 
 func (ns *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (npvr *csi.NodePublishVolumeResponse, err error) {
 	var parameters map[string]string
 	var providerName string
 	var podName, podNamespace, podUID string
+	var secretProviderClass, secretProviderClassKind string
 	var targetPath string
 	var mounted bool
 	errorReason := internalerrors.FailedToMount
@@ -73,6 +78,13 @@ func (ns *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 				klog.InfoS("unmounting target path as node publish volume failed", "targetPath", targetPath, "pod", klog.ObjectRef{Namespace: podNamespace, Name: podName})
 				ns.mounter.Unmount(targetPath)
 			}
+			// if the spc pod status exists from a previous mount for the same pod, reflect the
+			// failure in the Mounted condition
+			if secretProviderClass != "" && podName != "" {
+				if cerr := setMountedCondition(ctx, ns.client, secretProviderClassPodStatusName(podName, podNamespace, secretProviderClassKind, secretProviderClass), podNamespace, metav1.ConditionFalse, errorReason, err.Error()); cerr != nil {
+					klog.ErrorS(cerr, "failed to set mounted condition in spc pod status", "pod", klog.ObjectRef{Namespace: podNamespace, Name: podName})
+				}
+			}
 			ns.reporter.ReportNodePublishErrorCtMetric(providerName, errorReason)
 			return
 		}
@@ -99,12 +111,21 @@ func (ns *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	mountFlags := req.GetVolumeCapability().GetMount().GetMountFlags()
 	secrets := req.GetSecrets()
 
-	secretProviderClass := attrib[secretProviderClassField]
+	secretProviderClass = attrib[secretProviderClassField]
 	providerName = attrib["providerName"]
 	podName = attrib[csipodname]
 	podNamespace = attrib[csipodnamespace]
 	podUID = attrib[csipoduid]
 
+	secretProviderClassKind = secretsstorev1.SecretProviderClassKind
+	if clusterSecretProviderClass := attrib[clusterSecretProviderClassField]; clusterSecretProviderClass != "" {
+		if secretProviderClass != "" {
+			return nil, status.Errorf(codes.InvalidArgument, "only one of %s and %s can be set", secretProviderClassField, clusterSecretProviderClassField)
+		}
+		secretProviderClass = clusterSecretProviderClass
+		secretProviderClassKind = secretsstorev1.ClusterSecretProviderClassKind
+	}
+
 	mounted, err = ns.ensureMountPoint(targetPath)
 	if err != nil {
 		// kubelet will not create the CSI NodePublishVolume target directory in 1.20+, in accordance with the CSI specification.
@@ -137,12 +158,15 @@ func (ns *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 	}
 
 	if secretProviderClass == "" {
-		return nil, fmt.Errorf("secretProviderClass is not set")
+		return nil, fmt.Errorf("%s or %s is not set", secretProviderClassField, clusterSecretProviderClassField)
 	}
 
-	spc, err := getSecretProviderItem(ctx, ns.client, secretProviderClass, podNamespace)
+	spc, err := getSecretProviderItem(ctx, ns.client, secretProviderClassKind, secretProviderClass, podNamespace)
 	if err != nil {
 		errorReason = internalerrors.SecretProviderClassNotFound
+		if errors.Is(err, k8sutil.ErrNamespaceNotAllowed) {
+			errorReason = internalerrors.NamespaceNotAllowed
+		}
 		return nil, err
 	}
 	provider, err := getProviderFromSPC(spc)
@@ -191,13 +215,13 @@ func (ns *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		return nil, err
 	}
 	mounted = true
-	var objectVersions map[string]string
-	if objectVersions, errorReason, err = ns.mountSecretsStoreObjectContent(ctx, providerName, string(parametersStr), string(secretStr), targetPath, string(permissionStr), podName); err != nil {
+	var objects []secretsstorev1.SecretProviderClassObject
+	if objects, errorReason, err = ns.mountSecretsStoreObjectContent(ctx, providerName, string(parametersStr), string(secretStr), targetPath, string(permissionStr), podName); err != nil {
 		return nil, fmt.Errorf("failed to mount secrets store objects for pod %s/%s, err: %v", podNamespace, podName, err)
 	}
 
 	// create the secret provider class pod status object
-	if err = createSecretProviderClassPodStatus(ctx, ns.client, podName, podNamespace, podUID, secretProviderClass, targetPath, ns.nodeID, true, objectVersions); err != nil {
+	if err = createSecretProviderClassPodStatus(ctx, ns.client, podName, podNamespace, podUID, secretProviderClassKind, secretProviderClass, targetPath, ns.nodeID, true, objects); err != nil {
 		return nil, fmt.Errorf("failed to create secret provider class pod status for pod %s/%s, err: %v", podNamespace, podName, err)
 	}
 
@@ -276,7 +300,7 @@ func (ns *nodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 	return &csi.NodeUnstageVolumeResponse{}, nil
 }
 
-func (ns *nodeServer) mountSecretsStoreObjectContent(ctx context.Context, providerName, attributes, secrets, targetPath, permission, podName string) (map[string]string, string, error) {
+func (ns *nodeServer) mountSecretsStoreObjectContent(ctx context.Context, providerName, attributes, secrets, targetPath, permission, podName string) ([]secretsstorev1.SecretProviderClassObject, string, error) {
 	if len(attributes) == 0 {
 		return nil, "", errors.New("missing attributes")
 	}
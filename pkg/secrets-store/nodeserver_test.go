@@ -21,7 +21,7 @@ import (
 	"path/filepath"
 	"testing"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/secrets-store/mocks"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/test_utils/tmpdir"
 
@@ -29,6 +29,7 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -117,7 +118,7 @@ func TestNodePublishVolume(t *testing.T) {
 				VolumeContext:    map[string]string{"secretProviderClass": "provider1", csipodname: "pod1", csipodnamespace: "default"},
 			},
 			initObjects: []runtime.Object{
-				&v1alpha1.SecretProviderClass{
+				&secretsstorev1.SecretProviderClass{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "provider1",
 						Namespace: "testns",
@@ -136,7 +137,7 @@ func TestNodePublishVolume(t *testing.T) {
 				VolumeContext:    map[string]string{"secretProviderClass": "provider1", csipodname: "pod1", csipodnamespace: "default"},
 			},
 			initObjects: []runtime.Object{
-				&v1alpha1.SecretProviderClass{
+				&secretsstorev1.SecretProviderClass{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "provider1",
 						Namespace: "default",
@@ -155,12 +156,12 @@ func TestNodePublishVolume(t *testing.T) {
 				VolumeContext:    map[string]string{"secretProviderClass": "provider1", csipodname: "pod1", csipodnamespace: "default"},
 			},
 			initObjects: []runtime.Object{
-				&v1alpha1.SecretProviderClass{
+				&secretsstorev1.SecretProviderClass{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "provider1",
 						Namespace: "default",
 					},
-					Spec: v1alpha1.SecretProviderClassSpec{
+					Spec: secretsstorev1.SecretProviderClassSpec{
 						Provider: "provider1",
 					},
 				},
@@ -177,12 +178,12 @@ func TestNodePublishVolume(t *testing.T) {
 				VolumeContext:    map[string]string{"secretProviderClass": "provider1", csipodname: "pod1", csipodnamespace: "default"},
 			},
 			initObjects: []runtime.Object{
-				&v1alpha1.SecretProviderClass{
+				&secretsstorev1.SecretProviderClass{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "provider1",
 						Namespace: "default",
 					},
-					Spec: v1alpha1.SecretProviderClassSpec{
+					Spec: secretsstorev1.SecretProviderClassSpec{
 						Provider:   "provider1",
 						Parameters: map[string]string{"parameter1": "value1"},
 					},
@@ -191,6 +192,50 @@ func TestNodePublishVolume(t *testing.T) {
 			expectedErr:        true,
 			shouldRetryRemount: true,
 		},
+		{
+			name: "both secret provider class and cluster secret provider class set",
+			nodePublishVolReq: csi.NodePublishVolumeRequest{
+				VolumeCapability: &csi.VolumeCapability{},
+				VolumeId:         "testvolid1",
+				TargetPath:       tmpdir.New(t, "", "ut"),
+				VolumeContext:    map[string]string{"secretProviderClass": "provider1", "clusterSecretProviderClass": "provider1", csipodname: "pod1", csipodnamespace: "default"},
+			},
+			RPCCode:            codes.InvalidArgument,
+			wantsRPCCode:       true,
+			expectedErr:        true,
+			shouldRetryRemount: true,
+		},
+		{
+			name: "cluster secret provider class does not select pod namespace",
+			nodePublishVolReq: csi.NodePublishVolumeRequest{
+				VolumeCapability: &csi.VolumeCapability{},
+				VolumeId:         "testvolid1",
+				TargetPath:       tmpdir.New(t, "", "ut"),
+				VolumeContext:    map[string]string{"clusterSecretProviderClass": "provider1", csipodname: "pod1", csipodnamespace: "default"},
+				Readonly:         true,
+			},
+			initObjects: []runtime.Object{
+				&v1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "default",
+					},
+				},
+				&secretsstorev1.ClusterSecretProviderClass{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider1",
+					},
+					Spec: secretsstorev1.ClusterSecretProviderClassSpec{
+						SecretProviderClassSpec: secretsstorev1.SecretProviderClassSpec{
+							Provider:   "provider1",
+							Parameters: map[string]string{"parameter1": "value1"},
+						},
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+					},
+				},
+			},
+			expectedErr:        true,
+			shouldRetryRemount: true,
+		},
 		{
 			name: "volume already mounted, no remount",
 			nodePublishVolReq: csi.NodePublishVolumeRequest{
@@ -201,12 +246,12 @@ func TestNodePublishVolume(t *testing.T) {
 				Readonly:         true,
 			},
 			initObjects: []runtime.Object{
-				&v1alpha1.SecretProviderClass{
+				&secretsstorev1.SecretProviderClass{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "provider1",
 						Namespace: "default",
 					},
-					Spec: v1alpha1.SecretProviderClassSpec{
+					Spec: secretsstorev1.SecretProviderClassSpec{
 						Provider:   "provider1",
 						Parameters: map[string]string{"parameter1": "value1"},
 					},
@@ -219,9 +264,11 @@ func TestNodePublishVolume(t *testing.T) {
 	}
 
 	s := scheme.Scheme
-	s.AddKnownTypes(schema.GroupVersion{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version},
-		&v1alpha1.SecretProviderClass{},
-		&v1alpha1.SecretProviderClassList{},
+	s.AddKnownTypes(schema.GroupVersion{Group: secretsstorev1.GroupVersion.Group, Version: secretsstorev1.GroupVersion.Version},
+		&secretsstorev1.SecretProviderClass{},
+		&secretsstorev1.SecretProviderClassList{},
+		&secretsstorev1.ClusterSecretProviderClass{},
+		&secretsstorev1.ClusterSecretProviderClassList{},
 	)
 
 	for _, test := range tests {
@@ -370,9 +417,11 @@ func TestNodeUnpublishVolume(t *testing.T) {
 		},
 	}
 	s := scheme.Scheme
-	s.AddKnownTypes(schema.GroupVersion{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version},
-		&v1alpha1.SecretProviderClass{},
-		&v1alpha1.SecretProviderClassList{},
+	s.AddKnownTypes(schema.GroupVersion{Group: secretsstorev1.GroupVersion.Group, Version: secretsstorev1.GroupVersion.Version},
+		&secretsstorev1.SecretProviderClass{},
+		&secretsstorev1.SecretProviderClassList{},
+		&secretsstorev1.ClusterSecretProviderClass{},
+		&secretsstorev1.ClusterSecretProviderClassList{},
 	)
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
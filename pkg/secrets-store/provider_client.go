@@ -23,13 +23,17 @@ import (
 	"net"
 	"os"
 	"regexp"
+	"sort"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 	internalerrors "sigs.k8s.io/secrets-store-csi-driver/pkg/errors"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/fileutil"
 	"sigs.k8s.io/secrets-store-csi-driver/provider/v1alpha1"
@@ -149,6 +153,16 @@ func (p *PluginClientBuilder) Get(ctx context.Context, provider string) (v1alpha
 	return out, nil
 }
 
+// ProviderExists returns true if the provider name is valid and the provider
+// socket is present in the provider volume path.
+func (p *PluginClientBuilder) ProviderExists(provider string) bool {
+	if !PluginNameRe.MatchString(provider) {
+		return false
+	}
+	_, err := os.Stat(fmt.Sprintf("%s/%s.sock", p.socketPath, provider))
+	return err == nil
+}
+
 // Cleanup closes all underlying connections and removes all clients.
 func (p *PluginClientBuilder) Cleanup() {
 	p.lock.Lock()
@@ -196,8 +210,9 @@ func (p *PluginClientBuilder) HealthCheck(ctx context.Context, interval time.Dur
 }
 
 // MountContent calls the client's Mount() RPC with helpers to format the
-// request and interpret the response.
-func MountContent(ctx context.Context, client v1alpha1.CSIDriverProviderClient, attributes, secrets, targetPath, permission string, oldObjectVersions map[string]string) (map[string]string, string, error) {
+// request and interpret the response. The mounted objects are returned sorted by id,
+// with the time they expire if the provider returned one.
+func MountContent(ctx context.Context, client v1alpha1.CSIDriverProviderClient, attributes, secrets, targetPath, permission string, oldObjectVersions map[string]string) ([]secretsstorev1.SecretProviderClassObject, string, error) {
 	var objVersions []*v1alpha1.ObjectVersion
 	for obj, version := range oldObjectVersions {
 		objVersions = append(objVersions, &v1alpha1.ObjectVersion{Id: obj, Version: version})
@@ -223,10 +238,17 @@ func MountContent(ctx context.Context, client v1alpha1.CSIDriverProviderClient,
 	if ov == nil {
 		return nil, internalerrors.GRPCProviderError, errors.New("missing object versions")
 	}
-	objectVersions := make(map[string]string)
+	fetchedAt := metav1.Now()
+	objects := make([]secretsstorev1.SecretProviderClassObject, 0, len(ov))
 	for _, v := range ov {
-		objectVersions[v.Id] = v.Version
+		obj := secretsstorev1.SecretProviderClassObject{ID: v.Id, Version: v.Version}
+		if expiresAt := objectExpiry(v); expiresAt != nil {
+			obj.ExpiresAt = expiresAt
+			obj.FetchedAt = fetchedAt.DeepCopy()
+		}
+		objects = append(objects, obj)
 	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].ID < objects[j].ID })
 
 	// warn if the proto response size is over 1 MiB.
 	if size := proto.Size(resp); size > 1048576 {
@@ -247,7 +269,22 @@ func MountContent(ctx context.Context, client v1alpha1.CSIDriverProviderClient,
 		klog.V(5).Infof("mount response has no files")
 	}
 
-	return objectVersions, "", nil
+	return objects, "", nil
+}
+
+// objectExpiry returns the earliest of the expires_at and not_after times of the object
+// version, or nil if neither is set. Invalid times are ignored.
+func objectExpiry(ov *v1alpha1.ObjectVersion) *metav1.Time {
+	var expiresAt *metav1.Time
+	for _, ts := range []*timestamppb.Timestamp{ov.GetExpiresAt(), ov.GetNotAfter()} {
+		if ts == nil || !ts.IsValid() {
+			continue
+		}
+		if t := metav1.NewTime(ts.AsTime()); expiresAt == nil || t.Before(expiresAt) {
+			expiresAt = &t
+		}
+	}
+	return expiresAt
 }
 
 // Version calls the client's Version() RPC
@@ -263,3 +300,34 @@ func Version(ctx context.Context, client v1alpha1.CSIDriverProviderClient) (stri
 	}
 	return resp.RuntimeVersion, nil
 }
+
+// Capabilities calls the client's Version() RPC
+// returns the optional capabilities of the provider and error.
+func Capabilities(ctx context.Context, client v1alpha1.CSIDriverProviderClient) ([]v1alpha1.Capability, error) {
+	req := &v1alpha1.VersionRequest{
+		Version: "v1alpha1",
+	}
+
+	resp, err := client.Version(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetCapabilities(), nil
+}
+
+// Watch calls the client's Watch() RPC with helpers to format the request
+// and returns the stream of object changes. The stream is closed when ctx
+// is cancelled.
+func Watch(ctx context.Context, client v1alpha1.CSIDriverProviderClient, attributes, secrets string, oldObjectVersions map[string]string) (v1alpha1.CSIDriverProvider_WatchClient, error) {
+	var objVersions []*v1alpha1.ObjectVersion
+	for obj, version := range oldObjectVersions {
+		objVersions = append(objVersions, &v1alpha1.ObjectVersion{Id: obj, Version: version})
+	}
+
+	req := &v1alpha1.WatchRequest{
+		Attributes:           attributes,
+		Secrets:              secrets,
+		CurrentObjectVersion: objVersions,
+	}
+	return client.Watch(ctx, req)
+}
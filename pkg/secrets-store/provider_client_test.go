@@ -34,6 +34,7 @@ import (
 
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/test_utils/tmpdir"
 	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/fileutil"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/spcpsutil"
 	"sigs.k8s.io/secrets-store-csi-driver/provider/fake"
 	"sigs.k8s.io/secrets-store-csi-driver/provider/v1alpha1"
 )
@@ -194,11 +195,11 @@ func TestMountContent(t *testing.T) {
 				t.Fatalf("expected err to be nil, got: %+v", err)
 			}
 
-			objectVersions, _, err := MountContent(context.TODO(), client, "{}", "{}", targetPath, test.permission, nil)
+			objects, _, err := MountContent(context.TODO(), client, "{}", "{}", targetPath, test.permission, nil)
 			if err != nil {
 				t.Errorf("expected err to be nil, got: %+v", err)
 			}
-			if test.objectVersions != nil && !reflect.DeepEqual(test.objectVersions, objectVersions) {
+			if objectVersions := spcpsutil.ObjectVersions(objects); test.objectVersions != nil && !reflect.DeepEqual(test.objectVersions, objectVersions) {
 				t.Errorf("expected object versions: %v, got: %+v", test.objectVersions, objectVersions)
 			}
 
@@ -262,6 +263,63 @@ func TestMountContent_TooLarge(t *testing.T) {
 	}
 }
 
+func TestMountContentObjectExpiry(t *testing.T) {
+	socketPath := tmpdir.New(t, "", "ut")
+	targetPath := tmpdir.New(t, "", "ut")
+
+	pool := NewPluginClientBuilder(socketPath)
+	defer pool.Cleanup()
+
+	server, cleanup := fakeServer(t, socketPath, "provider1")
+	defer cleanup()
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	notAfter := expiresAt.Add(-time.Minute)
+	server.SetObjects(map[string]string{"secret/lease": "v1", "secret/cert": "v1", "secret/static": "v1"})
+	server.SetObjectExpiry("secret/lease", expiresAt, time.Time{})
+	server.SetObjectExpiry("secret/cert", expiresAt, notAfter)
+	server.Start()
+
+	client, err := pool.Get(context.Background(), "provider1")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %+v", err)
+	}
+
+	objects, _, err := MountContent(context.TODO(), client, "{}", "{}", targetPath, "420", nil)
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %+v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected 3 objects, got: %+v", objects)
+	}
+	// the objects are sorted by id and the earliest of expires_at and not_after is used
+	for i, want := range []struct {
+		id        string
+		expiresAt time.Time
+	}{
+		{id: "secret/cert", expiresAt: notAfter},
+		{id: "secret/lease", expiresAt: expiresAt},
+		{id: "secret/static"},
+	} {
+		obj := objects[i]
+		if obj.ID != want.id {
+			t.Errorf("expected object %d to be %s, got: %s", i, want.id, obj.ID)
+		}
+		if want.expiresAt.IsZero() {
+			if obj.ExpiresAt != nil || obj.FetchedAt != nil {
+				t.Errorf("expected object %s to not expire, got: %+v", obj.ID, obj)
+			}
+			continue
+		}
+		if obj.ExpiresAt == nil || !obj.ExpiresAt.Time.Equal(want.expiresAt) {
+			t.Errorf("expected object %s to expire at %v, got: %v", obj.ID, want.expiresAt, obj.ExpiresAt)
+		}
+		if obj.FetchedAt == nil {
+			t.Errorf("expected fetched at to be set for object %s", obj.ID)
+		}
+	}
+}
+
 func TestMountContentError(t *testing.T) {
 	cases := []struct {
 		name                  string
@@ -342,14 +400,14 @@ func TestMountContentError(t *testing.T) {
 				t.Fatalf("expected err to be nil, got: %+v", err)
 			}
 
-			objectVersions, errorCode, err := MountContent(context.TODO(), client, test.attributes, test.secrets, test.targetPath, test.permission, nil)
+			objects, errorCode, err := MountContent(context.TODO(), client, test.attributes, test.secrets, test.targetPath, test.permission, nil)
 			if err == nil {
 				t.Errorf("expected err to be not nil")
 			}
 			if errorCode != test.expectedErrorCode {
 				t.Errorf("expected error code: %v, got: %+v", test.expectedErrorCode, errorCode)
 			}
-			if test.expectedObjectVersion != nil && !reflect.DeepEqual(test.expectedObjectVersion, objectVersions) {
+			if objectVersions := spcpsutil.ObjectVersions(objects); test.expectedObjectVersion != nil && !reflect.DeepEqual(test.expectedObjectVersion, objectVersions) {
 				t.Errorf("expected object versions: %v, got: %+v", test.expectedObjectVersion, objectVersions)
 			}
 		})
@@ -441,6 +499,27 @@ func TestPluginClientBuilderErrorInvalid(t *testing.T) {
 	}
 }
 
+func TestPluginClientBuilder_ProviderExists(t *testing.T) {
+	path := tmpdir.New(t, "", "ut")
+
+	cb := NewPluginClientBuilder(path)
+
+	if cb.ProviderExists("provider1") {
+		t.Errorf("ProviderExists(%s) = true, want false", "provider1")
+	}
+	if cb.ProviderExists("bad/provider/name") {
+		t.Errorf("ProviderExists(%s) = true, want false", "bad/provider/name")
+	}
+
+	server, cleanup := fakeServer(t, path, "provider1")
+	defer cleanup()
+	server.Start()
+
+	if !cb.ProviderExists("provider1") {
+		t.Errorf("ProviderExists(%s) = false, want true", "provider1")
+	}
+}
+
 func TestVersion(t *testing.T) {
 	cases := []struct {
 		name                   string
@@ -480,6 +559,76 @@ func TestVersion(t *testing.T) {
 	}
 }
 
+func TestCapabilities(t *testing.T) {
+	socketPath := tmpdir.New(t, "", "ut")
+
+	pool := NewPluginClientBuilder(socketPath)
+	defer pool.Cleanup()
+
+	server, cleanup := fakeServer(t, socketPath, "provider1")
+	defer cleanup()
+
+	server.SetCapabilities(v1alpha1.Capability_CAPABILITY_WATCH)
+	server.Start()
+
+	client, err := pool.Get(context.Background(), "provider1")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %+v", err)
+	}
+
+	capabilities, err := Capabilities(context.TODO(), client)
+	if err != nil {
+		t.Errorf("expected err to be nil, got: %+v", err)
+	}
+	if diff := cmp.Diff([]v1alpha1.Capability{v1alpha1.Capability_CAPABILITY_WATCH}, capabilities); diff != "" {
+		t.Errorf("Capabilities() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	socketPath := tmpdir.New(t, "", "ut")
+
+	pool := NewPluginClientBuilder(socketPath)
+	defer pool.Cleanup()
+
+	server, cleanup := fakeServer(t, socketPath, "provider1")
+	defer cleanup()
+
+	server.Start()
+
+	client, err := pool.Get(context.Background(), "provider1")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %+v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := Watch(ctx, client, `{"foo":"bar"}`, `{"clientid":"id"}`, map[string]string{"secret/object1": "v1"})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %+v", err)
+	}
+
+	server.SendObjectChange("secret/object1", "v2")
+	change, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %+v", err)
+	}
+	if change.GetObjectVersion().GetId() != "secret/object1" || change.GetObjectVersion().GetVersion() != "v2" {
+		t.Errorf("unexpected object change: %v", change)
+	}
+
+	requests := server.WatchRequests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 watch request, got: %d", len(requests))
+	}
+	if requests[0].GetAttributes() != `{"foo":"bar"}` || requests[0].GetSecrets() != `{"clientid":"id"}` {
+		t.Errorf("unexpected watch request: %v", requests[0])
+	}
+	if diff := cmp.Diff("v1", requests[0].GetCurrentObjectVersion()[0].GetVersion()); diff != "" {
+		t.Errorf("watch request current object version mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestPluginClientBuilder_HealthCheck(t *testing.T) {
 	// this test asserts the read lock and unlock semantics in the
 	// HealthCheck() method work as expected
@@ -20,16 +20,24 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
 	"golang.org/x/net/context"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/cronutil"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/secretutil"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/spcpsutil"
 )
 
 // ensureMountPoint ensures mount point is valid
@@ -74,9 +82,22 @@ func (ns *nodeServer) ensureMountPoint(target string) (bool, error) {
 	return false, nil
 }
 
-// getSecretProviderItem returns the secretproviderclass object by name and namespace
-func getSecretProviderItem(ctx context.Context, c client.Client, name, namespace string) (*v1alpha1.SecretProviderClass, error) {
-	spc := &v1alpha1.SecretProviderClass{}
+// getSecretProviderItem returns the secretproviderclass object by kind, name and namespace.
+// For a clustersecretproviderclass, the namespace must be selected by the namespace selector.
+func getSecretProviderItem(ctx context.Context, c client.Client, kind, name, namespace string) (*secretsstorev1.SecretProviderClass, error) {
+	if kind == secretsstorev1.ClusterSecretProviderClassKind {
+		cspc := &secretsstorev1.ClusterSecretProviderClass{}
+		if err := c.Get(ctx, types.NamespacedName{Name: name}, cspc); err != nil {
+			return nil, fmt.Errorf("failed to get clustersecretproviderclass %s, error: %+v", name, err)
+		}
+		ns := &corev1.Namespace{}
+		if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+			return nil, fmt.Errorf("failed to get namespace %s, error: %+v", namespace, err)
+		}
+		return k8sutil.SecretProviderClassForNamespace(cspc, ns)
+	}
+
+	spc := &secretsstorev1.SecretProviderClass{}
 	spcKey := types.NamespacedName{
 		Namespace: namespace,
 		Name:      name,
@@ -88,26 +109,23 @@ func getSecretProviderItem(ctx context.Context, c client.Client, name, namespace
 }
 
 // createSecretProviderClassPodStatus creates secret provider class pod status
-func createSecretProviderClassPodStatus(ctx context.Context, c client.Client, podname, namespace, podUID, spcName, targetPath, nodeID string, mounted bool, objects map[string]string) error {
-	var o []v1alpha1.SecretProviderClassObject
-	for k, v := range objects {
-		o = append(o, v1alpha1.SecretProviderClassObject{ID: k, Version: v})
-	}
-
-	spcPodStatus := &v1alpha1.SecretProviderClassPodStatus{
+func createSecretProviderClassPodStatus(ctx context.Context, c client.Client, podname, namespace, podUID, spcKind, spcName, targetPath, nodeID string, mounted bool, objects []secretsstorev1.SecretProviderClassObject) error {
+	spcPodStatus := &secretsstorev1.SecretProviderClassPodStatus{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      podname + "-" + namespace + "-" + spcName,
+			Name:      secretProviderClassPodStatusName(podname, namespace, spcKind, spcName),
 			Namespace: namespace,
-			Labels:    map[string]string{v1alpha1.InternalNodeLabel: nodeID},
+			Labels:    map[string]string{secretsstorev1.InternalNodeLabel: nodeID},
 		},
-		Status: v1alpha1.SecretProviderClassPodStatusStatus{
+		Status: secretsstorev1.SecretProviderClassPodStatusStatus{
 			PodName:                 podname,
 			TargetPath:              targetPath,
 			Mounted:                 mounted,
 			SecretProviderClassName: spcName,
-			Objects:                 o,
+			SecretProviderClassKind: spcKind,
+			Objects:                 objects,
 		},
 	}
+	spcpsutil.SetCondition(spcPodStatus, secretsstorev1.ConditionTypeMounted, metav1.ConditionTrue, secretsstorev1.MountSucceededReason, "")
 	// Set owner reference to the pod as the mapping between secret provider class pod status and
 	// pod is 1 to 1. When pod is deleted, the spc pod status will automatically be garbage collected
 	spcPodStatus.SetOwnerReferences([]metav1.OwnerReference{
@@ -121,14 +139,56 @@ func createSecretProviderClassPodStatus(ctx context.Context, c client.Client, po
 
 	// create the secret provider class pod status
 	err := c.Create(ctx, spcPodStatus, &client.CreateOptions{})
-	if err != nil && !apierrors.IsAlreadyExists(err) {
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
 		return err
 	}
-	return nil
+	// the spc pod status already exists if the volume is mounted again for the same pod,
+	// for example after a node restart. Update the Mounted condition to reflect the new mount.
+	return setMountedCondition(ctx, c, spcPodStatus.Name, namespace, metav1.ConditionTrue, secretsstorev1.MountSucceededReason, "")
+}
+
+// secretProviderClassPodStatusName returns the name of the secret provider class pod status
+// for the pod and secret provider class. The kind is part of the name for a cluster secret
+// provider class, so it doesn't conflict with a secret provider class with the same name.
+func secretProviderClassPodStatusName(podName, namespace, spcKind, spcName string) string {
+	if spcKind == secretsstorev1.ClusterSecretProviderClassKind {
+		return podName + "-" + namespace + "-cluster-" + spcName
+	}
+	return podName + "-" + namespace + "-" + spcName
+}
+
+// setMountedCondition sets the Mounted condition in an existing secret provider class pod status
+func setMountedCondition(ctx context.Context, c client.Client, name, namespace string, status metav1.ConditionStatus, reason, message string) error {
+	updateFn := func() (bool, error) {
+		spcPodStatus := &secretsstorev1.SecretProviderClassPodStatus{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, spcPodStatus); err != nil {
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, nil
+		}
+		if !spcpsutil.SetCondition(spcPodStatus, secretsstorev1.ConditionTypeMounted, status, reason, message) {
+			return true, nil
+		}
+		if err := c.Update(ctx, spcPodStatus); err != nil {
+			klog.ErrorS(err, "failed to update mounted condition in spc pod status", "spcps", klog.KObj(spcPodStatus))
+			return false, nil
+		}
+		return true, nil
+	}
+	return wait.ExponentialBackoff(wait.Backoff{
+		Steps:    5,
+		Duration: 1 * time.Millisecond,
+		Factor:   1.0,
+		Jitter:   0.1,
+	}, updateFn)
 }
 
 // getProviderFromSPC returns the provider as defined in SecretProviderClass
-func getProviderFromSPC(spc *v1alpha1.SecretProviderClass) (string, error) {
+func getProviderFromSPC(spc *secretsstorev1.SecretProviderClass) (string, error) {
 	if len(spc.Spec.Provider) == 0 {
 		return "", fmt.Errorf("provider not set in %s/%s", spc.Namespace, spc.Name)
 	}
@@ -136,9 +196,78 @@ func getProviderFromSPC(spc *v1alpha1.SecretProviderClass) (string, error) {
 }
 
 // getParametersFromSPC returns the parameters map as defined in SecretProviderClass
-func getParametersFromSPC(spc *v1alpha1.SecretProviderClass) (map[string]string, error) {
+func getParametersFromSPC(spc *secretsstorev1.SecretProviderClass) (map[string]string, error) {
 	if len(spc.Spec.Parameters) == 0 {
 		return nil, fmt.Errorf("parameters not set in %s/%s", spc.Namespace, spc.Name)
 	}
 	return spc.Spec.Parameters, nil
 }
+
+// ValidateSecretProviderClass validates the SecretProviderClass spec and returns an
+// aggregate of all the validation errors found. minRotationInterval is the shortest rotation
+// interval the rotation policy can set, so it can't make every node call the provider in a
+// tight loop.
+func ValidateSecretProviderClass(spc *secretsstorev1.SecretProviderClass, minRotationInterval time.Duration) error {
+	var errs []error
+	provider, err := getProviderFromSPC(spc)
+	if err != nil {
+		errs = append(errs, err)
+	} else if !PluginNameRe.MatchString(provider) {
+		errs = append(errs, fmt.Errorf("provider %s is invalid, must match %s", provider, PluginNameRe.String()))
+	}
+	if _, err = getParametersFromSPC(spc); err != nil {
+		errs = append(errs, err)
+	}
+	if err = secretutil.ValidateSecretObjects(spc.Spec.SecretObjects); err != nil {
+		errs = append(errs, err)
+	}
+	if err = secretutil.ValidateConfigMapObjects(spc.Spec.ConfigMapObjects); err != nil {
+		errs = append(errs, err)
+	}
+	if err = validateRotationPolicy(spc.Spec.RotationPolicy, minRotationInterval); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateClusterSecretProviderClass validates the ClusterSecretProviderClass spec and
+// namespace selector and returns an aggregate of all the validation errors found
+func ValidateClusterSecretProviderClass(cspc *secretsstorev1.ClusterSecretProviderClass, minRotationInterval time.Duration) error {
+	var errs []error
+	spc := &secretsstorev1.SecretProviderClass{
+		ObjectMeta: cspc.ObjectMeta,
+		Spec:       cspc.Spec.SecretProviderClassSpec,
+	}
+	if err := ValidateSecretProviderClass(spc, minRotationInterval); err != nil {
+		errs = append(errs, err)
+	}
+	if cspc.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(cspc.Spec.NamespaceSelector); err != nil {
+			errs = append(errs, fmt.Errorf("namespace selector is invalid, err: %+v", err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateRotationPolicy checks the rotation interval and the window schedules and durations are valid
+func validateRotationPolicy(policy *secretsstorev1.RotationPolicy, minRotationInterval time.Duration) error {
+	if policy == nil {
+		return nil
+	}
+	var errs []error
+	if policy.Interval != nil && policy.Interval.Duration < minRotationInterval {
+		errs = append(errs, fmt.Errorf("rotation interval %s is invalid, must be at least %s", policy.Interval.Duration, minRotationInterval))
+	}
+	for i, window := range policy.Windows {
+		if _, err := cronutil.Parse(window.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("rotation window %d is invalid, err: %+v", i, err))
+		}
+		if window.Duration.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("rotation window %d duration %s is invalid, must be positive", i, window.Duration.Duration))
+		}
+	}
+	if action := policy.PostRotationAction; action != nil && action.MinInterval != nil && action.MinInterval.Duration < 0 {
+		errs = append(errs, fmt.Errorf("post rotation action min interval %s is invalid, must not be negative", action.MinInterval.Duration))
+	}
+	return utilerrors.NewAggregate(errs)
+}
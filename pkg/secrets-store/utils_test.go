@@ -15,3 +15,247 @@ limitations under the License.
 */
 
 package secretsstore
+
+import (
+	"testing"
+	"time"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateSecretProviderClass(t *testing.T) {
+	minRotationInterval := 15 * time.Minute
+	tests := []struct {
+		name          string
+		spec          secretsstorev1.SecretProviderClassSpec
+		expectedError bool
+	}{
+		{
+			name:          "provider and parameters not set",
+			spec:          secretsstorev1.SecretProviderClassSpec{},
+			expectedError: true,
+		},
+		{
+			name: "invalid provider name",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider/1",
+				Parameters: map[string]string{"parameter1": "value1"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "parameters not set",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider: "provider1",
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid secret object",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:      "provider1",
+				Parameters:    map[string]string{"parameter1": "value1"},
+				SecretObjects: []*secretsstorev1.SecretObject{{SecretName: "secret1", Type: "Opaque"}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid rotation policy",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider1",
+				Parameters: map[string]string{"parameter1": "value1"},
+				RotationPolicy: &secretsstorev1.RotationPolicy{
+					Interval: &metav1.Duration{Duration: -time.Minute},
+					Windows:  []secretsstorev1.RotationWindow{{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "rotation interval below the minimum",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider1",
+				Parameters: map[string]string{"parameter1": "value1"},
+				RotationPolicy: &secretsstorev1.RotationPolicy{
+					Interval: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid post rotation action",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider1",
+				Parameters: map[string]string{"parameter1": "value1"},
+				RotationPolicy: &secretsstorev1.RotationPolicy{
+					PostRotationAction: &secretsstorev1.PostRotationAction{
+						Type:        secretsstorev1.PostRotationActionRollout,
+						MinInterval: &metav1.Duration{Duration: -time.Minute},
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid secret provider class",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider1",
+				Parameters: map[string]string{"parameter1": "value1"},
+				SecretObjects: []*secretsstorev1.SecretObject{
+					{
+						SecretName: "secret1",
+						Type:       "Opaque",
+						Data:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}},
+					},
+				},
+				RotationPolicy: &secretsstorev1.RotationPolicy{
+					Interval: &metav1.Duration{Duration: time.Hour},
+					Windows:  []secretsstorev1.RotationWindow{{Schedule: "0 2 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}}},
+					PostRotationAction: &secretsstorev1.PostRotationAction{
+						Type:        secretsstorev1.PostRotationActionRollout,
+						MinInterval: &metav1.Duration{Duration: 10 * time.Minute},
+					},
+				},
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spc := &secretsstorev1.SecretProviderClass{Spec: test.spec}
+			spc.Name, spc.Namespace = "spc1", "default"
+
+			err := ValidateSecretProviderClass(spc, minRotationInterval)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCreateSecretProviderClassPodStatus(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := secretsstorev1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	c := fake.NewFakeClientWithScheme(s)
+	ctx := context.TODO()
+	key := types.NamespacedName{Namespace: "default", Name: "pod1-default-spc1"}
+
+	// create the spc pod status with a failed Mounted condition from a previous mount
+	if err := createSecretProviderClassPodStatus(ctx, c, "pod1", "default", "uid1", "SecretProviderClass", "spc1", "/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~csi/vol1/mount", "node1", true, nil); err != nil {
+		t.Fatalf("expected error to be nil, got: %+v", err)
+	}
+	if err := setMountedCondition(ctx, c, key.Name, key.Namespace, metav1.ConditionFalse, "FailedToMount", "failed to mount"); err != nil {
+		t.Fatalf("expected error to be nil, got: %+v", err)
+	}
+
+	spcps := &secretsstorev1.SecretProviderClassPodStatus{}
+	if err := c.Get(ctx, key, spcps); err != nil {
+		t.Fatalf("expected error to be nil, got: %+v", err)
+	}
+	if !meta.IsStatusConditionFalse(spcps.Status.Conditions, secretsstorev1.ConditionTypeMounted) {
+		t.Fatalf("expected Mounted condition to be false, got: %+v", spcps.Status.Conditions)
+	}
+
+	// mounting again for the same pod updates the existing spc pod status
+	if err := createSecretProviderClassPodStatus(ctx, c, "pod1", "default", "uid1", "SecretProviderClass", "spc1", "/var/lib/kubelet/pods/uid1/volumes/kubernetes.io~csi/vol1/mount", "node1", true, nil); err != nil {
+		t.Fatalf("expected error to be nil, got: %+v", err)
+	}
+	if err := c.Get(ctx, key, spcps); err != nil {
+		t.Fatalf("expected error to be nil, got: %+v", err)
+	}
+	c1 := meta.FindStatusCondition(spcps.Status.Conditions, secretsstorev1.ConditionTypeMounted)
+	if c1 == nil || c1.Status != metav1.ConditionTrue || c1.Reason != secretsstorev1.MountSucceededReason {
+		t.Fatalf("expected Mounted condition to be true, got: %+v", spcps.Status.Conditions)
+	}
+}
+
+func TestGetSecretProviderItem(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := secretsstorev1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	c := fake.NewFakeClientWithScheme(s,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"team": "b"}}},
+		&secretsstorev1.SecretProviderClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "team-a"},
+			Spec:       secretsstorev1.SecretProviderClassSpec{Provider: "provider1"},
+		},
+		&secretsstorev1.ClusterSecretProviderClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "spc1"},
+			Spec: secretsstorev1.ClusterSecretProviderClassSpec{
+				SecretProviderClassSpec: secretsstorev1.SecretProviderClassSpec{Provider: "provider2"},
+				NamespaceSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			},
+		},
+	)
+	ctx := context.TODO()
+
+	tests := []struct {
+		name             string
+		kind             string
+		namespace        string
+		expectedProvider secretsstorev1.Provider
+		expectedErr      bool
+	}{
+		{
+			name:             "secret provider class in namespace",
+			kind:             secretsstorev1.SecretProviderClassKind,
+			namespace:        "team-a",
+			expectedProvider: "provider1",
+		},
+		{
+			name:        "secret provider class not in namespace",
+			kind:        secretsstorev1.SecretProviderClassKind,
+			namespace:   "team-b",
+			expectedErr: true,
+		},
+		{
+			name:             "cluster secret provider class selects namespace",
+			kind:             secretsstorev1.ClusterSecretProviderClassKind,
+			namespace:        "team-a",
+			expectedProvider: "provider2",
+		},
+		{
+			name:        "cluster secret provider class does not select namespace",
+			kind:        secretsstorev1.ClusterSecretProviderClassKind,
+			namespace:   "team-b",
+			expectedErr: true,
+		},
+		{
+			name:        "namespace not found",
+			kind:        secretsstorev1.ClusterSecretProviderClassKind,
+			namespace:   "team-c",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spc, err := getSecretProviderItem(ctx, c, test.kind, "spc1", test.namespace)
+			if test.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %+v", test.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if spc.Namespace != test.namespace || spc.Spec.Provider != test.expectedProvider {
+				t.Fatalf("expected spc %s/spc1 with provider %s, got: %s/%s with provider %s", test.namespace, test.expectedProvider, spc.Namespace, spc.Name, spc.Spec.Provider)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expectedErr bool
+	}{
+		{name: "every minute", spec: "* * * * *"},
+		{name: "steps, ranges and lists", spec: "*/15 9-17/2 1,15 1-12 1-5"},
+		{name: "sunday as 7", spec: "0 2 * * 7"},
+		{name: "value with step", spec: "5/20 * * * *"},
+		{name: "missing field", spec: "* * * *", expectedErr: true},
+		{name: "value out of range", spec: "60 * * * *", expectedErr: true},
+		{name: "invalid range", spec: "* 17-9 * * *", expectedErr: true},
+		{name: "invalid step", spec: "*/0 * * * *", expectedErr: true},
+		{name: "not a number", spec: "* * * JAN *", expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.spec)
+			if test.expectedErr != (err != nil) {
+				t.Fatalf("expected err: %v, got: %+v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2021-04-01 is a Thursday
+	from := time.Date(2021, 4, 1, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{
+			name:     "every minute",
+			spec:     "* * * * *",
+			expected: time.Date(2021, 4, 1, 10, 8, 0, 0, time.UTC),
+		},
+		{
+			name:     "every 15 minutes",
+			spec:     "*/15 * * * *",
+			expected: time.Date(2021, 4, 1, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily at 2am",
+			spec:     "0 2 * * *",
+			expected: time.Date(2021, 4, 2, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "sundays",
+			spec:     "30 1 * * 7",
+			expected: time.Date(2021, 4, 4, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of month or day of week",
+			spec:     "0 0 15 * 1",
+			expected: time.Date(2021, 4, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "next year",
+			spec:     "0 0 1 1 *",
+			expected: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "never",
+			spec:     "0 0 30 2 *",
+			expected: time.Time{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := Parse(test.spec)
+			if err != nil {
+				t.Fatalf("expected err: nil, got: %+v", err)
+			}
+			next := schedule.Next(from)
+			if !next.Equal(test.expected) {
+				t.Fatalf("expected next: %v, got: %v", test.expected, next)
+			}
+			if !next.IsZero() && !schedule.Matches(next) {
+				t.Fatalf("expected %v to match %s", next, test.spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+// ErrNamespaceNotAllowed is returned when the namespace is not selected by the
+// namespace selector of the ClusterSecretProviderClass.
+var ErrNamespaceNotAllowed = errors.New("namespace not allowed")
+
+// SecretProviderClassForNamespace returns the SecretProviderClass defined by the
+// ClusterSecretProviderClass for pods in the namespace. An error wrapping
+// ErrNamespaceNotAllowed is returned if the namespace is not selected by the
+// ClusterSecretProviderClass namespace selector.
+func SecretProviderClassForNamespace(cspc *secretsstorev1.ClusterSecretProviderClass, namespace *v1.Namespace) (*secretsstorev1.SecretProviderClass, error) {
+	if cspc.Spec.NamespaceSelector == nil {
+		return nil, fmt.Errorf("%w: clustersecretproviderclass %s has no namespace selector", ErrNamespaceNotAllowed, cspc.Name)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cspc.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse namespace selector in clustersecretproviderclass %s, err: %+v", cspc.Name, err)
+	}
+	if !selector.Matches(labels.Set(namespace.Labels)) {
+		return nil, fmt.Errorf("%w: namespace %s is not selected by clustersecretproviderclass %s", ErrNamespaceNotAllowed, namespace.Name, cspc.Name)
+	}
+
+	return &secretsstorev1.SecretProviderClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       cspc.Name,
+			Namespace:  namespace.Name,
+			UID:        cspc.UID,
+			Generation: cspc.Generation,
+		},
+		Spec: *cspc.Spec.SecretProviderClassSpec.DeepCopy(),
+	}, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestSecretProviderClassForNamespace(t *testing.T) {
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{"team": "a"},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		namespaceSelector *metav1.LabelSelector
+		expectedErr       error
+	}{
+		{
+			name:              "nil selector allows no namespaces",
+			namespaceSelector: nil,
+			expectedErr:       ErrNamespaceNotAllowed,
+		},
+		{
+			name:              "empty selector allows all namespaces",
+			namespaceSelector: &metav1.LabelSelector{},
+		},
+		{
+			name:              "namespace selected by labels",
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+		},
+		{
+			name:              "namespace not selected by labels",
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}},
+			expectedErr:       ErrNamespaceNotAllowed,
+		},
+		{
+			name: "namespace not selected by expressions",
+			namespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"a"}},
+				},
+			},
+			expectedErr: ErrNamespaceNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cspc := &secretsstorev1.ClusterSecretProviderClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "cspc1"},
+				Spec: secretsstorev1.ClusterSecretProviderClassSpec{
+					SecretProviderClassSpec: secretsstorev1.SecretProviderClassSpec{
+						Provider:   "provider1",
+						Parameters: map[string]string{"foo": "bar"},
+					},
+					NamespaceSelector: test.namespaceSelector,
+				},
+			}
+			spc, err := SecretProviderClassForNamespace(cspc, namespace)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Fatalf("expected error %v, got: %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %+v", err)
+			}
+			if spc.Name != "cspc1" || spc.Namespace != "team-a" {
+				t.Fatalf("expected spc team-a/cspc1, got: %s/%s", spc.Namespace, spc.Name)
+			}
+			if spc.Spec.Provider != "provider1" || spc.Spec.Parameters["foo"] != "bar" {
+				t.Fatalf("expected spc spec to match cluster spc spec, got: %+v", spc.Spec)
+			}
+		})
+	}
+}
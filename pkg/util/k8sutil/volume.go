@@ -20,19 +20,34 @@ package k8sutil
 
 import (
 	v1 "k8s.io/api/core/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+const (
+	// DriverName is the default name of the secrets-store CSI driver
+	DriverName = "secrets-store.csi.k8s.io"
+	// SecretProviderClassVolumeAttribute is the volume attribute that references a SecretProviderClass
+	SecretProviderClassVolumeAttribute = "secretProviderClass"
+	// ClusterSecretProviderClassVolumeAttribute is the volume attribute that references a ClusterSecretProviderClass
+	ClusterSecretProviderClassVolumeAttribute = "clusterSecretProviderClass"
 )
 
-// SPCVolume finds the Secret Provider Class volume from a Pod, or returns nil
-// if a volume could not be found.
-func SPCVolume(pod *v1.Pod, spcName string) *v1.Volume {
+// SPCVolume finds the Secret Provider Class volume of the given kind from a Pod, or
+// returns nil if a volume could not be found. An empty kind is a SecretProviderClass.
+func SPCVolume(pod *v1.Pod, spcKind, spcName string) *v1.Volume {
+	attribute := SecretProviderClassVolumeAttribute
+	if spcKind == secretsstorev1.ClusterSecretProviderClassKind {
+		attribute = ClusterSecretProviderClassVolumeAttribute
+	}
 	for i, vol := range pod.Spec.Volumes {
 		if vol.CSI == nil {
 			continue
 		}
-		if vol.CSI.Driver != "secrets-store.csi.k8s.io" {
+		if vol.CSI.Driver != DriverName {
 			continue
 		}
-		if vol.CSI.VolumeAttributes["secretProviderClass"] != spcName {
+		if vol.CSI.VolumeAttributes[attribute] != spcName {
 			continue
 		}
 		return &pod.Spec.Volumes[i]
@@ -27,6 +27,7 @@ func TestSPCVolume(t *testing.T) {
 	tests := []struct {
 		name    string
 		pod     *v1.Pod
+		spcKind string
 		spcName string
 		want    *v1.Volume
 	}{
@@ -155,11 +156,70 @@ func TestSPCVolume(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Cluster Volume with SecretProviderClass kind",
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						{
+							Name: "csi-volume",
+							VolumeSource: v1.VolumeSource{
+								CSI: &v1.CSIVolumeSource{
+									Driver:           "secrets-store.csi.k8s.io",
+									VolumeAttributes: map[string]string{"clusterSecretProviderClass": "spc1"},
+								},
+							},
+						},
+					},
+				},
+			},
+			spcKind: "SecretProviderClass",
+			spcName: "spc1",
+			want:    nil,
+		},
+		{
+			name: "Correct Cluster Volume",
+			pod: &v1.Pod{
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						{
+							Name: "csi-volume-0",
+							VolumeSource: v1.VolumeSource{
+								CSI: &v1.CSIVolumeSource{
+									Driver:           "secrets-store.csi.k8s.io",
+									VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+								},
+							},
+						},
+						{
+							Name: "csi-volume",
+							VolumeSource: v1.VolumeSource{
+								CSI: &v1.CSIVolumeSource{
+									Driver:           "secrets-store.csi.k8s.io",
+									VolumeAttributes: map[string]string{"clusterSecretProviderClass": "spc1"},
+								},
+							},
+						},
+					},
+				},
+			},
+			spcKind: "ClusterSecretProviderClass",
+			spcName: "spc1",
+			want: &v1.Volume{
+				Name: "csi-volume",
+				VolumeSource: v1.VolumeSource{
+					CSI: &v1.CSIVolumeSource{
+						Driver:           "secrets-store.csi.k8s.io",
+						VolumeAttributes: map[string]string{"clusterSecretProviderClass": "spc1"},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := SPCVolume(tc.pod, tc.spcName)
+			got := SPCVolume(tc.pod, tc.spcKind, tc.spcName)
 			if diff := cmp.Diff(tc.want, got); diff != "" {
 				t.Errorf("SPCVolume() mismatch (-want +got):\n%s", diff)
 			}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ValidateConfigMapObject performs basic validation of the secret provider class
+// configmap object to check if the mandatory fields - name and data are defined
+// and the data keys are unique
+func ValidateConfigMapObject(configMapObj secretsstorev1.ConfigMapObject) error {
+	if len(strings.TrimSpace(configMapObj.ConfigMapName)) == 0 {
+		return fmt.Errorf("configmap name is empty")
+	}
+	if len(configMapObj.Data) == 0 {
+		return fmt.Errorf("data is empty")
+	}
+	_, err := validateObjectData(configMapObj.Data)
+	return err
+}
+
+// ValidateConfigMapObjects validates all the configmap objects in the secret provider class
+// and checks that the same configmap name isn't used by more than one configmap object
+func ValidateConfigMapObjects(configMapObjs []*secretsstorev1.ConfigMapObject) error {
+	names := make(map[string]bool)
+	for i, configMapObj := range configMapObjs {
+		if configMapObj == nil {
+			return fmt.Errorf("configMapObjects[%d] is empty", i)
+		}
+		if err := ValidateConfigMapObject(*configMapObj); err != nil {
+			return fmt.Errorf("configMapObjects[%d] is invalid, err: %w", i, err)
+		}
+		configMapName := strings.TrimSpace(configMapObj.ConfigMapName)
+		if names[configMapName] {
+			return fmt.Errorf("configMapObjects[%d] has duplicate configmap name %s", i, configMapName)
+		}
+		names[configMapName] = true
+	}
+	return nil
+}
+
+// GetConfigMapData gets the object contents from the pods target path and returns the
+// maps that will be populated in the Kubernetes configmap data and binaryData fields.
+// Contents that aren't valid UTF-8 are added to binaryData.
+func GetConfigMapData(objData []*secretsstorev1.SecretObjectData, files map[string]string) (map[string]string, map[string][]byte, error) {
+	datamap, err := GetSecretData(objData, corev1.SecretTypeOpaque, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := make(map[string]string)
+	binaryData := make(map[string][]byte)
+	for k, v := range datamap {
+		if utf8.Valid(v) {
+			data[k] = string(v)
+			continue
+		}
+		binaryData[k] = v
+	}
+	return data, binaryData, nil
+}
+
+// GetSHAFromConfigMap gets SHA for the configmap data and binary data
+func GetSHAFromConfigMap(data map[string]string, binaryData map[string][]byte) (string, error) {
+	values := make(map[string][]byte, len(data)+len(binaryData))
+	for k, v := range data {
+		values[k] = []byte(v)
+	}
+	for k, v := range binaryData {
+		values[k] = v
+	}
+	return GetSHAFromSecret(values)
+}
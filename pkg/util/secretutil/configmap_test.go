@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestValidateConfigMapObjects(t *testing.T) {
+	tests := []struct {
+		name          string
+		configMapObjs []*secretsstorev1.ConfigMapObject
+		expectedError bool
+	}{
+		{
+			name:          "no configmap objects",
+			expectedError: false,
+		},
+		{
+			name:          "nil configmap object",
+			configMapObjs: []*secretsstorev1.ConfigMapObject{nil},
+			expectedError: true,
+		},
+		{
+			name:          "configmap name not set",
+			configMapObjs: []*secretsstorev1.ConfigMapObject{{Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}}},
+			expectedError: true,
+		},
+		{
+			name:          "data not set",
+			configMapObjs: []*secretsstorev1.ConfigMapObject{{ConfigMapName: "cm1"}},
+			expectedError: true,
+		},
+		{
+			name: "duplicate data key",
+			configMapObjs: []*secretsstorev1.ConfigMapObject{
+				{ConfigMapName: "cm1", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}, {ObjectName: "obj2", Key: "file1"}}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid template",
+			configMapObjs: []*secretsstorev1.ConfigMapObject{
+				{ConfigMapName: "cm1", Data: []*secretsstorev1.SecretObjectData{{Key: "file1", Template: "{{ .obj1"}}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "duplicate configmap name",
+			configMapObjs: []*secretsstorev1.ConfigMapObject{
+				{ConfigMapName: "cm1", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
+				{ConfigMapName: "cm1", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj2", Key: "file2"}}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid configmap objects",
+			configMapObjs: []*secretsstorev1.ConfigMapObject{
+				{ConfigMapName: "cm1", Labels: map[string]string{"app": "foo"}, Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
+				{ConfigMapName: "cm2", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj2", Key: "file2"}}},
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateConfigMapObjects(test.configMapObjs)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestGetConfigMapData(t *testing.T) {
+	tests := []struct {
+		name               string
+		objData            []*secretsstorev1.SecretObjectData
+		objects            map[string]string
+		expectedData       map[string]string
+		expectedBinaryData map[string][]byte
+		expectedError      bool
+	}{
+		{
+			name:               "text content",
+			objData:            []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "config.yaml"}},
+			objects:            map[string]string{"obj1": "a: 1\n"},
+			expectedData:       map[string]string{"config.yaml": "a: 1\n"},
+			expectedBinaryData: map[string][]byte{},
+		},
+		{
+			name:               "binary content",
+			objData:            []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file.bin"}, {ObjectName: "obj2", Key: "file.txt"}},
+			objects:            map[string]string{"obj1": "\xff\xfe\x00", "obj2": "text"},
+			expectedData:       map[string]string{"file.txt": "text"},
+			expectedBinaryData: map[string][]byte{"file.bin": []byte("\xff\xfe\x00")},
+		},
+		{
+			name:          "object not found",
+			objData:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}},
+			objects:       map[string]string{"obj2": "text"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			files := make(map[string]string)
+			for name, content := range test.objects {
+				path := filepath.Join(tmpDir, name)
+				if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+					t.Fatalf("expected err to be nil, got: %+v", err)
+				}
+				files[name] = path
+			}
+			data, binaryData, err := GetConfigMapData(test.objData, files)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+			if !reflect.DeepEqual(data, test.expectedData) {
+				t.Fatalf("expected data: %v, got: %v", test.expectedData, data)
+			}
+			if !reflect.DeepEqual(binaryData, test.expectedBinaryData) {
+				t.Fatalf("expected binary data: %v, got: %v", test.expectedBinaryData, binaryData)
+			}
+		})
+	}
+}
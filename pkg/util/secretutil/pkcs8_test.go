@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"strings"
+	"testing"
+)
+
+// marshalPBES2Key returns a PKCS #8 encrypted private key with the PBKDF2 parameters and
+// AES-256-CBC encryption scheme. The encrypted data is a single block that isn't decrypted
+// to a valid key.
+func marshalPBES2Key(t *testing.T, kdfParams pbkdf2Params) []byte {
+	marshal := func(v interface{}) asn1.RawValue {
+		b, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %+v", err)
+		}
+		return asn1.RawValue{FullBytes: b}
+	}
+	params := pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: marshal(kdfParams)},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: marshal(make([]byte, 16))},
+	}
+	der, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: marshal(params)},
+		EncryptedData: make([]byte, 16),
+	})
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %+v", err)
+	}
+	return der
+}
+
+func TestDecryptPKCS8PrivateKeyParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		kdfParams   pbkdf2Params
+		expectedErr string
+	}{
+		{
+			name:        "zero iteration count",
+			kdfParams:   pbkdf2Params{Salt: []byte("salt"), IterationCount: 0},
+			expectedErr: "PBKDF2 iteration count 0 is not supported",
+		},
+		{
+			name:        "iteration count above the cap",
+			kdfParams:   pbkdf2Params{Salt: []byte("salt"), IterationCount: maxPBKDF2Iterations + 1},
+			expectedErr: "PBKDF2 iteration count 1000001 is not supported",
+		},
+		{
+			name:        "key length doesn't match the encryption scheme",
+			kdfParams:   pbkdf2Params{Salt: []byte("salt"), IterationCount: 1, KeyLength: 16},
+			expectedErr: "PBKDF2 key length 16 doesn't match the key length 32 of the encryption scheme",
+		},
+		{
+			name:        "valid parameters with the wrong passphrase",
+			kdfParams:   pbkdf2Params{Salt: []byte("salt"), IterationCount: 1, KeyLength: 32},
+			expectedErr: "the passphrase may be incorrect",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decryptPKCS8PrivateKey(marshalPBES2Key(t, test.kdfParams), []byte("passphrase"))
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Fatalf("expected err to contain %q, got: %+v", test.expectedErr, err)
+			}
+		})
+	}
+}
@@ -28,7 +28,7 @@ import (
 	"sort"
 	"strings"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
@@ -79,6 +79,14 @@ func getPrivateKey(data []byte) ([]byte, error) {
 		if pemBlock == nil {
 			break
 		}
+		// encrypted keys are returned as is and decrypted when the tls secret
+		// data is built, as the passphrase is needed
+		if pemBlock.Type == encryptedPrivateKeyType {
+			return pem.EncodeToMemory(pemBlock), nil
+		}
+		if _, encrypted := pemBlock.Headers["DEK-Info"]; encrypted {
+			return nil, fmt.Errorf("legacy encrypted PEM private keys are not supported, use an encrypted PKCS#8 private key")
+		}
 		if pemBlock.Type != certType {
 			der = pemBlock.Bytes
 		}
@@ -145,54 +153,186 @@ func GetSecretType(sType string) corev1.SecretType {
 }
 
 // ValidateSecretObject performs basic validation of the secret provider class
-// secret object to check if the mandatory fields - name, type and data are defined
-func ValidateSecretObject(secretObj v1alpha1.SecretObject) error {
+// secret object to check if the mandatory fields - name, type and data are defined,
+// the type is a supported Kubernetes secret type and the data keys are unique
+func ValidateSecretObject(secretObj secretsstorev1.SecretObject) error {
 	if len(secretObj.SecretName) == 0 {
 		return fmt.Errorf("secret name is empty")
 	}
 	if len(secretObj.Type) == 0 {
 		return fmt.Errorf("secret type is empty")
 	}
-	if len(secretObj.Data) == 0 {
+	if len(secretObj.Data) == 0 && secretObj.DockerConfig == nil {
 		return fmt.Errorf("data is empty")
 	}
+	secretType := strings.TrimSpace(secretObj.Type)
+	if GetSecretType(secretType) == corev1.SecretTypeOpaque && secretType != string(corev1.SecretTypeOpaque) {
+		return fmt.Errorf("secret type %s is not supported", secretType)
+	}
+	if secretObj.DockerConfig != nil {
+		if err := validateDockerConfigSource(secretObj); err != nil {
+			return err
+		}
+	}
+	if secretObj.TLS != nil {
+		if err := validateTLSOptions(secretObj); err != nil {
+			return err
+		}
+	}
+	keys, err := validateObjectData(secretObj.Data)
+	if err != nil {
+		return err
+	}
+	// the keys of expanded objects are only known once the object is mounted, so the
+	// required keys are checked by the secret type validation of the synced data instead
+	if GetSecretType(secretType) == corev1.SecretTypeTLS && !keys[expandedKeys] {
+		for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			if !keys[key] {
+				return fmt.Errorf("data key %s is required for secret type %s", key, secretType)
+			}
+		}
+	}
+	return nil
+}
+
+// expandedKeys is the key recorded by validateObjectData for the data entries with
+// expandKeys set. It isn't a valid data key, so it can't collide with a static key.
+const expandedKeys = "*"
+
+// validateObjectData checks the data entries are set, the data keys are unique and
+// the templates, JSONPaths and transforms are valid. The data keys are returned.
+func validateObjectData(objData []*secretsstorev1.SecretObjectData) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	for _, data := range objData {
+		if data == nil {
+			return nil, fmt.Errorf("data contains an empty entry")
+		}
+		key := strings.TrimSpace(data.Key)
+		if data.ExpandKeys {
+			if len(key) > 0 {
+				return nil, fmt.Errorf("key %s can't be set when expandKeys is set", key)
+			}
+			key = expandedKeys
+		} else if keys[key] {
+			return nil, fmt.Errorf("duplicate data key %s", key)
+		}
+		keys[key] = true
+		if len(data.Template) > 0 {
+			if err := ValidateTemplate(data.Template); err != nil {
+				return nil, fmt.Errorf("template for data key %s is invalid, err: %w", key, err)
+			}
+		}
+		if len(data.JSONPath) > 0 {
+			if err := ValidateJSONPath(data.JSONPath); err != nil {
+				return nil, fmt.Errorf("jsonPath for data key %s is invalid, err: %w", key, err)
+			}
+		}
+		if err := ValidateTransforms(data.Transforms); err != nil {
+			return nil, fmt.Errorf("transforms for data key %s are invalid, err: %w", key, err)
+		}
+	}
+	return keys, nil
+}
+
+// ValidateSecretObjects validates all the secret objects in the secret provider class
+// and checks that the same secret name isn't used by more than one secret object
+func ValidateSecretObjects(secretObjs []*secretsstorev1.SecretObject) error {
+	names := make(map[string]bool)
+	for i, secretObj := range secretObjs {
+		if secretObj == nil {
+			return fmt.Errorf("secretObjects[%d] is empty", i)
+		}
+		if err := ValidateSecretObject(*secretObj); err != nil {
+			return fmt.Errorf("secretObjects[%d] is invalid, err: %w", i, err)
+		}
+		secretName := strings.TrimSpace(secretObj.SecretName)
+		if names[secretName] {
+			return fmt.Errorf("secretObjects[%d] has duplicate secret name %s", i, secretName)
+		}
+		names[secretName] = true
+	}
 	return nil
 }
 
 // GetSecretData gets the object contents from the pods target path and returns a
-// map that will be populated in the Kubernetes secret data field
-func GetSecretData(secretObjData []*v1alpha1.SecretObjectData, secretType corev1.SecretType, files map[string]string) (map[string][]byte, error) {
+// map that will be populated in the Kubernetes secret data field. If a template is
+// set for the data, the rendered template is used as the content. If a JSONPath is
+// set, only the selected field of the structured content is used. The transforms are
+// then applied, and if expandKeys is set, every top-level key of the structured
+// content is added to the map.
+func GetSecretData(secretObjData []*secretsstorev1.SecretObjectData, secretType corev1.SecretType, files map[string]string) (map[string][]byte, error) {
 	datamap := make(map[string][]byte)
 	for _, data := range secretObjData {
 		objectName := strings.TrimSpace(data.ObjectName)
 		dataKey := strings.TrimSpace(data.Key)
 
-		if len(objectName) == 0 {
+		if len(objectName) == 0 && len(data.Template) == 0 {
 			return datamap, fmt.Errorf("object name in secretObjects.data")
 		}
-		if len(dataKey) == 0 {
+		if len(dataKey) == 0 && !data.ExpandKeys {
 			return datamap, fmt.Errorf("key in secretObjects.data is empty")
 		}
-		file, ok := files[objectName]
-		if !ok {
-			return datamap, fmt.Errorf("file matching objectName %s not found in the pod", objectName)
+		var content []byte
+		var err error
+		file := objectName
+		if len(data.Template) > 0 {
+			if content, err = RenderTemplate(data.Template, files); err != nil {
+				return datamap, fmt.Errorf("failed to render template for key %s, err: %v", dataKey, err)
+			}
+		} else {
+			var ok bool
+			if file, ok = files[objectName]; !ok {
+				return datamap, fmt.Errorf("file matching objectName %s not found in the pod", objectName)
+			}
+			if content, err = os.ReadFile(file); err != nil {
+				return datamap, fmt.Errorf("failed to read file %s, err: %v", objectName, err)
+			}
 		}
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return datamap, fmt.Errorf("failed to read file %s, err: %v", objectName, err)
+		if len(data.JSONPath) > 0 {
+			if content, err = ExtractField(content, data.JSONPath); err != nil {
+				return datamap, fmt.Errorf("failed to extract %s from object %s, err: %v", data.JSONPath, objectName, err)
+			}
 		}
-		datamap[dataKey] = content
-		if secretType == v1.SecretTypeTLS {
-			c, err := GetCertPart(content, dataKey)
-			if err != nil {
-				return datamap, fmt.Errorf("failed to get cert data from file %s, err: %+v", file, err)
+		if len(data.Transforms) > 0 {
+			if content, err = ApplyTransforms(content, data.Transforms, files); err != nil {
+				return datamap, fmt.Errorf("failed to transform object %s, err: %v", objectName, err)
+			}
+		}
+
+		values := map[string][]byte{dataKey: content}
+		if data.ExpandKeys {
+			if values, err = ExpandKeys(content); err != nil {
+				return datamap, fmt.Errorf("failed to expand keys of object %s, err: %v", objectName, err)
+			}
+		}
+		for key, value := range values {
+			datamap[key] = value
+			if secretType == v1.SecretTypeTLS {
+				c, err := GetCertPart(value, key)
+				if err != nil {
+					return datamap, fmt.Errorf("failed to get cert data from file %s, err: %+v", file, err)
+				}
+				datamap[key] = c
 			}
-			datamap[dataKey] = c
 		}
 	}
 	return datamap, nil
 }
 
+// readObject returns the content of the mounted object without the trailing newline
+func readObject(files map[string]string, objectName string) (string, error) {
+	objectName = strings.TrimSpace(objectName)
+	file, ok := files[objectName]
+	if !ok {
+		return "", fmt.Errorf("file matching objectName %s not found in the pod", objectName)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s, err: %v", objectName, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
 // GetSHAFromSecret gets SHA for the secret data
 func GetSHAFromSecret(data map[string][]byte) (string, error) {
 	var values []string
@@ -24,7 +24,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 
-	"sigs.k8s.io/secrets-store-csi-driver/apis/v1alpha1"
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -172,30 +172,124 @@ func TestGetCert(t *testing.T) {
 func TestValidateSecretObject(t *testing.T) {
 	tests := []struct {
 		name          string
-		secretObj     v1alpha1.SecretObject
+		secretObj     secretsstorev1.SecretObject
 		expectedError bool
 	}{
 		{
 			name:          "secret name is empty",
-			secretObj:     v1alpha1.SecretObject{},
+			secretObj:     secretsstorev1.SecretObject{},
 			expectedError: true,
 		},
 		{
 			name:          "secret type is empty",
-			secretObj:     v1alpha1.SecretObject{SecretName: "secret1"},
+			secretObj:     secretsstorev1.SecretObject{SecretName: "secret1"},
 			expectedError: true,
 		},
 		{
 			name:          "data is empty",
-			secretObj:     v1alpha1.SecretObject{SecretName: "secret1", Type: "Opaque"},
+			secretObj:     secretsstorev1.SecretObject{SecretName: "secret1", Type: "Opaque"},
 			expectedError: true,
 		},
+		{
+			name: "data template is invalid",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "Opaque",
+				Data:       []*secretsstorev1.SecretObjectData{{Key: "file1", Template: "{{ .obj1 "}}},
+			expectedError: true,
+		},
+		{
+			name: "data key set with expandKeys",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "Opaque",
+				Data:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1", ExpandKeys: true}}},
+			expectedError: true,
+		},
+		{
+			name: "data jsonPath is invalid",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "Opaque",
+				Data:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1", JSONPath: "{.username"}}},
+			expectedError: true,
+		},
+		{
+			name: "docker config set for another secret type",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName:   "secret1",
+				Type:         "Opaque",
+				DockerConfig: &secretsstorev1.DockerConfigSource{RegistryObjectName: "registry", UsernameObjectName: "username", PasswordObjectName: "password"}},
+			expectedError: true,
+		},
+		{
+			name: "docker config without password object",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName:   "secret1",
+				Type:         "kubernetes.io/dockerconfigjson",
+				DockerConfig: &secretsstorev1.DockerConfigSource{RegistryObjectName: "registry", UsernameObjectName: "username"}},
+			expectedError: true,
+		},
+		{
+			name: "valid docker config secret object",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName:   "secret1",
+				Type:         "kubernetes.io/dockerconfigjson",
+				DockerConfig: &secretsstorev1.DockerConfigSource{RegistryObjectName: "registry", UsernameObjectName: "username", PasswordObjectName: "password"}},
+			expectedError: false,
+		},
+		{
+			name: "secret type is not supported",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "kubernetes.io/unknown",
+				Data:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
+			expectedError: true,
+		},
+		{
+			name: "duplicate data key",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "Opaque",
+				Data: []*secretsstorev1.SecretObjectData{
+					{ObjectName: "obj1", Key: "file1"},
+					{ObjectName: "obj2", Key: "file1"},
+				}},
+			expectedError: true,
+		},
+		{
+			name: "tls secret without tls.key",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "kubernetes.io/tls",
+				Data:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "tls.crt"}}},
+			expectedError: true,
+		},
+		{
+			name: "valid tls secret object",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "kubernetes.io/tls",
+				Data: []*secretsstorev1.SecretObjectData{
+					{ObjectName: "obj1", Key: "tls.crt"},
+					{ObjectName: "obj1", Key: "tls.key"},
+				}},
+			expectedError: false,
+		},
+		{
+			name: "tls secret with expanded keys",
+			secretObj: secretsstorev1.SecretObject{
+				SecretName: "secret1",
+				Type:       "kubernetes.io/tls",
+				Data:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", ExpandKeys: true}}},
+			expectedError: false,
+		},
 		{
 			name: "valid secret object",
-			secretObj: v1alpha1.SecretObject{
+			secretObj: secretsstorev1.SecretObject{
 				SecretName: "secret1",
 				Type:       "Opaque",
-				Data:       []*v1alpha1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
+				Data:       []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
 			expectedError: false,
 		},
 	}
@@ -203,7 +297,55 @@ func TestValidateSecretObject(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			err := ValidateSecretObject(test.secretObj)
-			if test.expectedError && err == nil {
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateSecretObjects(t *testing.T) {
+	tests := []struct {
+		name          string
+		secretObjs    []*secretsstorev1.SecretObject
+		expectedError bool
+	}{
+		{
+			name:          "no secret objects",
+			expectedError: false,
+		},
+		{
+			name:          "nil secret object",
+			secretObjs:    []*secretsstorev1.SecretObject{nil},
+			expectedError: true,
+		},
+		{
+			name:          "invalid secret object",
+			secretObjs:    []*secretsstorev1.SecretObject{{SecretName: "secret1"}},
+			expectedError: true,
+		},
+		{
+			name: "duplicate secret name",
+			secretObjs: []*secretsstorev1.SecretObject{
+				{SecretName: "secret1", Type: "Opaque", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
+				{SecretName: "secret1", Type: "Opaque", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj2", Key: "file2"}}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid secret objects",
+			secretObjs: []*secretsstorev1.SecretObject{
+				{SecretName: "secret1", Type: "Opaque", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
+				{SecretName: "secret2", Type: "Opaque", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj2", Key: "file2"}}},
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSecretObjects(test.secretObjs)
+			if test.expectedError != (err != nil) {
 				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
 			}
 		})
@@ -213,7 +355,7 @@ func TestValidateSecretObject(t *testing.T) {
 func TestGetSecretData(t *testing.T) {
 	tests := []struct {
 		name            string
-		secretObjData   []*v1alpha1.SecretObjectData
+		secretObjData   []*secretsstorev1.SecretObjectData
 		secretType      corev1.SecretType
 		currentFiles    map[string]string
 		expectedDataMap map[string][]byte
@@ -221,7 +363,7 @@ func TestGetSecretData(t *testing.T) {
 	}{
 		{
 			name: "object name not set",
-			secretObjData: []*v1alpha1.SecretObjectData{
+			secretObjData: []*secretsstorev1.SecretObjectData{
 				{
 					ObjectName: "",
 				},
@@ -232,7 +374,7 @@ func TestGetSecretData(t *testing.T) {
 		},
 		{
 			name: "key not set",
-			secretObjData: []*v1alpha1.SecretObjectData{
+			secretObjData: []*secretsstorev1.SecretObjectData{
 				{
 					ObjectName: "obj1",
 				},
@@ -243,7 +385,7 @@ func TestGetSecretData(t *testing.T) {
 		},
 		{
 			name: "file matching object doesn't exist in map",
-			secretObjData: []*v1alpha1.SecretObjectData{
+			secretObjData: []*secretsstorev1.SecretObjectData{
 				{
 					ObjectName: "obj1",
 					Key:        "file1",
@@ -256,7 +398,7 @@ func TestGetSecretData(t *testing.T) {
 		},
 		{
 			name: "file matching object doesn't exist in the fs",
-			secretObjData: []*v1alpha1.SecretObjectData{
+			secretObjData: []*secretsstorev1.SecretObjectData{
 				{
 					ObjectName: "obj1",
 					Key:        "file1",
@@ -269,7 +411,7 @@ func TestGetSecretData(t *testing.T) {
 		},
 		{
 			name: "file matching object found in fs",
-			secretObjData: []*v1alpha1.SecretObjectData{
+			secretObjData: []*secretsstorev1.SecretObjectData{
 				{
 					ObjectName: "obj1",
 					Key:        "file1",
@@ -282,7 +424,7 @@ func TestGetSecretData(t *testing.T) {
 		},
 		{
 			name: "file matching object found in fs after trimming spaces in object name",
-			secretObjData: []*v1alpha1.SecretObjectData{
+			secretObjData: []*secretsstorev1.SecretObjectData{
 				{
 					ObjectName: "obj1     ",
 					Key:        "file1",
@@ -295,7 +437,7 @@ func TestGetSecretData(t *testing.T) {
 		},
 		{
 			name: "file matching object found in fs after trimming spaces in key",
-			secretObjData: []*v1alpha1.SecretObjectData{
+			secretObjData: []*secretsstorev1.SecretObjectData{
 				{
 					ObjectName: "obj1     ",
 					Key:        "   file1",
@@ -306,6 +448,32 @@ func TestGetSecretData(t *testing.T) {
 			expectedDataMap: map[string][]byte{"file1": []byte("test")},
 			expectedError:   false,
 		},
+		{
+			name: "template rendered with object contents",
+			secretObjData: []*secretsstorev1.SecretObjectData{
+				{
+					Key:      "url",
+					Template: `jdbc://{{ .obj1 }}:{{ index . "obj-2" | base64 }}`,
+				},
+			},
+			secretType:      corev1.SecretTypeOpaque,
+			currentFiles:    map[string]string{"obj1": "", "obj-2": ""},
+			expectedDataMap: map[string][]byte{"url": []byte("jdbc://test:dGVzdA==")},
+			expectedError:   false,
+		},
+		{
+			name: "template references object not found in the pod",
+			secretObjData: []*secretsstorev1.SecretObjectData{
+				{
+					Key:      "url",
+					Template: "{{ .obj2 }}",
+				},
+			},
+			secretType:      corev1.SecretTypeOpaque,
+			currentFiles:    map[string]string{"obj1": ""},
+			expectedDataMap: make(map[string][]byte),
+			expectedError:   true,
+		},
 	}
 
 	for _, test := range tests {
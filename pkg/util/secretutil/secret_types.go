@@ -0,0 +1,257 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strings"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dataValidators validates the secret data is valid for the secret type
+var dataValidators = map[corev1.SecretType]func(data map[string][]byte) error{
+	corev1.SecretTypeBasicAuth:        validateBasicAuth,
+	corev1.SecretTypeBootstrapToken:   validateBootstrapToken,
+	corev1.SecretTypeDockerConfigJson: validateDockerConfigJSON,
+	corev1.SecretTypeDockercfg:        validateDockercfg,
+	corev1.SecretTypeSSHAuth:          validateSSHAuth,
+	corev1.SecretTypeTLS:              validateTLS,
+}
+
+// dockerConfigJSON is the format of the .dockerconfigjson data field
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// dockerConfigEntry is the registry entry in the .dockerconfigjson data field
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// ValidateSecretData checks the secret data is valid for the secret type, so
+// misconfigured secret objects are reported before the secret is created
+func ValidateSecretData(secretType corev1.SecretType, data map[string][]byte) error {
+	validate, ok := dataValidators[secretType]
+	if !ok {
+		return nil
+	}
+	if err := validate(data); err != nil {
+		return fmt.Errorf("data is invalid for secret type %s, err: %w", secretType, err)
+	}
+	return nil
+}
+
+// validateBasicAuth checks username or password is set
+func validateBasicAuth(data map[string][]byte) error {
+	_, hasUsername := data[corev1.BasicAuthUsernameKey]
+	_, hasPassword := data[corev1.BasicAuthPasswordKey]
+	if !hasUsername && !hasPassword {
+		return fmt.Errorf("data key %s or %s is required", corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+	return nil
+}
+
+const (
+	// bootstrapTokenIDKey is the id of the bootstrap token
+	bootstrapTokenIDKey = "token-id"
+	// bootstrapTokenSecretKey is the secret of the bootstrap token
+	bootstrapTokenSecretKey = "token-secret"
+)
+
+var (
+	bootstrapTokenIDRe     = regexp.MustCompile(`^[a-z0-9]{6}$`)
+	bootstrapTokenSecretRe = regexp.MustCompile(`^[a-z0-9]{16}$`)
+)
+
+// validateBootstrapToken checks the token id and secret are set and well formed
+func validateBootstrapToken(data map[string][]byte) error {
+	if !bootstrapTokenIDRe.Match(data[bootstrapTokenIDKey]) {
+		return fmt.Errorf("data key %s must match %s", bootstrapTokenIDKey, bootstrapTokenIDRe.String())
+	}
+	if !bootstrapTokenSecretRe.Match(data[bootstrapTokenSecretKey]) {
+		return fmt.Errorf("data key %s must match %s", bootstrapTokenSecretKey, bootstrapTokenSecretRe.String())
+	}
+	return nil
+}
+
+// validateDockerConfigJSON checks .dockerconfigjson is a docker config with auths
+func validateDockerConfigJSON(data map[string][]byte) error {
+	content, ok := data[corev1.DockerConfigJsonKey]
+	if !ok {
+		return fmt.Errorf("data key %s is required", corev1.DockerConfigJsonKey)
+	}
+	var config dockerConfigJSON
+	if err := json.Unmarshal(content, &config); err != nil {
+		return fmt.Errorf("data key %s is not valid JSON, err: %v", corev1.DockerConfigJsonKey, err)
+	}
+	if len(config.Auths) == 0 {
+		return fmt.Errorf("data key %s has no auths", corev1.DockerConfigJsonKey)
+	}
+	for registry, entry := range config.Auths {
+		if err := validateDockerConfigEntry(entry); err != nil {
+			return fmt.Errorf("data key %s auth for %s is invalid, err: %v", corev1.DockerConfigJsonKey, registry, err)
+		}
+	}
+	return nil
+}
+
+// validateDockercfg checks .dockercfg is a legacy docker config
+func validateDockercfg(data map[string][]byte) error {
+	content, ok := data[corev1.DockerConfigKey]
+	if !ok {
+		return fmt.Errorf("data key %s is required", corev1.DockerConfigKey)
+	}
+	var config map[string]dockerConfigEntry
+	if err := json.Unmarshal(content, &config); err != nil {
+		return fmt.Errorf("data key %s is not valid JSON, err: %v", corev1.DockerConfigKey, err)
+	}
+	for registry, entry := range config {
+		if err := validateDockerConfigEntry(entry); err != nil {
+			return fmt.Errorf("data key %s auth for %s is invalid, err: %v", corev1.DockerConfigKey, registry, err)
+		}
+	}
+	return nil
+}
+
+// validateDockerConfigEntry checks the registry entry has credentials
+func validateDockerConfigEntry(entry dockerConfigEntry) error {
+	if len(entry.Auth) == 0 {
+		if len(entry.Username) == 0 && len(entry.Password) == 0 {
+			return fmt.Errorf("auth or username and password are required")
+		}
+		return nil
+	}
+	auth, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return fmt.Errorf("auth is not valid base64, err: %v", err)
+	}
+	if !strings.Contains(string(auth), ":") {
+		return fmt.Errorf("auth must be in the username:password format")
+	}
+	return nil
+}
+
+// validateSSHAuth checks ssh-privatekey is set to a PEM encoded key
+func validateSSHAuth(data map[string][]byte) error {
+	content, ok := data[corev1.SSHAuthPrivateKey]
+	if !ok {
+		return fmt.Errorf("data key %s is required", corev1.SSHAuthPrivateKey)
+	}
+	if block, _ := pem.Decode(content); block == nil {
+		return fmt.Errorf("data key %s is not PEM encoded", corev1.SSHAuthPrivateKey)
+	}
+	return nil
+}
+
+// validateTLS checks tls.crt contains a certificate and tls.key contains a private key
+func validateTLS(data map[string][]byte) error {
+	cert, err := getCert(data[corev1.TLSCertKey])
+	if err != nil || len(cert) == 0 {
+		return fmt.Errorf("data key %s doesn't contain a certificate", corev1.TLSCertKey)
+	}
+	block, _ := pem.Decode(data[corev1.TLSPrivateKeyKey])
+	if block == nil {
+		return fmt.Errorf("data key %s doesn't contain a private key", corev1.TLSPrivateKeyKey)
+	}
+	if _, err := parsePrivateKey(block.Bytes); err != nil {
+		return fmt.Errorf("data key %s doesn't contain a valid private key, err: %v", corev1.TLSPrivateKeyKey, err)
+	}
+	return nil
+}
+
+// validateDockerConfigSource checks the docker config builder is only used for
+// kubernetes.io/dockerconfigjson secrets and all the objects are set
+func validateDockerConfigSource(secretObj secretsstorev1.SecretObject) error {
+	if GetSecretType(strings.TrimSpace(secretObj.Type)) != corev1.SecretTypeDockerConfigJson {
+		return fmt.Errorf("dockerConfig can only be set for secret type %s", corev1.SecretTypeDockerConfigJson)
+	}
+	source := secretObj.DockerConfig
+	for field, objectName := range map[string]string{
+		"registryObjectName": source.RegistryObjectName,
+		"usernameObjectName": source.UsernameObjectName,
+		"passwordObjectName": source.PasswordObjectName,
+	} {
+		if len(strings.TrimSpace(objectName)) == 0 {
+			return fmt.Errorf("dockerConfig %s is empty", field)
+		}
+	}
+	for _, data := range secretObj.Data {
+		if data != nil && strings.TrimSpace(data.Key) == corev1.DockerConfigJsonKey {
+			return fmt.Errorf("data key %s can't be set when dockerConfig is set", corev1.DockerConfigJsonKey)
+		}
+	}
+	return nil
+}
+
+// BuildSecretData gets the secret data for the secret object from the mounted files
+// and adds the data fields assembled by the builders set in the secret object
+func BuildSecretData(secretObj secretsstorev1.SecretObject, secretType corev1.SecretType, files map[string]string) (map[string][]byte, error) {
+	datamap, err := GetSecretData(secretObj.Data, secretType, files)
+	if err != nil {
+		return datamap, err
+	}
+	if secretObj.DockerConfig != nil {
+		config, err := buildDockerConfigJSON(secretObj.DockerConfig, files)
+		if err != nil {
+			return datamap, fmt.Errorf("failed to build %s, err: %v", corev1.DockerConfigJsonKey, err)
+		}
+		datamap[corev1.DockerConfigJsonKey] = config
+	}
+	// the tls data is only rebuilt when the tls options are set, so the data of the
+	// secret objects without the options is synced as is
+	if secretObj.TLS != nil {
+		if err = buildTLSData(datamap, secretObj.TLS, files); err != nil {
+			return datamap, fmt.Errorf("failed to build tls data, err: %v", err)
+		}
+	}
+	return datamap, nil
+}
+
+// buildDockerConfigJSON assembles a .dockerconfigjson from the registry, username
+// and password objects
+func buildDockerConfigJSON(source *secretsstorev1.DockerConfigSource, files map[string]string) ([]byte, error) {
+	registry, err := readObject(files, source.RegistryObjectName)
+	if err != nil {
+		return nil, err
+	}
+	username, err := readObject(files, source.UsernameObjectName)
+	if err != nil {
+		return nil, err
+	}
+	password, err := readObject(files, source.PasswordObjectName)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(dockerConfigJSON{
+		Auths: map[string]dockerConfigEntry{
+			strings.TrimSpace(registry): {
+				Username: username,
+				Password: password,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	})
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateSecretData(t *testing.T) {
+	tests := []struct {
+		name          string
+		secretType    corev1.SecretType
+		data          map[string][]byte
+		expectedError bool
+	}{
+		{
+			name:       "opaque secret",
+			secretType: corev1.SecretTypeOpaque,
+			data:       map[string][]byte{"key": []byte("value")},
+		},
+		{
+			name:       "basic auth with username and password",
+			secretType: corev1.SecretTypeBasicAuth,
+			data:       map[string][]byte{"username": []byte("admin"), "password": []byte("pass")},
+		},
+		{
+			name:          "basic auth without username and password",
+			secretType:    corev1.SecretTypeBasicAuth,
+			data:          map[string][]byte{"user": []byte("admin")},
+			expectedError: true,
+		},
+		{
+			name:       "valid bootstrap token",
+			secretType: corev1.SecretTypeBootstrapToken,
+			data:       map[string][]byte{"token-id": []byte("abcdef"), "token-secret": []byte("0123456789abcdef")},
+		},
+		{
+			name:          "bootstrap token with invalid token id",
+			secretType:    corev1.SecretTypeBootstrapToken,
+			data:          map[string][]byte{"token-id": []byte("ABCDEF"), "token-secret": []byte("0123456789abcdef")},
+			expectedError: true,
+		},
+		{
+			name:       "valid dockerconfigjson",
+			secretType: corev1.SecretTypeDockerConfigJson,
+			data:       map[string][]byte{".dockerconfigjson": []byte(`{"auths":{"registry.io":{"auth":"dXNlcjpwYXNz"}}}`)},
+		},
+		{
+			name:          "dockerconfigjson without auths",
+			secretType:    corev1.SecretTypeDockerConfigJson,
+			data:          map[string][]byte{".dockerconfigjson": []byte(`{"registry.io":{"auth":"dXNlcjpwYXNz"}}`)},
+			expectedError: true,
+		},
+		{
+			name:          "dockerconfigjson with invalid auth",
+			secretType:    corev1.SecretTypeDockerConfigJson,
+			data:          map[string][]byte{".dockerconfigjson": []byte(`{"auths":{"registry.io":{"auth":"dXNlcg=="}}}`)},
+			expectedError: true,
+		},
+		{
+			name:          "dockerconfigjson is not json",
+			secretType:    corev1.SecretTypeDockerConfigJson,
+			data:          map[string][]byte{".dockerconfigjson": []byte("user:pass")},
+			expectedError: true,
+		},
+		{
+			name:       "valid dockercfg",
+			secretType: corev1.SecretTypeDockercfg,
+			data:       map[string][]byte{".dockercfg": []byte(`{"registry.io":{"username":"user","password":"pass"}}`)},
+		},
+		{
+			name:          "dockercfg key missing",
+			secretType:    corev1.SecretTypeDockercfg,
+			data:          map[string][]byte{".dockerconfigjson": []byte(`{}`)},
+			expectedError: true,
+		},
+		{
+			name:          "ssh auth key is not pem",
+			secretType:    corev1.SecretTypeSSHAuth,
+			data:          map[string][]byte{"ssh-privatekey": []byte("key")},
+			expectedError: true,
+		},
+		{
+			name:       "valid tls",
+			secretType: corev1.SecretTypeTLS,
+			data:       map[string][]byte{"tls.crt": []byte(certPEM), "tls.key": []byte(keyPEM)},
+		},
+		{
+			name:          "tls key is not a private key",
+			secretType:    corev1.SecretTypeTLS,
+			data:          map[string][]byte{"tls.crt": []byte(certPEM), "tls.key": []byte(certPEM)},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSecretData(test.secretType, test.data)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestBuildSecretData(t *testing.T) {
+	tests := []struct {
+		name            string
+		secretObj       secretsstorev1.SecretObject
+		objects         map[string]string
+		expectedDataMap map[string][]byte
+		expectedError   bool
+	}{
+		{
+			name: "data without builder",
+			secretObj: secretsstorev1.SecretObject{
+				Type: "Opaque",
+				Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "key1"}},
+			},
+			objects:         map[string]string{"obj1": "value"},
+			expectedDataMap: map[string][]byte{"key1": []byte("value")},
+		},
+		{
+			name: "tls data synced as is without tls options",
+			secretObj: secretsstorev1.SecretObject{
+				Type: "kubernetes.io/tls",
+				Data: []*secretsstorev1.SecretObjectData{
+					{ObjectName: "cert", Key: "tls.crt"},
+					{ObjectName: "key", Key: "tls.key"},
+				},
+			},
+			objects: map[string]string{"cert": caCertPEM + leafCertPEM, "key": leafKeyPKCS1PEM},
+			expectedDataMap: map[string][]byte{
+				"tls.crt": []byte(caCertPEM + leafCertPEM),
+				"tls.key": []byte(leafKeyPKCS1PEM),
+			},
+		},
+		{
+			name: "tls data rebuilt with tls options",
+			secretObj: secretsstorev1.SecretObject{
+				Type: "kubernetes.io/tls",
+				TLS:  &secretsstorev1.TLSOptions{IncludeCA: true},
+				Data: []*secretsstorev1.SecretObjectData{
+					{ObjectName: "cert", Key: "tls.crt"},
+					{ObjectName: "key", Key: "tls.key"},
+				},
+			},
+			objects: map[string]string{"cert": caCertPEM + leafCertPEM, "key": leafKeyPKCS1PEM},
+			expectedDataMap: map[string][]byte{
+				"tls.crt": []byte(leafCertPEM + caCertPEM),
+				"tls.key": []byte(leafKeyPKCS1PEM),
+				"ca.crt":  []byte(caCertPEM),
+			},
+		},
+		{
+			name: "dockerconfigjson built from objects",
+			secretObj: secretsstorev1.SecretObject{
+				Type: "kubernetes.io/dockerconfigjson",
+				DockerConfig: &secretsstorev1.DockerConfigSource{
+					RegistryObjectName: "registry",
+					UsernameObjectName: "username",
+					PasswordObjectName: "password",
+				},
+			},
+			objects: map[string]string{"registry": "registry.io\n", "username": "user", "password": "pass"},
+			expectedDataMap: map[string][]byte{
+				".dockerconfigjson": []byte(`{"auths":{"registry.io":{"username":"user","password":"pass","auth":"dXNlcjpwYXNz"}}}`),
+			},
+		},
+		{
+			name: "dockerconfigjson object not found",
+			secretObj: secretsstorev1.SecretObject{
+				Type: "kubernetes.io/dockerconfigjson",
+				DockerConfig: &secretsstorev1.DockerConfigSource{
+					RegistryObjectName: "registry",
+					UsernameObjectName: "username",
+					PasswordObjectName: "password",
+				},
+			},
+			objects:         map[string]string{"registry": "registry.io", "username": "user"},
+			expectedDataMap: map[string][]byte{},
+			expectedError:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			files := make(map[string]string)
+			for name, content := range test.objects {
+				path := filepath.Join(tmpDir, name)
+				if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+					t.Fatalf("expected err to be nil, got: %+v", err)
+				}
+				files[name] = path
+			}
+			secretType := GetSecretType(test.secretObj.Type)
+			datamap, err := BuildSecretData(test.secretObj, secretType, files)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+			if !reflect.DeepEqual(datamap, test.expectedDataMap) {
+				t.Fatalf("expected: %s, got: %s", test.expectedDataMap, datamap)
+			}
+			if err == nil {
+				if err = ValidateSecretData(secretType, datamap); err != nil {
+					t.Fatalf("expected built data to be valid, got: %+v", err)
+				}
+			}
+		})
+	}
+}
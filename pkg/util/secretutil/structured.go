@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// parseJSONPath parses the JSONPath expression. The enclosing braces are optional,
+// so both {.username} and .username are accepted.
+func parseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	j := jsonpath.New("data")
+	if err := j.Parse(path); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// ValidateJSONPath checks the JSONPath expression can be parsed
+func ValidateJSONPath(path string) error {
+	_, err := parseJSONPath(path)
+	return err
+}
+
+// decodeStructured decodes JSON or YAML content
+func decodeStructured(content []byte) (interface{}, error) {
+	data, err := yaml.ToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("content is not valid JSON or YAML, err: %v", err)
+	}
+	var obj interface{}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("content is not valid JSON or YAML, err: %v", err)
+	}
+	return obj, nil
+}
+
+// fieldValue returns the raw value for strings and the JSON encoding for any
+// other value
+func fieldValue(v interface{}) ([]byte, error) {
+	if s, ok := v.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(v)
+}
+
+// ExtractField returns the single field selected by the JSONPath expression
+// from the JSON or YAML content
+func ExtractField(content []byte, path string) ([]byte, error) {
+	j, err := parseJSONPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jsonPath, err: %v", err)
+	}
+	obj, err := decodeStructured(content)
+	if err != nil {
+		return nil, err
+	}
+	results, err := j.FindResults(obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		var count int
+		for _, r := range results {
+			count += len(r)
+		}
+		return nil, fmt.Errorf("jsonPath must select exactly one field, selected %d", count)
+	}
+	return fieldValue(results[0][0].Interface())
+}
+
+// ExpandKeys returns a map with a value for every top-level key of the JSON or
+// YAML content
+func ExpandKeys(content []byte) (map[string][]byte, error) {
+	obj, err := decodeStructured(content)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("content is not an object")
+	}
+	values := make(map[string][]byte, len(fields))
+	for k, v := range fields {
+		if values[k], err = fieldValue(v); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
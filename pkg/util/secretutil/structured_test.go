@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractField(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		path          string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "string field from json",
+			content:  `{"username": "admin", "password": "pass"}`,
+			path:     "{.password}",
+			expected: "pass",
+		},
+		{
+			name:     "path without braces",
+			content:  `{"username": "admin", "password": "pass"}`,
+			path:     ".username",
+			expected: "admin",
+		},
+		{
+			name:     "nested field from yaml",
+			content:  "db:\n  host: db.local\n  port: 5432\n",
+			path:     "{.db.port}",
+			expected: "5432",
+		},
+		{
+			name:     "object field is json encoded",
+			content:  `{"db": {"host": "db.local"}}`,
+			path:     "{.db}",
+			expected: `{"host":"db.local"}`,
+		},
+		{
+			name:          "field not found",
+			content:       `{"username": "admin"}`,
+			path:          "{.password}",
+			expectedError: true,
+		},
+		{
+			name:          "multiple fields selected",
+			content:       `{"users": ["a", "b"]}`,
+			path:          "{.users[*]}",
+			expectedError: true,
+		},
+		{
+			name:          "content is not structured",
+			content:       "{not json",
+			path:          "{.username}",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ExtractField([]byte(test.content), test.path)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+			if string(actual) != test.expected {
+				t.Fatalf("expected: %q, got: %q", test.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestExpandKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expected      map[string][]byte
+		expectedError bool
+	}{
+		{
+			name:    "json object",
+			content: `{"username": "admin", "password": "pass", "port": 5432}`,
+			expected: map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("pass"),
+				"port":     []byte("5432"),
+			},
+		},
+		{
+			name:    "yaml object",
+			content: "username: admin\noptions:\n  ssl: true\n",
+			expected: map[string][]byte{
+				"username": []byte("admin"),
+				"options":  []byte(`{"ssl":true}`),
+			},
+		},
+		{
+			name:          "content is not an object",
+			content:       `["a", "b"]`,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ExpandKeys([]byte(test.content))
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+			if !test.expectedError && !reflect.DeepEqual(actual, test.expected) {
+				t.Fatalf("expected: %v, got: %v", test.expected, actual)
+			}
+		})
+	}
+}
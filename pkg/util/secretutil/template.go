@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs is the set of functions available to secret object data templates.
+// The set is intentionally small and has no access to the filesystem or environment.
+var templateFuncs = template.FuncMap{
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"trim": strings.TrimSpace,
+	"indent": func(spaces int, s string) string {
+		pad := strings.Repeat(" ", spaces)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+	"toJson": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+}
+
+// parseTemplate parses the secret object data template
+func parseTemplate(tmpl string) (*template.Template, error) {
+	return template.New("data").Option("missingkey=error").Funcs(templateFuncs).Parse(tmpl)
+}
+
+// ValidateTemplate checks the secret object data template can be parsed
+func ValidateTemplate(tmpl string) error {
+	_, err := parseTemplate(tmpl)
+	return err
+}
+
+// RenderTemplate renders the secret object data template with the contents of the
+// mounted files, keyed by object name
+func RenderTemplate(tmpl string, files map[string]string) ([]byte, error) {
+	t, err := parseTemplate(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template, err: %v", err)
+	}
+	objects := make(map[string]string, len(files))
+	for objectName, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s, err: %v", objectName, err)
+		}
+		objects[objectName] = string(content)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, objects); err != nil {
+		return nil, fmt.Errorf("failed to render template, err: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TLSCACertKey is the key for the CA certificates in a kubernetes.io/tls secret
+const TLSCACertKey = "ca.crt"
+
+// validateTLSOptions checks the tls options are only used for kubernetes.io/tls
+// secrets and the key format is supported
+func validateTLSOptions(secretObj secretsstorev1.SecretObject) error {
+	if GetSecretType(strings.TrimSpace(secretObj.Type)) != corev1.SecretTypeTLS {
+		return fmt.Errorf("tls can only be set for secret type %s", corev1.SecretTypeTLS)
+	}
+	switch secretObj.TLS.KeyFormat {
+	case "", secretsstorev1.TLSKeyFormatPKCS1, secretsstorev1.TLSKeyFormatPKCS8:
+	default:
+		return fmt.Errorf("tls keyFormat %s is not supported", secretObj.TLS.KeyFormat)
+	}
+	for _, data := range secretObj.Data {
+		if data != nil && secretObj.TLS.IncludeCA && strings.TrimSpace(data.Key) == TLSCACertKey {
+			return fmt.Errorf("data key %s can't be set when tls includeCA is set", TLSCACertKey)
+		}
+	}
+	return nil
+}
+
+// buildTLSData decrypts the tls.key private key and encodes it in the requested
+// format, orders the tls.crt chain leaf first and verifies the private key matches
+// the leaf certificate. The issuer certificates are added to ca.crt if requested.
+func buildTLSData(datamap map[string][]byte, opts *secretsstorev1.TLSOptions, files map[string]string) error {
+	if opts == nil {
+		opts = &secretsstorev1.TLSOptions{}
+	}
+	keyPEM, certPEM := datamap[corev1.TLSPrivateKeyKey], datamap[corev1.TLSCertKey]
+	if len(keyPEM) == 0 || len(certPEM) == 0 {
+		// missing data is reported by the secret type validation
+		return nil
+	}
+
+	signer, err := decodeTLSPrivateKey(keyPEM, opts.PassphraseObjectName, files)
+	if err != nil {
+		return err
+	}
+	if datamap[corev1.TLSPrivateKeyKey], err = encodeTLSPrivateKey(signer, opts.KeyFormat); err != nil {
+		return err
+	}
+
+	var certs []*x509.Certificate
+	data := certPEM
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate in %s, err: %v", corev1.TLSCertKey, err)
+		}
+		certs = append(certs, cert)
+		data = rest
+	}
+	chain, others, err := orderChain(certs, signer)
+	if err != nil {
+		return err
+	}
+
+	var crt, ca []byte
+	for i, cert := range chain {
+		encoded := pem.EncodeToMemory(&pem.Block{Type: certType, Bytes: cert.Raw})
+		crt = append(crt, encoded...)
+		if i > 0 {
+			ca = append(ca, encoded...)
+		}
+	}
+	for _, cert := range others {
+		crt = append(crt, pem.EncodeToMemory(&pem.Block{Type: certType, Bytes: cert.Raw})...)
+	}
+	datamap[corev1.TLSCertKey] = crt
+	if opts.IncludeCA && len(ca) > 0 {
+		datamap[TLSCACertKey] = ca
+	}
+	return nil
+}
+
+// decodeTLSPrivateKey parses the PEM encoded private key. Encrypted PKCS#8 keys
+// are decrypted with the passphrase from the passphrase object.
+func decodeTLSPrivateKey(keyPEM []byte, passphraseObjectName string, files map[string]string) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("%s doesn't contain a private key", corev1.TLSPrivateKeyKey)
+	}
+	der := block.Bytes
+	if block.Type == encryptedPrivateKeyType {
+		if len(strings.TrimSpace(passphraseObjectName)) == 0 {
+			return nil, fmt.Errorf("%s is encrypted, tls passphraseObjectName is required", corev1.TLSPrivateKeyKey)
+		}
+		passphrase, err := readObject(files, passphraseObjectName)
+		if err != nil {
+			return nil, err
+		}
+		if der, err = decryptPKCS8PrivateKey(der, []byte(passphrase)); err != nil {
+			return nil, err
+		}
+	}
+	signer, err := parsePrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s, err: %v", corev1.TLSPrivateKeyKey, err)
+	}
+	return signer, nil
+}
+
+// encodeTLSPrivateKey PEM encodes the private key in the key format
+func encodeTLSPrivateKey(signer crypto.Signer, format secretsstorev1.TLSKeyFormat) ([]byte, error) {
+	if format == secretsstorev1.TLSKeyFormatPKCS8 {
+		der, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: der}), nil
+	}
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{Type: privateKeyTypeRSA, Bytes: x509.MarshalPKCS1PrivateKey(key)}), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: privateKeyTypeEC, Bytes: der}), nil
+	default:
+		return nil, fmt.Errorf("private key type %T can only be encoded in %s format", signer, secretsstorev1.TLSKeyFormatPKCS8)
+	}
+}
+
+// orderChain returns the chain starting with the leaf certificate that matches
+// the private key, followed by its issuers in order. The certificates that aren't
+// part of the chain are returned separately in their original order.
+func orderChain(certs []*x509.Certificate, signer crypto.Signer) ([]*x509.Certificate, []*x509.Certificate, error) {
+	used := make([]bool, len(certs))
+	leaf := -1
+	for i, cert := range certs {
+		if pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool }); ok && pub.Equal(cert.PublicKey) {
+			leaf = i
+			break
+		}
+	}
+	if leaf == -1 {
+		return nil, nil, fmt.Errorf("%s doesn't match any certificate in %s", corev1.TLSPrivateKeyKey, corev1.TLSCertKey)
+	}
+
+	chain := []*x509.Certificate{certs[leaf]}
+	used[leaf] = true
+	for current := certs[leaf]; !bytes.Equal(current.RawIssuer, current.RawSubject); {
+		next := -1
+		for i, cert := range certs {
+			if !used[i] && bytes.Equal(cert.RawSubject, current.RawIssuer) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			break
+		}
+		used[next] = true
+		current = certs[next]
+		chain = append(chain, current)
+	}
+
+	var others []*x509.Certificate
+	for i, cert := range certs {
+		if !used[i] {
+			others = append(others, cert)
+		}
+	}
+	return chain, others, nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strings"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// maxDecodedSize is the max size of decompressed content. This is the max size
+// of a Kubernetes secret.
+const maxDecodedSize = 1 << 20
+
+// ValidateTransforms checks the secret object data transformations are supported
+// and the options are only set for the matching transformation
+func ValidateTransforms(transforms []secretsstorev1.Transform) error {
+	for i, t := range transforms {
+		switch t.Type {
+		case secretsstorev1.TransformBase64Decode, secretsstorev1.TransformHexDecode, secretsstorev1.TransformGzipDecode:
+			if t.PKCS12 != nil {
+				return fmt.Errorf("transforms[%d] pkcs12 options can't be set for type %s", i, t.Type)
+			}
+		case secretsstorev1.TransformPKCS12:
+			if t.PKCS12 == nil {
+				continue
+			}
+			switch t.PKCS12.Part {
+			case "", secretsstorev1.PKCS12PartCert, secretsstorev1.PKCS12PartKey, secretsstorev1.PKCS12PartCA:
+			default:
+				return fmt.Errorf("transforms[%d] pkcs12 part %s is not supported", i, t.PKCS12.Part)
+			}
+		default:
+			return fmt.Errorf("transforms[%d] type %s is not supported", i, t.Type)
+		}
+	}
+	return nil
+}
+
+// ApplyTransforms applies the transformations in order to the content. The mounted
+// files are used to look up the objects referenced by the transformations.
+func ApplyTransforms(content []byte, transforms []secretsstorev1.Transform, files map[string]string) ([]byte, error) {
+	var err error
+	for i, t := range transforms {
+		switch t.Type {
+		case secretsstorev1.TransformBase64Decode:
+			content, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+		case secretsstorev1.TransformHexDecode:
+			content, err = hex.DecodeString(strings.TrimSpace(string(content)))
+		case secretsstorev1.TransformGzipDecode:
+			content, err = gunzip(content)
+		case secretsstorev1.TransformPKCS12:
+			content, err = decodePKCS12(content, t.PKCS12, files)
+		default:
+			err = fmt.Errorf("type %s is not supported", t.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply transforms[%d] %s, err: %v", i, t.Type, err)
+		}
+	}
+	return content, nil
+}
+
+// gunzip decompresses the gzip content
+func gunzip(content []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	decoded, err := io.ReadAll(io.LimitReader(r, maxDecodedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) > maxDecodedSize {
+		return nil, fmt.Errorf("decompressed content exceeds %d bytes", maxDecodedSize)
+	}
+	return decoded, nil
+}
+
+// decodePKCS12 unpacks the PKCS#12 bundle and returns the requested part in PEM format.
+// Only the legacy encryption supported by golang.org/x/crypto/pkcs12 is accepted, i.e.
+// the bags encrypted with pbeWithSHAAnd3-KeyTripleDES-CBC or pbeWithSHAAnd40BitRC2-CBC
+// and a SHA-1 MAC. Bundles encrypted with PBES2 (AES), the default of OpenSSL 3, fail to
+// decode and have to be exported with the legacy algorithms.
+func decodePKCS12(content []byte, opts *secretsstorev1.PKCS12Transform, files map[string]string) ([]byte, error) {
+	var password string
+	var part secretsstorev1.PKCS12Part
+	if opts != nil {
+		part = opts.Part
+		if len(strings.TrimSpace(opts.PasswordObjectName)) > 0 {
+			var err error
+			if password, err = readObject(files, opts.PasswordObjectName); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	blocks, err := pkcs12.ToPEM(content, password)
+	if err != nil {
+		return nil, err
+	}
+	var key, leaf []byte
+	var certs [][]byte
+	var signer crypto.Signer
+	for _, block := range blocks {
+		if block.Type == certType {
+			certs = append(certs, block.Bytes)
+			continue
+		}
+		// the key is re-encoded in PKCS #8 form as the PEM type set by the
+		// pkcs12 package doesn't match the key encoding
+		if signer, err = parsePrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to parse private key, err: %v", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal private key, err: %v", err)
+		}
+		key = pem.EncodeToMemory(&pem.Block{Type: privateKeyType, Bytes: der})
+	}
+	// the leaf certificate is the one matching the private key. If there is no
+	// key in the bundle, the first certificate is the leaf.
+	leafIndex := 0
+	if signer != nil {
+		leafIndex = -1
+		for i, der := range certs {
+			if publicKeyMatches(der, signer) {
+				leafIndex = i
+				break
+			}
+		}
+	}
+	if leafIndex < 0 {
+		return nil, fmt.Errorf("no certificate in the bundle matches the private key")
+	}
+	var ca []byte
+	for i, der := range certs {
+		encoded := pem.EncodeToMemory(&pem.Block{Type: certType, Bytes: der})
+		if i == leafIndex {
+			leaf = encoded
+			continue
+		}
+		ca = append(ca, encoded...)
+	}
+
+	switch part {
+	case secretsstorev1.PKCS12PartCert:
+		return leaf, nil
+	case secretsstorev1.PKCS12PartKey:
+		return key, nil
+	case secretsstorev1.PKCS12PartCA:
+		return ca, nil
+	default:
+		var all []byte
+		all = append(all, key...)
+		all = append(all, leaf...)
+		return append(all, ca...), nil
+	}
+}
+
+// parsePrivateKey parses a PKCS #1, PKCS #8 or SEC 1 private key in ASN.1 DER form
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unknown private key type")
+	}
+	return signer, nil
+}
+
+// publicKeyMatches checks the certificate public key matches the private key
+func publicKeyMatches(der []byte, signer crypto.Signer) bool {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return false
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && pub.Equal(cert.PublicKey)
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+// pfxBundle is a base64 encoded PKCS#12 bundle with the password "password" that
+// contains a private key, the leaf certificate (CN=test-leaf) and the CA
+// certificate (CN=test-ca)
+const pfxBundle = `
+MIIMKQIBAzCCC+8GCSqGSIb3DQEHAaCCC+AEggvcMIIL2DCCBo8GCSqGSIb3DQEH
+BqCCBoAwggZ8AgEAMIIGdQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQYwDgQITCZj
+CKpislUCAggAgIIGSLuovD1ZxWUGnZykFBK9043GVbkwI8H48piIfqKz9HbZHgnA
+Z6M7RVquPij9ZiqiAqGerKv/w1gy88hS9bp7Ay+Ve9774I6uYbxaIJd6kku0itj1
+kqT4IhYjYXsrm4Y4QYxNf1aEwIILlmQ5jottgH2sy63pYaup5Uik2NyCalvj0WrT
+5AqBrQJCfyOp0HMdRusYsS6/ReDpndU5aOUPr5mO6OdmI7mQYraxabHdKlFxYgdz
+3wclug6kOIRg0me/Plu12KOSIvbD7TKI5uUL11j26NqyAQ0vnw809JVAz46trAzP
+huTux5ght4J2ot3UYniCqV1Y4cR20LRQTl8PrOjfbAy2mXK1walI7vPyxtcgrRM5
+1dwYlXHlpPhF00Ee2P0kW8+aruDTMK/HLvNg2O4/XaP6H3YhuxSgwwyYxfh8YU1U
+IgJn7yDt3b5ZXUtfvXkQ+Y4QpYC5s2xHyx9Z2xIjlN9lxGi+ZNTRy6athWald4Fi
+34sJMovxeGRo33pF7aSrujKfLaH/mRfLFQToveIT2QSI6BOjQINhxrRJrkj5aA9P
+GMz62x3TlAf1YMLGBX1iF3YyIrasoCL4gZZY4qzeZEDi+HiojDX7NsxthCmKd5Tp
+I0XD4VOabZLme7tTCOwr8f9HrnZ6XYadjqvGYrbEKFQjInEuTA8eBAeGwDFJqQYW
+zg1QOyvbT8inmfPu3SsuIpmjmV7lR8pGTWgZsCEsaQbRzDl/ll8G91fWJMra+s5Y
+qtPcxJRJMi22w4ssdJTcUyzXLToeD9fmQhGwohKDd0sj2T2afBhEai0OtccDFeS8
+v/fT0UQzz++BFQ8vmFXDMeHuYy4qjeLZi3OsUgbDwlnqRjCqq8lqlWx60t4s4ib6
+KTqCk1lKVrR9efbugtq+5b/cNFItZfFGzjW7o5zxWPVzvbr+KX2VWM5JM7k2LJ7p
+Q8x51XwzzzFsBYc/y1alf0/QiZlJHrO27aOhJ5vx+eqrsGcAx3/EOsWFGkcYD1Ld
+TqOpwQeCU1YweI+qM4GAzbJXVybQC62b91CwNnaB4uvd0b65MmMXYxD7zP5Aj8Mx
+dUQURzOlNNzRFQQ8XV/F8+GVYQ612jyODfel+1sax448z1/Md1UHvaevqg8xUsF3
+R/4awnr+LTUfdPqJcC4ggi/XznCoc6p9OVcsbs6skN384Hf3ZzNd4ztPALe1iNCs
+5fMlCA8vdyeWEfW/PCUxqRQ4s/rBTbFt4Q6tuPj6gAWp8wk298YvFRPFdk0PQiP/
+cyZj0lyQaqlqvccG5IxYHA13T9dNVzQ4Pi2G1ZfjGno5BuSAzm65SCp8H/xo4C0v
+/vyzYOhtrC8Xwwt7ecOONc50m3n3PrUvwuGRNo8M6hzMgNwXBrjRwa6zHhj00Vu5
+QZvnYA/YeZ05BihZv4uAEhmulPpjrjHOass6zhMW1jAI9Rg/tbSRkZrTY0Ohw5bh
+YrEacZMtg4/+bPHuoNc3+mu2ic1c1nN5fKlbVaZjtZb8dxK9u1giQ3P43SUs/BLY
+rtBpsRxt3n+JURSPPpgFW3PYzdgVLy0y6bIWEbSd7lRM1EHyS+xPzqQuJgmfkB3x
+yiZXDQ5OYcqDEcyTgj1tzSB1UAi8pEFtsfOBgbNTEeN/sQnJAUZc4412NVo5oyZr
+wNMexXKvsC99OyGS0yr1T7uS08QSLkeeZJQ7Hf31X7IJ+UB1QQJ1g0jmS3Drjd2h
++6FHM510FDFcZe/ToQGX7ADaM9JSD3lGcm/9aSDRHcSzQ+tXIA0LvRkKnPRpefHp
+6MT0j2NIwJfPMiZb8GpY678dLrSzATVxuC81AwgmmJn4PDhgP3p/Pb8iwtxmJSNI
+DNghxzXPdiBR5myXmRZ9Z/uKHdikm2Vifo+opayVhprX51DwA2//t+rVoq5YZhj0
+0qOBmYcOyNee6D8p8KhcHVxhWi4wVE29yizZJLdHthR40M92qquOTTVq/HUOFBrT
+85dy3IHrxitTWprsmGCT9mENA02ObfmYw0p4msyIKzBWT0+nNUHWT9Yy8bk8HYMh
+BeImmYLNxXPQs3VGoWBYXjyoD1LxmgObtl5i9L+27E9sDPoRIMNMeNoNvb5Gqg3L
+Yp0ePAK3EEVogpeAPDFNvqySjS4DsknirloM9xjqeIbc9yYRejCCBUEGCSqGSIb3
+DQEHAaCCBTIEggUuMIIFKjCCBSYGCyqGSIb3DQEMCgECoIIE7jCCBOowHAYKKoZI
+hvcNAQwBAzAOBAizOSXUhO7OXAICCAAEggTIREtd+lrFBkrA4RxvB6vWShcBh9em
+2sXtBmILyD85bheu3vvW40YSjnuj53TAxjVB4hlaQdEzTcUpRRVnUYsAvbaGEMFV
+9xKEARBxxdhwNsMjlqvUiFxJR0dY3YHNjGA/gb37FYirY4xnZ0zn6/hNxw5Hb330
+lly/8ZTq1CtfEJtkKpzwhUbtJc+QeGH1UsCXMJba8dN3jWIG9JkwbGCwT30H9gsl
+zDoWwfrk1w+ASb1H1tnEED4dhY0X/oRNhKBqD6gS5p+WAV/s2PxGzLe3mX8kMwDD
+n7qS7dLWypLyf+OYUAtdNuc8E4XBsE5iE12rVKW0bkaeneMUKqdAHJ+hV97bpbsp
+No6jTEFMb2Uwst2PL0CO88UTrF7wMUwbkfwmhmke48RMxH54NIOoYuz+jCxLDyA0
+cJcn2NoA6ggtDD2n1OKNM9mtj0TBnZJGy9VXVOw83NVv4amdggvoQD3TgDpxQpNp
+G9oPggO3rWYF2XfLa+3S/iOZCrSZHoy9KydfXhadcEunwJDl17OSnXUX/0/r9Vgo
+YfQaEtK/hPYLnmTB4FB9yxU8EoW/rledwyLgbIcu1S3KDyvLh27BfLglKdzoWdlD
+/vs4jdLqcNSvtJsR33IGmY9rHl840fk0JSJIJDt4AhKkZLvGFzR7WPYoXaNgYILl
+8Ow60gfBug4OKu3eNoSNe4hJMf2mNoI47mUJB7nQDH3hCmMEStDIiBgy+qFKlrFC
+2wIwFaW2HlV5ga8qHUYTdqMhxi8rYc1yfoE2A8CMGyXDILbN6+CpF49P7lvlAIc1
+Es2cjCZbMgel8B3bWvDdvrDPhXzga0fQ3/BFxHlaDWet/9t5XNC2LsMUdRbm4yEX
+pvz9tXSoMTF/xvizukC6lVErKoLhqXxJoJh17pNWDu3DkRSvQjiTlB0nFxjFIw7O
+roE9IZy+D18bOPWu3IKFjmQMou3+yNDSOUhQkiJQgzxeW4DHY7ICoG0qqkmOMKvB
+msfEe0ZW3VZeB8jAM/TPh6o6L4R/JwjCIj1tQlPJjebolpElX3YqABwtMZsivULw
+uC70SL46sZWMAkyfpohIh8Yb8tVRhZsTGtLgItkr0RYG8zDxMfABhPDNHpnmtIUv
+AqDUnEhj4BjhuyNBDhUQU9iKsBCbocgghtbl48muaR60+GDjLpuySEwIGPtFH/qU
+57KciPRky/C5Jl25sYC/1a1cj+vQNzxxCZyf55lBBoGwphIvCKJ1prx51fWPMeqk
+UtaoajkQoqtjTc+nYNUg6JHdDr4kOi72Rjd+9NyIxJ8/Tz6PoOQMInAMl+AxuZ5s
+7mtgmz5A1L6uzKhyoK/hgK/lnEkFsqbMxCI+HTMmsdTA/xcUdZ+WDtbxj10Itx7o
+WGBY5p65XP9LbEYJimBvpkLOg2n2k9u2T0yUh6xif+ijJEtBBvGgEudtxR+cKiR5
+zb8RX2yApGJ8pastVrTNfot1Ydm7nR9uVrKsZ/KVEY5wKa+t9PrdGTfsy+QtiU2w
+Rww8uuRm/l6WqmD4lSSDIENXnaFB78fgqsIvHAT31CAzmZburvWJ3ghrbZCnEEIG
+nE/gbqAgUJcdTr4HN/psWJ5fcvKNty8KoWPhqwBRyN4t9hEKAPhn9bjdGTmvJFG3
+mNbMMSUwIwYJKoZIhvcNAQkVMRYEFBgFy+JQyPRxl+Dg7Qf65DwtZE8NMDEwITAJ
+BgUrDgMCGgUABBQk9OxnNfVj1AB+aMY3hc9R269urgQIldlgb41C0J8CAggA
+`
+
+// mismatchedPFXBundle is a base64 encoded PKCS#12 bundle with the password "password"
+// that contains a private key and a certificate (CN=test-leaf) for another key
+const mismatchedPFXBundle = `
+MIIJAgIBAzCCCMgGCSqGSIb3DQEHAaCCCLkEggi1MIIIsTCCA48GCSqGSIb3DQEH
+BqCCA4AwggN8AgEAMIIDdQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQYwDgQIxTVa
+yDlVgZ8CAggAgIIDSHcnfxssd8zkEmrvZyOtZvC4Toy4Cp3IbjzwHk4f5POjIex3
+2dBHjQ0iHQpZmuNSABSVnVDY/5K+lX0wCt6EwKt67OZZORNsnHG3Cm0r6X2WvkON
+Sbm1aujiucvnyXTbTeeMmXsuscsFd7oEHTJ6kXGKi/YWSwTb5HFwNteZf7g6EV7Q
+FZgUDDNxRdrkXdSvGJetem2akprbP7HG/0sq5/RGE3st8bUrGQPKfByeinnfyxqJ
+K2KrOOaH8w9CCH001rxy+YvoWHaeYN8lsEhfu5FHx4LyYB2Tn287U2d00M5RULkI
+hqHXs+a3I51EEg5A7cnIViuL0sLPZVEOU416V7PBwQCeSl07nX3gbXZEQUpDUsOB
+z9XO/OEs1OWIOYjKollptyPkVp37tjcKLW1WNhK0goMVvDYEqyxPSFzOI11jz3sM
+VpwVXtAW4LVduOHEhsmUmkQT+wrcl9Gxq4+e4nHqMfi+vR1Dnb+L9dVQOxGfNpdA
+eCAKcGASA16D2vrxVqado9NYP5wnpDg6jRW9gxNQMt+K/h3jm6Quwo6FAk32uO1A
+NlVFj56yPv8ft+BL5bNUuU2NuEPueGnhvnaUtPPpPkCyVsHssHwJaGLs4ydRV5+f
+99MFpzzkhNP2nREwnHoSo4xE4tftVzCt+6udBKOJ6tw/0wik2SRl4r1T4+420t8/
+7DtvzTpLnLBjnAmQ2nTv9tt+1RDA7UC9TXQ3+bHRW2NwWIgY8gsE1RG1GW14hF4q
+N9rB2KqZMZ10TObL0IhD+32mNZYEInA63qF9KCJ6mPyiSBW/1At4R+XmowGVRfmp
+aebdVLQSK3OIEr1iROnuFqETx7FSNzPLxFW9UOIZ4SamNbgMvzG48IJtc7ERtwel
+Bw521B8NtZFwQq4JTpCVFfanjxZSjbnBSlIBtsknLlmwsTUNIn/esDqTDfNzO6yc
+WbUVSRu6GJsAiYi7JVLUDlxFVJYoPrbZlaL167cZHj8VuUqVd/C8gLBr/Sp6YCnM
+o/dZey0aYJtjCDDiIrZvhrQBEMWdOIV1F8xeWTtwnBhBGq4VuYDZL+07QY9dp7oG
+5wjxGq68hf8YSJA7d4PEmtdNPT8JdPeLhjlC9ooXqjs0fD0CaTCCBRoGCSqGSIb3
+DQEHAaCCBQsEggUHMIIFAzCCBP8GCyqGSIb3DQEMCgECoIIE7jCCBOowHAYKKoZI
+hvcNAQwBAzAOBAjN5FmaFaDwygICCAAEggTIONqN560gSosSyhWhM5Izq76UmY+P
+jkMCtyOQtb0VN73NYx0NVlpUeG1SCG1C1UbiO+avlY9bwCF+4iRyLgw3JaIUcTBR
+0fySTVTsLDvax1slcPMPSJfvOT8ZpVp2uld5tozpyyFWqDphUVKht2IIBVKAguFN
+a1tmtR5VvCZ2Cxq4HkBBwX099PXLWpyd6EEJoOIHWkRcK3kOfSq0fVVhTC57E65C
+k9t64Wx6eT1dgq2Sx7WiiV3JXPZt1c67wlNgUZaPoht3Yn77eCXpwSC1AtwhvkOa
+2wlc7ADpfxkQ4HW0npUr42CRXLBugVRe6NCgohRihF96oyzvIBY6R6Te9vhlY0od
+twF4sptNm6F9OFhPEeCYGJMhSMrxCrDOkcHhoQ1PgjCv7gwu1pAP3bsAiIWxoyWi
+T5rua3LVuOjidRZ44+3dOtY6jgPD8b1+tBsmtrWnTnQJag3dIkrJO1Y3OrysA9Be
+AS1VTbYGDu4xKUfk7fb1L8+MMzd0zwaOVGtfiWq5D+o1aPY3xJLQvaZsJzW3nfHz
++QUD5KWa0ytqJmR28C0PP3Hg7al1S65wstGYxQBu/4k8nORNQ74ziBrHLf1XqNoz
+oQm6CcAADfpg2EQLLKUFl0VezShh/pjn/2Cp96Z1l9krsBH4QwRuENQBNxQvxSCN
+7EIgDcBAjTWlGCdw/BMmZFy7m2xgz30os2jEeofh/aqTxbdU/TfGJ8VVHqsZ0rnH
+PwKtABkAR/0pFAzzXfMs9LTBDBHsuDPrybaxhtFds8HQEO5fy0IwgpB2JlrK+Dy3
+cU6DbRvI7/0HllLD9egb6VY9HuYfMn7rStoOWwUm+pYffUrXzgT0fOCnmnnboPF0
+ZJN5itirScrl/oNDlVaHtq4Z+RWsbbphTIblkLxGHgS7Cw/bAPkjlUG26kTl273m
+7zrDDkh+iVE/Sb6qZ5nVqXa59Q4rHQukMFhpwpSuQub0TY6B/lkWLXmFDeaCDF9m
+LrISCRlZYuUYnD8E6LhtVwZWVn/0wWl77CiJAIG4+1orRNluuzwh3w2yFLYRCgmg
+faSlyzkjsgi/EZ+Rj97IMEQiCVAvPrQJ+j8+Kmhh9lNz3qG0lbe9ieEUE29H6JYV
+pZWYySRCvoakVMF+ii9CWinRgNc5vPSv2IbfJJBjmi0giKbh2wwAyd8f5POinFsH
+wMHcc8eRdiRwEChqn5hKx6wLY2HvzoDJzA3kxTX0yOndoZ+AZVfKGppD6/gMH1TI
+NnEwpTv3gSE+snpMCgcBDtEEXlwC47YzkQCIGgne/6J+5ws5u9KPAlQAECD9188h
+EPgwd9bHmY2cw0pyoHgT2JpqZ8Wn/ODJkpyZRPHgFKarH1sVJeVUFdByxfmgAjev
+Cu1fonlyHNtjY7p2oIoSWmsf1i6erNfFfED/l0DoMKF26eAXUfXzqFrNxJxGAwx5
+kMWwKaQmbgiyOlGLHE6uusBW8nP9x3gB1w1W12nP9uk2nKNMU6mLRJUnLxdaGL3P
+pwXQBrEF7lCYyMBL1GRJshp8DuenmmR7tgUFbzdWMELrxRHPCWKVgrkG61w/buaN
+q4XikW3OUydIrZ2wigKGGWTdOoASENG6jbLbVz3+JIokiXY39DUu7Z1s08p/5WMD
+4unmMDEwITAJBgUrDgMCGgUABBQ1HKEA4IaJgnwiisVqTIAUWqdvugQIVYvwZykl
+wVQCAggA
+`
+
+// pemSubjects returns the common names of the certificates and the types of the
+// other PEM blocks in the data
+func pemSubjects(t *testing.T, data []byte) []string {
+	var subjects []string
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == certType {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %+v", err)
+			}
+			subjects = append(subjects, cert.Subject.CommonName)
+		} else {
+			subjects = append(subjects, block.Type)
+		}
+		data = rest
+	}
+	return subjects
+}
+
+func TestApplyTransforms(t *testing.T) {
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	w.Write([]byte("value"))
+	w.Close()
+
+	pkcs12Transforms := func(opts *secretsstorev1.PKCS12Transform) []secretsstorev1.Transform {
+		return []secretsstorev1.Transform{
+			{Type: secretsstorev1.TransformBase64Decode},
+			{Type: secretsstorev1.TransformPKCS12, PKCS12: opts},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		content          []byte
+		transforms       []secretsstorev1.Transform
+		objects          map[string]string
+		expected         string
+		expectedSubjects []string
+		expectedError    bool
+	}{
+		{
+			name:       "base64 decode",
+			content:    []byte("dmFsdWU=\n"),
+			transforms: []secretsstorev1.Transform{{Type: secretsstorev1.TransformBase64Decode}},
+			expected:   "value",
+		},
+		{
+			name:       "hex decode",
+			content:    []byte("76616c7565"),
+			transforms: []secretsstorev1.Transform{{Type: secretsstorev1.TransformHexDecode}},
+			expected:   "value",
+		},
+		{
+			name:       "gzip decode",
+			content:    gzipped.Bytes(),
+			transforms: []secretsstorev1.Transform{{Type: secretsstorev1.TransformGzipDecode}},
+			expected:   "value",
+		},
+		{
+			name:          "invalid base64",
+			content:       []byte("not base64!"),
+			transforms:    []secretsstorev1.Transform{{Type: secretsstorev1.TransformBase64Decode}},
+			expectedError: true,
+		},
+		{
+			name:             "pkcs12 all parts",
+			content:          []byte(pfxBundle),
+			transforms:       pkcs12Transforms(&secretsstorev1.PKCS12Transform{PasswordObjectName: "password"}),
+			objects:          map[string]string{"password": "password\n"},
+			expectedSubjects: []string{"PRIVATE KEY", "test-leaf", "test-ca"},
+		},
+		{
+			name:             "pkcs12 cert",
+			content:          []byte(pfxBundle),
+			transforms:       pkcs12Transforms(&secretsstorev1.PKCS12Transform{PasswordObjectName: "password", Part: secretsstorev1.PKCS12PartCert}),
+			objects:          map[string]string{"password": "password"},
+			expectedSubjects: []string{"test-leaf"},
+		},
+		{
+			name:             "pkcs12 key",
+			content:          []byte(pfxBundle),
+			transforms:       pkcs12Transforms(&secretsstorev1.PKCS12Transform{PasswordObjectName: "password", Part: secretsstorev1.PKCS12PartKey}),
+			objects:          map[string]string{"password": "password"},
+			expectedSubjects: []string{"PRIVATE KEY"},
+		},
+		{
+			name:             "pkcs12 ca",
+			content:          []byte(pfxBundle),
+			transforms:       pkcs12Transforms(&secretsstorev1.PKCS12Transform{PasswordObjectName: "password", Part: secretsstorev1.PKCS12PartCA}),
+			objects:          map[string]string{"password": "password"},
+			expectedSubjects: []string{"test-ca"},
+		},
+		{
+			name:          "pkcs12 wrong password",
+			content:       []byte(pfxBundle),
+			transforms:    pkcs12Transforms(&secretsstorev1.PKCS12Transform{PasswordObjectName: "password"}),
+			objects:       map[string]string{"password": "wrong"},
+			expectedError: true,
+		},
+		{
+			name:          "pkcs12 no certificate matches the private key",
+			content:       []byte(mismatchedPFXBundle),
+			transforms:    pkcs12Transforms(&secretsstorev1.PKCS12Transform{PasswordObjectName: "password", Part: secretsstorev1.PKCS12PartCert}),
+			objects:       map[string]string{"password": "password"},
+			expectedError: true,
+		},
+		{
+			name:          "pkcs12 password object not found",
+			content:       []byte(pfxBundle),
+			transforms:    pkcs12Transforms(&secretsstorev1.PKCS12Transform{PasswordObjectName: "password"}),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			files := make(map[string]string)
+			for name, content := range test.objects {
+				path := filepath.Join(tmpDir, name)
+				if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+					t.Fatalf("expected err to be nil, got: %+v", err)
+				}
+				files[name] = path
+			}
+			actual, err := ApplyTransforms(test.content, test.transforms, files)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+			if test.expectedSubjects != nil {
+				subjects := pemSubjects(t, actual)
+				if len(subjects) != len(test.expectedSubjects) {
+					t.Fatalf("expected: %v, got: %v", test.expectedSubjects, subjects)
+				}
+				for i := range subjects {
+					if subjects[i] != test.expectedSubjects[i] {
+						t.Fatalf("expected: %v, got: %v", test.expectedSubjects, subjects)
+					}
+				}
+				return
+			}
+			if string(actual) != test.expected {
+				t.Fatalf("expected: %q, got: %q", test.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestValidateTransforms(t *testing.T) {
+	tests := []struct {
+		name          string
+		transforms    []secretsstorev1.Transform
+		expectedError bool
+	}{
+		{
+			name:       "valid transforms",
+			transforms: []secretsstorev1.Transform{{Type: secretsstorev1.TransformBase64Decode}, {Type: secretsstorev1.TransformPKCS12, PKCS12: &secretsstorev1.PKCS12Transform{Part: secretsstorev1.PKCS12PartKey}}},
+		},
+		{
+			name:          "unknown type",
+			transforms:    []secretsstorev1.Transform{{Type: "Unknown"}},
+			expectedError: true,
+		},
+		{
+			name:          "pkcs12 options for another type",
+			transforms:    []secretsstorev1.Transform{{Type: secretsstorev1.TransformHexDecode, PKCS12: &secretsstorev1.PKCS12Transform{}}},
+			expectedError: true,
+		},
+		{
+			name:          "unknown pkcs12 part",
+			transforms:    []secretsstorev1.Transform{{Type: secretsstorev1.TransformPKCS12, PKCS12: &secretsstorev1.PKCS12Transform{Part: "Chain"}}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTransforms(test.transforms)
+			if test.expectedError != (err != nil) {
+				t.Fatalf("expected err: %+v, got: %+v", test.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package spcpsutil holds Secrets CSI Driver utilities for dealing with
+// SecretProviderClassPodStatus objects.
+package spcpsutil
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+const (
+	// maxConditionMessageLength is the maximum length of the condition message
+	maxConditionMessageLength = 1024
+	redacted                  = "[REDACTED]"
+)
+
+var (
+	// pemBlockRe matches PEM encoded blocks such as certificates and private keys
+	pemBlockRe = regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]+-----.*?-----END [A-Z0-9 ]+-----`)
+	// encodedValueRe matches long base64 or hex encoded values that could be secret contents
+	encodedValueRe = regexp.MustCompile(`[A-Za-z0-9+/]{40,}={0,2}`)
+)
+
+// RedactMessage removes contents that could be secret material from the message
+// and truncates it, so it's safe to be stored in the object conditions
+func RedactMessage(message string) string {
+	message = pemBlockRe.ReplaceAllString(message, redacted)
+	message = encodedValueRe.ReplaceAllString(message, redacted)
+	if len(message) > maxConditionMessageLength {
+		message = message[:maxConditionMessageLength]
+	}
+	return message
+}
+
+// SetCondition sets the condition with the redacted message in the spc pod status.
+// The last transition time is only updated when the condition status changes.
+// Returns true if the condition was added or changed.
+func SetCondition(spcps *secretsstorev1.SecretProviderClassPodStatus, conditionType string, status metav1.ConditionStatus, reason, message string) bool {
+	message = RedactMessage(message)
+	if c := meta.FindStatusCondition(spcps.Status.Conditions, conditionType); c != nil &&
+		c.Status == status && c.Reason == reason && c.Message == message {
+		return false
+	}
+	meta.SetStatusCondition(&spcps.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: spcps.Generation,
+	})
+	return true
+}
+
+// RemoveCondition removes the condition from the spc pod status.
+// Returns true if the condition existed and was removed.
+func RemoveCondition(spcps *secretsstorev1.SecretProviderClassPodStatus, conditionType string) bool {
+	if meta.FindStatusCondition(spcps.Status.Conditions, conditionType) == nil {
+		return false
+	}
+	meta.RemoveStatusCondition(&spcps.Status.Conditions, conditionType)
+	return true
+}
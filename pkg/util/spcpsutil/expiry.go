@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spcpsutil
+
+import (
+	"time"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+// ObjectVersions returns the versions of the objects keyed by id.
+func ObjectVersions(objects []secretsstorev1.SecretProviderClassObject) map[string]string {
+	objectVersions := make(map[string]string, len(objects))
+	for _, obj := range objects {
+		objectVersions[obj.ID] = obj.Version
+	}
+	return objectVersions
+}
+
+// MergeObjectExpiry sets the fetch time of the new objects whose version and expiry didn't change
+// to the fetch time of the old objects, so the lifetime of an object version is measured from when
+// its expiry was first fetched and not from the most recent rotation.
+// Returns true if the expiry of any object was added, changed or removed.
+func MergeObjectExpiry(oldObjects, newObjects []secretsstorev1.SecretProviderClassObject) bool {
+	old := make(map[string]secretsstorev1.SecretProviderClassObject, len(oldObjects))
+	for _, obj := range oldObjects {
+		old[obj.ID] = obj
+	}
+	var changed bool
+	for i := range newObjects {
+		obj := &newObjects[i]
+		oldObj, ok := old[obj.ID]
+		delete(old, obj.ID)
+		if !ok || !obj.ExpiresAt.Equal(oldObj.ExpiresAt) {
+			changed = changed || obj.ExpiresAt != nil || oldObj.ExpiresAt != nil
+			continue
+		}
+		if obj.Version == oldObj.Version && oldObj.FetchedAt != nil {
+			obj.FetchedAt = oldObj.FetchedAt.DeepCopy()
+		}
+	}
+	for _, obj := range old {
+		changed = changed || obj.ExpiresAt != nil
+	}
+	return changed
+}
+
+// RefreshTime returns the time the object is due for refresh, once fraction of the lifetime of
+// its version has elapsed. If the lifetime is unknown, the object is due for refresh at its expiry.
+// Returns false if the object doesn't expire.
+func RefreshTime(obj secretsstorev1.SecretProviderClassObject, fraction float64) (time.Time, bool) {
+	if obj.ExpiresAt == nil {
+		return time.Time{}, false
+	}
+	if obj.FetchedAt == nil || !obj.FetchedAt.Before(obj.ExpiresAt) {
+		return obj.ExpiresAt.Time, true
+	}
+	lifetime := obj.ExpiresAt.Sub(obj.FetchedAt.Time)
+	return obj.FetchedAt.Add(time.Duration(float64(lifetime) * fraction)), true
+}
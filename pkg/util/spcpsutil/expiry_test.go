@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spcpsutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestMergeObjectExpiry(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	t0, t1, t2 := metav1.NewTime(now.Add(-time.Hour)), metav1.NewTime(now), metav1.NewTime(now.Add(time.Hour))
+
+	tests := []struct {
+		name            string
+		oldObjects      []secretsstorev1.SecretProviderClassObject
+		newObjects      []secretsstorev1.SecretProviderClassObject
+		expectedObjects []secretsstorev1.SecretProviderClassObject
+		expectedChanged bool
+	}{
+		{
+			name:            "no expiry",
+			oldObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1"}},
+			newObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v2"}},
+			expectedObjects: []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v2"}},
+		},
+		{
+			name:            "same version and expiry keeps the fetch time",
+			oldObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t2, FetchedAt: &t0}},
+			newObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t2, FetchedAt: &t1}},
+			expectedObjects: []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t2, FetchedAt: &t0}},
+		},
+		{
+			name:            "new version with the same expiry",
+			oldObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t2, FetchedAt: &t0}},
+			newObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v2", ExpiresAt: &t2, FetchedAt: &t1}},
+			expectedObjects: []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v2", ExpiresAt: &t2, FetchedAt: &t1}},
+		},
+		{
+			name:            "renewed expiry",
+			oldObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t1, FetchedAt: &t0}},
+			newObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t2, FetchedAt: &t1}},
+			expectedObjects: []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t2, FetchedAt: &t1}},
+			expectedChanged: true,
+		},
+		{
+			name:            "expiry removed",
+			oldObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t1, FetchedAt: &t0}},
+			newObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1"}},
+			expectedObjects: []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1"}},
+			expectedChanged: true,
+		},
+		{
+			name:            "expiring object removed",
+			oldObjects:      []secretsstorev1.SecretProviderClassObject{{ID: "secret/object1", Version: "v1", ExpiresAt: &t1, FetchedAt: &t0}},
+			expectedChanged: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed := MergeObjectExpiry(test.oldObjects, test.newObjects)
+			if changed != test.expectedChanged {
+				t.Errorf("MergeObjectExpiry() = %v, want %v", changed, test.expectedChanged)
+			}
+			if diff := cmp.Diff(test.expectedObjects, test.newObjects); diff != "" {
+				t.Errorf("MergeObjectExpiry() objects mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRefreshTime(t *testing.T) {
+	now := time.Now()
+	fetchedAt, expiresAt := metav1.NewTime(now), metav1.NewTime(now.Add(time.Hour))
+
+	if _, ok := RefreshTime(secretsstorev1.SecretProviderClassObject{ID: "secret/object1"}, 0.5); ok {
+		t.Errorf("RefreshTime() expected an object without expiry to not be refreshed")
+	}
+	refreshAt, ok := RefreshTime(secretsstorev1.SecretProviderClassObject{ID: "secret/object1", ExpiresAt: &expiresAt, FetchedAt: &fetchedAt}, 0.5)
+	if !ok || !refreshAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("RefreshTime() = %v, %v, want %v", refreshAt, ok, now.Add(30*time.Minute))
+	}
+	// the object is refreshed at the expiry if the fetch time is unknown
+	refreshAt, ok = RefreshTime(secretsstorev1.SecretProviderClassObject{ID: "secret/object1", ExpiresAt: &expiresAt}, 0.5)
+	if !ok || !refreshAt.Equal(expiresAt.Time) {
+		t.Errorf("RefreshTime() = %v, %v, want %v", refreshAt, ok, expiresAt.Time)
+	}
+}
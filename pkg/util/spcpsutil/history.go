@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spcpsutil
+
+import (
+	"sort"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+// ObjectVersionChanges returns the objects whose version differs between the old and new
+// object versions, sorted by id. An object missing from the old or new versions was added
+// or removed.
+func ObjectVersionChanges(oldObjectVersions, newObjectVersions map[string]string) []secretsstorev1.RotationHistoryObject {
+	var changes []secretsstorev1.RotationHistoryObject
+	for id, newVersion := range newObjectVersions {
+		if oldVersion, ok := oldObjectVersions[id]; !ok || oldVersion != newVersion {
+			changes = append(changes, secretsstorev1.RotationHistoryObject{ID: id, OldVersion: oldVersion, NewVersion: newVersion})
+		}
+	}
+	for id, oldVersion := range oldObjectVersions {
+		if _, ok := newObjectVersions[id]; !ok {
+			changes = append(changes, secretsstorev1.RotationHistoryObject{ID: id, OldVersion: oldVersion})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes
+}
+
+// AddRotationHistory adds the entry as the most recent entry of the rotation history in the
+// spc pod status and drops the oldest entries beyond limit. A failure with the same reason as
+// the most recent failure, or a success that didn't change any object after a success, isn't
+// added, so the history isn't filled by the periodic rotations.
+// Returns true if the entry was added.
+func AddRotationHistory(spcps *secretsstorev1.SecretProviderClassPodStatus, entry secretsstorev1.RotationHistoryEntry, limit int) bool {
+	history := spcps.Status.RotationHistory
+	if len(history) > 0 && history[0].Result == secretsstorev1.RotationResultFailed &&
+		entry.Result == secretsstorev1.RotationResultFailed && history[0].Reason == entry.Reason {
+		return false
+	}
+	if entry.Result == secretsstorev1.RotationResultSucceeded && len(entry.Objects) == 0 &&
+		(len(history) == 0 || history[0].Result == secretsstorev1.RotationResultSucceeded) {
+		return false
+	}
+	history = append([]secretsstorev1.RotationHistoryEntry{entry}, history...)
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	spcps.Status.RotationHistory = history
+	return true
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spcpsutil
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestObjectVersionChanges(t *testing.T) {
+	oldObjectVersions := map[string]string{"secret/object1": "v1", "secret/object2": "v1", "secret/object3": "v1"}
+	newObjectVersions := map[string]string{"secret/object1": "v2", "secret/object2": "v1", "secret/object4": "v1"}
+
+	expected := []secretsstorev1.RotationHistoryObject{
+		{ID: "secret/object1", OldVersion: "v1", NewVersion: "v2"},
+		{ID: "secret/object3", OldVersion: "v1"},
+		{ID: "secret/object4", NewVersion: "v1"},
+	}
+	if diff := cmp.Diff(expected, ObjectVersionChanges(oldObjectVersions, newObjectVersions)); diff != "" {
+		t.Errorf("ObjectVersionChanges() mismatch (-want +got):\n%s", diff)
+	}
+	if changes := ObjectVersionChanges(oldObjectVersions, oldObjectVersions); len(changes) != 0 {
+		t.Errorf("ObjectVersionChanges() = %v, want no changes", changes)
+	}
+}
+
+func TestAddRotationHistory(t *testing.T) {
+	succeeded := secretsstorev1.RotationHistoryEntry{
+		Result:  secretsstorev1.RotationResultSucceeded,
+		Reason:  secretsstorev1.RotationSucceededReason,
+		Objects: []secretsstorev1.RotationHistoryObject{{ID: "secret/object1", OldVersion: "v1", NewVersion: "v2"}},
+	}
+	unchanged := secretsstorev1.RotationHistoryEntry{Result: secretsstorev1.RotationResultSucceeded, Reason: secretsstorev1.RotationSucceededReason}
+	failed := secretsstorev1.RotationHistoryEntry{Result: secretsstorev1.RotationResultFailed, Reason: "ProviderError"}
+	throttled := secretsstorev1.RotationHistoryEntry{Result: secretsstorev1.RotationResultFailed, Reason: "ProviderThrottled"}
+
+	tests := []struct {
+		name     string
+		history  []secretsstorev1.RotationHistoryEntry
+		entry    secretsstorev1.RotationHistoryEntry
+		added    bool
+		expected []secretsstorev1.RotationHistoryEntry
+	}{
+		{
+			name:     "first rotation with changes",
+			entry:    succeeded,
+			added:    true,
+			expected: []secretsstorev1.RotationHistoryEntry{succeeded},
+		},
+		{
+			name:  "first rotation without changes",
+			entry: unchanged,
+		},
+		{
+			name:     "rotation without changes after success",
+			history:  []secretsstorev1.RotationHistoryEntry{succeeded},
+			entry:    unchanged,
+			expected: []secretsstorev1.RotationHistoryEntry{succeeded},
+		},
+		{
+			name:     "rotation without changes after failure",
+			history:  []secretsstorev1.RotationHistoryEntry{failed},
+			entry:    unchanged,
+			added:    true,
+			expected: []secretsstorev1.RotationHistoryEntry{unchanged, failed},
+		},
+		{
+			name:     "failure with the same reason",
+			history:  []secretsstorev1.RotationHistoryEntry{failed, succeeded},
+			entry:    failed,
+			expected: []secretsstorev1.RotationHistoryEntry{failed, succeeded},
+		},
+		{
+			name:     "failure with another reason",
+			history:  []secretsstorev1.RotationHistoryEntry{failed},
+			entry:    throttled,
+			added:    true,
+			expected: []secretsstorev1.RotationHistoryEntry{throttled, failed},
+		},
+		{
+			name:     "oldest entry is dropped",
+			history:  []secretsstorev1.RotationHistoryEntry{failed, succeeded, throttled},
+			entry:    succeeded,
+			added:    true,
+			expected: []secretsstorev1.RotationHistoryEntry{succeeded, failed, succeeded},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spcps := &secretsstorev1.SecretProviderClassPodStatus{
+				Status: secretsstorev1.SecretProviderClassPodStatusStatus{RotationHistory: test.history},
+			}
+			if added := AddRotationHistory(spcps, test.entry, 3); added != test.added {
+				t.Errorf("AddRotationHistory() = %v, want %v", added, test.added)
+			}
+			if diff := cmp.Diff(test.expected, spcps.Status.RotationHistory); diff != "" {
+				t.Errorf("AddRotationHistory() history mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	"sigs.k8s.io/secrets-store-csi-driver/pkg/util/k8sutil"
+)
+
+const (
+	// MutatePodPath is the path the pod mutating webhook is served on
+	MutatePodPath = "/mutate-v1-pod"
+
+	// InjectAnnotation is set on a pod to the name of the secret provider class
+	// the secrets-store volume is injected for
+	InjectAnnotation = "secrets-store.csi.k8s.io/inject"
+	// InjectKindAnnotation is the kind of the injected secret provider class,
+	// SecretProviderClass (default) or ClusterSecretProviderClass
+	InjectKindAnnotation = "secrets-store.csi.k8s.io/inject-kind"
+	// InjectMountPathAnnotation is the path the volume is mounted at in the containers
+	InjectMountPathAnnotation = "secrets-store.csi.k8s.io/inject-mount-path"
+	// InjectContainersAnnotation is the comma separated list of the containers and init
+	// containers the volume is mounted in. Defaults to all the containers.
+	InjectContainersAnnotation = "secrets-store.csi.k8s.io/inject-containers"
+	// InjectNodePublishSecretRefAnnotation is the name of the secret passed to the
+	// provider as the nodePublishSecretRef of the volume
+	InjectNodePublishSecretRefAnnotation = "secrets-store.csi.k8s.io/inject-node-publish-secret-ref"
+	// InjectEnvAnnotation is the comma separated list of <env name>=<secret name>/<key>
+	// env entries added to the containers from the synced secrets
+	InjectEnvAnnotation = "secrets-store.csi.k8s.io/inject-env"
+
+	// InjectedVolumeName is the name of the injected secrets-store volume
+	InjectedVolumeName = "secrets-store-inline"
+	// DefaultInjectMountPath is the default path the injected volume is mounted at
+	DefaultInjectMountPath = "/mnt/secrets-store"
+)
+
+// PodInjector injects the secrets-store volume into pods on create based on the pod annotations
+type PodInjector struct {
+	decoder *admission.Decoder
+}
+
+// SetupWithManager registers the mutating webhook with the manager webhook server
+func (i *PodInjector) SetupWithManager(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(MutatePodPath, &webhook.Admission{Handler: i})
+}
+
+// Handle injects the secrets-store volume into the pod in the admission request and rejects
+// the pod if the inject annotations are invalid
+func (i *PodInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := i.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	injected, err := InjectSecretsStoreVolume(pod)
+	if err != nil {
+		klog.InfoS("denied pod with invalid inject annotations", "pod", klog.ObjectRef{Namespace: req.Namespace, Name: pod.Name}, "err", err)
+		return admission.Denied(err.Error())
+	}
+	if !injected {
+		return admission.Allowed("")
+	}
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	klog.V(5).InfoS("injected secrets-store volume", "pod", klog.ObjectRef{Namespace: req.Namespace, Name: pod.Name}, "spc", pod.Annotations[InjectAnnotation])
+	return admission.PatchResponseFromRaw(req.Object.Raw, raw)
+}
+
+// InjectDecoder injects the decoder into the injector
+func (i *PodInjector) InjectDecoder(d *admission.Decoder) error {
+	i.decoder = d
+	return nil
+}
+
+// InjectSecretsStoreVolume adds the secrets-store volume, volume mounts and env entries requested
+// by the inject annotations to the pod. The volume uses the same driver name and volume attributes
+// as the volumes the driver mounts. Returns false if the pod doesn't have the inject annotation.
+func InjectSecretsStoreVolume(pod *corev1.Pod) (bool, error) {
+	spcName := strings.TrimSpace(pod.Annotations[InjectAnnotation])
+	if len(spcName) == 0 {
+		return false, nil
+	}
+
+	spcKind := strings.TrimSpace(pod.Annotations[InjectKindAnnotation])
+	attribute := k8sutil.SecretProviderClassVolumeAttribute
+	switch spcKind {
+	case "", secretsstorev1.SecretProviderClassKind:
+		spcKind = secretsstorev1.SecretProviderClassKind
+	case secretsstorev1.ClusterSecretProviderClassKind:
+		attribute = k8sutil.ClusterSecretProviderClassVolumeAttribute
+	default:
+		return false, fmt.Errorf("invalid %s %q, must be %s or %s", InjectKindAnnotation, spcKind, secretsstorev1.SecretProviderClassKind, secretsstorev1.ClusterSecretProviderClassKind)
+	}
+
+	env, err := parseInjectEnv(pod.Annotations[InjectEnvAnnotation])
+	if err != nil {
+		return false, err
+	}
+	containers, err := injectContainers(pod, pod.Annotations[InjectContainersAnnotation])
+	if err != nil {
+		return false, err
+	}
+
+	// the volume isn't injected again if the pod already has a volume for the secret provider class
+	volumeName := InjectedVolumeName
+	if vol := k8sutil.SPCVolume(pod, spcKind, spcName); vol != nil {
+		volumeName = vol.Name
+	} else {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.Name == InjectedVolumeName {
+				return false, fmt.Errorf("volume %s already exists and doesn't reference %s %s", InjectedVolumeName, spcKind, spcName)
+			}
+		}
+		readOnly := true
+		csi := &corev1.CSIVolumeSource{
+			Driver:           k8sutil.DriverName,
+			ReadOnly:         &readOnly,
+			VolumeAttributes: map[string]string{attribute: spcName},
+		}
+		if secretName := strings.TrimSpace(pod.Annotations[InjectNodePublishSecretRefAnnotation]); len(secretName) > 0 {
+			csi.NodePublishSecretRef = &corev1.LocalObjectReference{Name: secretName}
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         volumeName,
+			VolumeSource: corev1.VolumeSource{CSI: csi},
+		})
+	}
+
+	mountPath := strings.TrimSpace(pod.Annotations[InjectMountPathAnnotation])
+	if len(mountPath) == 0 {
+		mountPath = DefaultInjectMountPath
+	}
+	for _, container := range containers {
+		injectVolumeMount(container, volumeName, mountPath)
+		injectEnv(container, env)
+	}
+	return true, nil
+}
+
+// injectContainers returns the containers and init containers named in the comma separated
+// names, or all the containers if names is empty
+func injectContainers(pod *corev1.Pod, names string) ([]*corev1.Container, error) {
+	var containers []*corev1.Container
+	if len(strings.TrimSpace(names)) == 0 {
+		for i := range pod.Spec.Containers {
+			containers = append(containers, &pod.Spec.Containers[i])
+		}
+		return containers, nil
+	}
+
+	byName := make(map[string]*corev1.Container)
+	for i := range pod.Spec.InitContainers {
+		byName[pod.Spec.InitContainers[i].Name] = &pod.Spec.InitContainers[i]
+	}
+	for i := range pod.Spec.Containers {
+		byName[pod.Spec.Containers[i].Name] = &pod.Spec.Containers[i]
+	}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		container, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("container %s in %s not found in pod", name, InjectContainersAnnotation)
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// parseInjectEnv parses the comma separated list of <env name>=<secret name>/<key> entries
+// into env entries sourced from the synced secrets
+func parseInjectEnv(value string) ([]corev1.EnvVar, error) {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil, nil
+	}
+	var env []corev1.EnvVar
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		name, ref := entry, ""
+		if i := strings.Index(entry, "="); i >= 0 {
+			name, ref = strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		}
+		secretName, key := ref, ""
+		if i := strings.Index(ref, "/"); i >= 0 {
+			secretName, key = ref[:i], ref[i+1:]
+		}
+		if len(name) == 0 || len(secretName) == 0 || len(key) == 0 {
+			return nil, fmt.Errorf("invalid entry %q in %s, must be <env name>=<secret name>/<key>", entry, InjectEnvAnnotation)
+		}
+		env = append(env, corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	return env, nil
+}
+
+// injectVolumeMount mounts the volume read only in the container, unless the container
+// already mounts the volume
+func injectVolumeMount(container *corev1.Container, volumeName, mountPath string) {
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == volumeName {
+			return
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  true,
+	})
+}
+
+// injectEnv adds the env entries that aren't already set in the container
+func injectEnv(container *corev1.Container, env []corev1.EnvVar) {
+	for _, e := range env {
+		exists := false
+		for _, ce := range container.Env {
+			if ce.Name == e.Name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			container.Env = append(container.Env, e)
+		}
+	}
+}
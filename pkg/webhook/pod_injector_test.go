@@ -0,0 +1,273 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newInjectPod(annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod1",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+		},
+	}
+}
+
+func TestInjectSecretsStoreVolume(t *testing.T) {
+	readOnly := true
+	mount := corev1.VolumeMount{Name: InjectedVolumeName, MountPath: DefaultInjectMountPath, ReadOnly: true}
+
+	tests := []struct {
+		name                string
+		annotations         map[string]string
+		expectedInjected    bool
+		expectedErr         bool
+		expectedVolume      *corev1.Volume
+		expectedMounts      map[string][]corev1.VolumeMount
+		expectedEnvNames    map[string][]string
+		existingVolume      *corev1.Volume
+		expectedVolumeCount int
+	}{
+		{
+			name:             "no inject annotation",
+			annotations:      map[string]string{"foo": "bar"},
+			expectedInjected: false,
+		},
+		{
+			name:             "secret provider class",
+			annotations:      map[string]string{InjectAnnotation: "spc1"},
+			expectedInjected: true,
+			expectedVolume: &corev1.Volume{
+				Name: InjectedVolumeName,
+				VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					ReadOnly:         &readOnly,
+					VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+				}},
+			},
+			expectedMounts: map[string][]corev1.VolumeMount{
+				"init":    nil,
+				"app":     {mount},
+				"sidecar": {mount},
+			},
+			expectedVolumeCount: 1,
+		},
+		{
+			name: "cluster secret provider class with options",
+			annotations: map[string]string{
+				InjectAnnotation:                     "cspc1",
+				InjectKindAnnotation:                 "ClusterSecretProviderClass",
+				InjectMountPathAnnotation:            "/etc/secrets",
+				InjectContainersAnnotation:           "init, app",
+				InjectNodePublishSecretRefAnnotation: "creds",
+				InjectEnvAnnotation:                  "USERNAME=foosecret/username, PASSWORD=foosecret/password",
+			},
+			expectedInjected: true,
+			expectedVolume: &corev1.Volume{
+				Name: InjectedVolumeName,
+				VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{
+					Driver:               "secrets-store.csi.k8s.io",
+					ReadOnly:             &readOnly,
+					VolumeAttributes:     map[string]string{"clusterSecretProviderClass": "cspc1"},
+					NodePublishSecretRef: &corev1.LocalObjectReference{Name: "creds"},
+				}},
+			},
+			expectedMounts: map[string][]corev1.VolumeMount{
+				"init":    {{Name: InjectedVolumeName, MountPath: "/etc/secrets", ReadOnly: true}},
+				"app":     {{Name: InjectedVolumeName, MountPath: "/etc/secrets", ReadOnly: true}},
+				"sidecar": nil,
+			},
+			expectedEnvNames: map[string][]string{
+				"init":    {"USERNAME", "PASSWORD"},
+				"app":     {"USERNAME", "PASSWORD"},
+				"sidecar": nil,
+			},
+			expectedVolumeCount: 1,
+		},
+		{
+			name:        "existing volume for the secret provider class",
+			annotations: map[string]string{InjectAnnotation: "spc1"},
+			existingVolume: &corev1.Volume{
+				Name: "secrets",
+				VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{
+					Driver:           "secrets-store.csi.k8s.io",
+					VolumeAttributes: map[string]string{"secretProviderClass": "spc1"},
+				}},
+			},
+			expectedInjected: true,
+			expectedMounts: map[string][]corev1.VolumeMount{
+				"app":     {{Name: "secrets", MountPath: DefaultInjectMountPath, ReadOnly: true}},
+				"sidecar": {{Name: "secrets", MountPath: DefaultInjectMountPath, ReadOnly: true}},
+			},
+			expectedVolumeCount: 1,
+		},
+		{
+			name:        "existing volume with the injected volume name",
+			annotations: map[string]string{InjectAnnotation: "spc1"},
+			existingVolume: &corev1.Volume{
+				Name:         InjectedVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid kind",
+			annotations: map[string]string{InjectAnnotation: "spc1", InjectKindAnnotation: "Secret"},
+			expectedErr: true,
+		},
+		{
+			name:        "container not found",
+			annotations: map[string]string{InjectAnnotation: "spc1", InjectContainersAnnotation: "app,other"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid env entry",
+			annotations: map[string]string{InjectAnnotation: "spc1", InjectEnvAnnotation: "USERNAME=foosecret"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := newInjectPod(test.annotations)
+			if test.existingVolume != nil {
+				pod.Spec.Volumes = append(pod.Spec.Volumes, *test.existingVolume)
+			}
+
+			injected, err := InjectSecretsStoreVolume(pod)
+			if test.expectedErr != (err != nil) {
+				t.Fatalf("expected err: %v, got: %+v", test.expectedErr, err)
+			}
+			if injected != test.expectedInjected {
+				t.Fatalf("expected injected: %v, got: %v", test.expectedInjected, injected)
+			}
+			if !injected {
+				return
+			}
+			if len(pod.Spec.Volumes) != test.expectedVolumeCount {
+				t.Fatalf("expected %d volumes, got: %+v", test.expectedVolumeCount, pod.Spec.Volumes)
+			}
+			if test.expectedVolume != nil && !reflect.DeepEqual(pod.Spec.Volumes[0], *test.expectedVolume) {
+				t.Fatalf("expected volume: %+v, got: %+v", *test.expectedVolume, pod.Spec.Volumes[0])
+			}
+			containers := append([]corev1.Container{}, pod.Spec.InitContainers...)
+			containers = append(containers, pod.Spec.Containers...)
+			for _, container := range containers {
+				if mounts, ok := test.expectedMounts[container.Name]; ok && !reflect.DeepEqual(container.VolumeMounts, mounts) {
+					t.Fatalf("expected mounts for container %s: %+v, got: %+v", container.Name, mounts, container.VolumeMounts)
+				}
+				var envNames []string
+				for _, e := range container.Env {
+					if e.ValueFrom == nil || e.ValueFrom.SecretKeyRef == nil || e.ValueFrom.SecretKeyRef.Name != "foosecret" {
+						t.Fatalf("expected env %s from secret foosecret, got: %+v", e.Name, e.ValueFrom)
+					}
+					envNames = append(envNames, e.Name)
+				}
+				if !reflect.DeepEqual(envNames, test.expectedEnvNames[container.Name]) {
+					t.Fatalf("expected env for container %s: %v, got: %v", container.Name, test.expectedEnvNames[container.Name], envNames)
+				}
+			}
+
+			// injecting again doesn't change the pod
+			expected := pod.DeepCopy()
+			if _, err := InjectSecretsStoreVolume(pod); err != nil {
+				t.Fatalf("expected no error when injecting again, got: %+v", err)
+			}
+			if !reflect.DeepEqual(pod, expected) {
+				t.Fatalf("expected pod to be unchanged when injecting again, got: %+v", pod)
+			}
+		})
+	}
+}
+
+func TestPodInjector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	injector := &PodInjector{}
+	if err := injector.InjectDecoder(decoder); err != nil {
+		t.Fatalf("failed to inject decoder: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		expectedAllowed bool
+		expectedPatches bool
+	}{
+		{
+			name:            "pod without inject annotation",
+			expectedAllowed: true,
+		},
+		{
+			name:            "pod with inject annotation",
+			annotations:     map[string]string{InjectAnnotation: "spc1"},
+			expectedAllowed: true,
+			expectedPatches: true,
+		},
+		{
+			name:            "pod with invalid inject annotations",
+			annotations:     map[string]string{InjectAnnotation: "spc1", InjectKindAnnotation: "Secret"},
+			expectedAllowed: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			raw, err := json.Marshal(newInjectPod(test.annotations))
+			if err != nil {
+				t.Fatalf("failed to marshal pod: %v", err)
+			}
+			resp := injector.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Namespace: "default",
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			if resp.Allowed != test.expectedAllowed {
+				t.Fatalf("expected allowed: %v, got: %v, result: %+v", test.expectedAllowed, resp.Allowed, resp.Result)
+			}
+			if (len(resp.Patches) > 0) != test.expectedPatches {
+				t.Fatalf("expected patches: %v, got: %+v", test.expectedPatches, resp.Patches)
+			}
+		})
+	}
+}
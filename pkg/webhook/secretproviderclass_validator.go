@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+	secretsstore "sigs.k8s.io/secrets-store-csi-driver/pkg/secrets-store"
+)
+
+// ValidateSecretProviderClassPath is the path the SecretProviderClass validating webhook is served on
+const ValidateSecretProviderClassPath = "/validate-secrets-store-csi-x-k8s-io-v1-secretproviderclass"
+
+// SecretProviderClassValidator validates SecretProviderClass and ClusterSecretProviderClass
+// objects on create and update
+type SecretProviderClassValidator struct {
+	// MinRotationInterval is the shortest rotation interval the rotation policy can set
+	MinRotationInterval time.Duration
+
+	decoder *admission.Decoder
+}
+
+// SetupWithManager registers the validating webhook with the manager webhook server
+func (v *SecretProviderClassValidator) SetupWithManager(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(ValidateSecretProviderClassPath, &webhook.Admission{Handler: v})
+}
+
+// Handle rejects the SecretProviderClass or ClusterSecretProviderClass in the admission
+// request if it fails validation
+func (v *SecretProviderClassValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Kind.Kind == secretsstorev1.ClusterSecretProviderClassKind {
+		cspc := &secretsstorev1.ClusterSecretProviderClass{}
+		if err := v.decoder.Decode(req, cspc); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := secretsstore.ValidateClusterSecretProviderClass(cspc, v.MinRotationInterval); err != nil {
+			klog.InfoS("denied invalid cluster secret provider class", "cspc", klog.KObj(cspc), "operation", req.Operation, "err", err)
+			return admission.Denied(err.Error())
+		}
+		return admission.Allowed("")
+	}
+
+	spc := &secretsstorev1.SecretProviderClass{}
+	if err := v.decoder.Decode(req, spc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := secretsstore.ValidateSecretProviderClass(spc, v.MinRotationInterval); err != nil {
+		klog.InfoS("denied invalid secret provider class", "spc", klog.KObj(spc), "operation", req.Operation, "err", err)
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder into the validator
+func (v *SecretProviderClassValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	secretsstorev1 "sigs.k8s.io/secrets-store-csi-driver/apis/v1"
+)
+
+func TestSecretProviderClassValidator(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            secretsstorev1.SecretProviderClassSpec
+		expectedAllowed bool
+	}{
+		{
+			name: "invalid provider name",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider/1",
+				Parameters: map[string]string{"parameter1": "value1"},
+			},
+			expectedAllowed: false,
+		},
+		{
+			name: "duplicate secret name",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider1",
+				Parameters: map[string]string{"parameter1": "value1"},
+				SecretObjects: []*secretsstorev1.SecretObject{
+					{SecretName: "secret1", Type: "Opaque", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj1", Key: "file1"}}},
+					{SecretName: "secret1", Type: "Opaque", Data: []*secretsstorev1.SecretObjectData{{ObjectName: "obj2", Key: "file2"}}},
+				},
+			},
+			expectedAllowed: false,
+		},
+		{
+			name: "rotation interval below the minimum",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:       "provider1",
+				Parameters:     map[string]string{"parameter1": "value1"},
+				RotationPolicy: &secretsstorev1.RotationPolicy{Interval: &metav1.Duration{Duration: 5 * time.Minute}},
+			},
+			expectedAllowed: false,
+		},
+		{
+			name: "valid secret provider class",
+			spec: secretsstorev1.SecretProviderClassSpec{
+				Provider:   "provider1",
+				Parameters: map[string]string{"parameter1": "value1"},
+			},
+			expectedAllowed: true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := secretsstorev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spc := &secretsstorev1.SecretProviderClass{
+				TypeMeta:   metav1.TypeMeta{APIVersion: secretsstorev1.GroupVersion.String(), Kind: "SecretProviderClass"},
+				ObjectMeta: metav1.ObjectMeta{Name: "spc1", Namespace: "default"},
+				Spec:       test.spec,
+			}
+			raw, err := json.Marshal(spc)
+			if err != nil {
+				t.Fatalf("failed to marshal spc: %v", err)
+			}
+
+			v := &SecretProviderClassValidator{MinRotationInterval: 10 * time.Minute}
+			if err := v.InjectDecoder(decoder); err != nil {
+				t.Fatalf("failed to inject decoder: %v", err)
+			}
+			resp := v.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			if resp.Allowed != test.expectedAllowed {
+				t.Fatalf("expected allowed: %v, got: %v, result: %+v", test.expectedAllowed, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
+
+func TestClusterSecretProviderClassValidator(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            secretsstorev1.ClusterSecretProviderClassSpec
+		expectedAllowed bool
+	}{
+		{
+			name: "invalid provider name",
+			spec: secretsstorev1.ClusterSecretProviderClassSpec{
+				SecretProviderClassSpec: secretsstorev1.SecretProviderClassSpec{Provider: "provider/1"},
+				NamespaceSelector:       &metav1.LabelSelector{},
+			},
+			expectedAllowed: false,
+		},
+		{
+			name: "invalid namespace selector",
+			spec: secretsstorev1.ClusterSecretProviderClassSpec{
+				SecretProviderClassSpec: secretsstorev1.SecretProviderClassSpec{Provider: "provider1"},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Matches"}},
+				},
+			},
+			expectedAllowed: false,
+		},
+		{
+			name: "valid cluster secret provider class",
+			spec: secretsstorev1.ClusterSecretProviderClassSpec{
+				SecretProviderClassSpec: secretsstorev1.SecretProviderClassSpec{Provider: "provider1", Parameters: map[string]string{"parameter1": "value1"}},
+				NamespaceSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			},
+			expectedAllowed: true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := secretsstorev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cspc := &secretsstorev1.ClusterSecretProviderClass{
+				TypeMeta:   metav1.TypeMeta{APIVersion: secretsstorev1.GroupVersion.String(), Kind: secretsstorev1.ClusterSecretProviderClassKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "cspc1"},
+				Spec:       test.spec,
+			}
+			raw, err := json.Marshal(cspc)
+			if err != nil {
+				t.Fatalf("failed to marshal cspc: %v", err)
+			}
+
+			v := &SecretProviderClassValidator{}
+			if err := v.InjectDecoder(decoder); err != nil {
+				t.Fatalf("failed to inject decoder: %v", err)
+			}
+			resp := v.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: secretsstorev1.GroupVersion.Group, Version: secretsstorev1.GroupVersion.Version, Kind: secretsstorev1.ClusterSecretProviderClassKind},
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			if resp.Allowed != test.expectedAllowed {
+				t.Fatalf("expected allowed: %v, got: %v, result: %+v", test.expectedAllowed, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
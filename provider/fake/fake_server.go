@@ -22,8 +22,11 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"sigs.k8s.io/secrets-store-csi-driver/provider/v1alpha1"
 )
@@ -36,6 +39,13 @@ type MockCSIProviderServer struct {
 	errorCode  string
 	objects    []*v1alpha1.ObjectVersion
 	files      []*v1alpha1.File
+
+	capabilities []v1alpha1.Capability
+	// changes is sent on the Watch streams
+	changes chan *v1alpha1.ObjectChange
+	mutex   sync.Mutex
+	// watchRequests are the requests of the Watch streams received
+	watchRequests []*v1alpha1.WatchRequest
 }
 
 // NewMocKCSIProviderServer returns a mock csi-provider grpc server
@@ -44,6 +54,7 @@ func NewMocKCSIProviderServer(socketPath string) (*MockCSIProviderServer, error)
 	s := &MockCSIProviderServer{
 		grpcServer: server,
 		socketPath: socketPath,
+		changes:    make(chan *v1alpha1.ObjectChange, 10),
 	}
 	v1alpha1.RegisterCSIDriverProviderServer(server, s)
 	return s, nil
@@ -63,6 +74,22 @@ func (m *MockCSIProviderServer) SetObjects(objects map[string]string) {
 	m.objects = ov
 }
 
+// SetObjectExpiry sets the expires_at and not_after times of the expected object set
+// with SetObjects. A zero time isn't set.
+func (m *MockCSIProviderServer) SetObjectExpiry(id string, expiresAt, notAfter time.Time) {
+	for _, ov := range m.objects {
+		if ov.Id != id {
+			continue
+		}
+		if !expiresAt.IsZero() {
+			ov.ExpiresAt = timestamppb.New(expiresAt)
+		}
+		if !notAfter.IsZero() {
+			ov.NotAfter = timestamppb.New(notAfter)
+		}
+	}
+}
+
 // SetFiles sets provider files to return on Mount
 func (m *MockCSIProviderServer) SetFiles(files []*v1alpha1.File) {
 	var ov []*v1alpha1.File
@@ -76,6 +103,23 @@ func (m *MockCSIProviderServer) SetFiles(files []*v1alpha1.File) {
 	m.files = ov
 }
 
+// SetCapabilities sets the capabilities to return on Version
+func (m *MockCSIProviderServer) SetCapabilities(capabilities ...v1alpha1.Capability) {
+	m.capabilities = capabilities
+}
+
+// SendObjectChange sends the object change on a Watch stream
+func (m *MockCSIProviderServer) SendObjectChange(id, version string) {
+	m.changes <- &v1alpha1.ObjectChange{ObjectVersion: &v1alpha1.ObjectVersion{Id: id, Version: version}}
+}
+
+// WatchRequests returns the requests of the Watch streams received
+func (m *MockCSIProviderServer) WatchRequests() []*v1alpha1.WatchRequest {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]*v1alpha1.WatchRequest(nil), m.watchRequests...)
+}
+
 // SetProviderErrorCode sets provider error code to return
 func (m *MockCSIProviderServer) SetProviderErrorCode(errorCode string) {
 	m.errorCode = errorCode
@@ -131,5 +175,26 @@ func (m *MockCSIProviderServer) Version(ctx context.Context, req *v1alpha1.Versi
 		Version:        "v1alpha1",
 		RuntimeName:    "fakeprovider",
 		RuntimeVersion: "0.0.10",
+		Capabilities:   m.capabilities,
 	}, nil
 }
+
+// Watch implements provider csi-provider method
+func (m *MockCSIProviderServer) Watch(req *v1alpha1.WatchRequest, stream v1alpha1.CSIDriverProvider_WatchServer) error {
+	if m.returnErr != nil {
+		return m.returnErr
+	}
+	m.mutex.Lock()
+	m.watchRequests = append(m.watchRequests, req)
+	m.mutex.Unlock()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case change := <-m.changes:
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}